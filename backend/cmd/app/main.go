@@ -28,20 +28,34 @@ func main() {
 		logrus.Warnf("Could not get REDIS_URL from environment: %v", err)
 		redisURL = ""
 	}
+	// интерфейс остается nil без подключения, иначе сервисы получат nil указатель внутри интерфейса
+	var redis repository.Redis
 	redisClient, err := repository.NewRedisClient(redisURL) // инициализация redis клиента
 	if err != nil {
 		logrus.Warnf("Redis connection failed: %v", err)
 		logrus.Info("Application will continue without Redis caching")
-		redisClient = nil
 	} else {
 		logrus.Info("Redis connected successfully")
+		redis = redisClient
 		defer redisClient.Close()
 	}
 
 	// сервис разделё на 3 слоя
-	repos := repository.NewRepository(db, redisClient) // слой репозитория для работы с бд
-	services := service.NewService(repos)              // слой сервисов для работы с бизнес логикой
-	handler := handler.NewHandler(services)            // слой хэндлеров для отловки запросов
+	repos := repository.NewRepository(db, redis) // слой репозитория для работы с бд
+	services, err := service.NewService(repos)   // слой сервисов для работы с бизнес логикой
+	if err != nil {
+		logrus.Fatalf("fatal initializetion services, %s", err.Error())
+	}
+	handler := handler.NewHandler(services) // слой хэндлеров для отловки запросов
+
+	// фоновые задачи: прогнозы по расписанию, оценка их точности, проверка правил оповещений, их эскалация и уведомления
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go services.ForecastScheduler.Run(ctx)
+	go services.Accuracy.Run(ctx)
+	go services.AlertRule.Run(ctx)
+	go services.AlertEscalation.Run(ctx)
+	go services.Notification.Run(ctx)
 
 	done := make(chan struct{})
 
@@ -0,0 +1,43 @@
+/*
+Локальная замена GigaChat для тестов и разработки без ключей Сбера и доступа в сеть.
+Бэкенд направляется на нее через GIGACHAT_BASE_URL=http://localhost:8090.
+Сценарий ответов задается json файлом в FAKE_GIGACHAT_CONFIG:
+
+	{"key": "", "script": [{"content": "..."}], "rules": [{"match": "regexp", "status": 500, "content": "..."}]}
+*/
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/fakegigachat"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr, err := config.Get("FAKE_GIGACHAT_ADDR")
+	if err != nil {
+		addr = ":8090"
+	}
+
+	var cfg fakegigachat.Config
+	if path, err := config.Get("FAKE_GIGACHAT_CONFIG"); err == nil {
+		cfg, err = fakegigachat.LoadConfig(path)
+		if err != nil {
+			logrus.Fatalf("fake gigachat config: %v", err)
+		}
+		logrus.Infof("fake gigachat: %d scripted replies, %d rules from %s", len(cfg.Script), len(cfg.Rules), path)
+	}
+
+	server, err := fakegigachat.NewServer(cfg)
+	if err != nil {
+		logrus.Fatalf("fake gigachat: %v", err)
+	}
+
+	logrus.Infof("fake gigachat listening on %s", addr)
+	if err := http.ListenAndServe(addr, server); err != nil {
+		logrus.Fatalf("fake gigachat: %v", err)
+	}
+}
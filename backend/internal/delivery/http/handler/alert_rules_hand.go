@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListAlertRules(c *gin.Context) {
+	rules, err := h.services.AlertRule.ListRules()
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *Handler) GetAlertRule(c *gin.Context) {
+	id, ok := alertRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.services.AlertRule.GetRule(id)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *Handler) CreateAlertRule(c *gin.Context) {
+	var input entities.AlertRuleInput
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.services.AlertRule.CreateRule(input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *Handler) UpdateAlertRule(c *gin.Context) {
+	id, ok := alertRuleID(c)
+	if !ok {
+		return
+	}
+
+	var input entities.AlertRuleUpdate
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.services.AlertRule.UpdateRule(id, input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *Handler) DeleteAlertRule(c *gin.Context) {
+	id, ok := alertRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.AlertRule.DeleteRule(id); err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "deleted",
+	})
+}
+
+// внеочередная проверка всех правил, например после их изменения
+func (h *Handler) EvaluateAlertRules(c *gin.Context) {
+	raised, err := h.services.AlertRule.EvaluateRules(time.Now())
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"raised": raised,
+	})
+}
+
+func alertRuleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid alert rule id")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/gin-gonic/gin"
+)
+
+// оповещения с фильтрами по статусу, важности, товару и зоне
+func (h *Handler) ListAlerts(c *gin.Context) {
+	var query entities.AlertQuery
+	if err := c.BindQuery(&query); err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid query parameters: "+err.Error())
+		return
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 1000 {
+		query.Limit = 1000
+	}
+
+	alerts, err := h.services.Alert.ListAlerts(query)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+func (h *Handler) GetAlert(c *gin.Context) {
+	id, ok := alertID(c)
+	if !ok {
+		return
+	}
+
+	alert, err := h.services.Alert.GetAlert(id)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// взять оповещение в работу, тело запроса необязательно
+func (h *Handler) AcknowledgeAlert(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		NewResponseError(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	id, ok := alertID(c)
+	if !ok {
+		return
+	}
+
+	var input entities.AlertAcknowledge
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&input); err != nil {
+			NewResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	alert, err := h.services.Alert.AcknowledgeAlert(id, userID, input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+func (h *Handler) AssignAlert(c *gin.Context) {
+	id, ok := alertID(c)
+	if !ok {
+		return
+	}
+
+	var input entities.AlertAssign
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	alert, err := h.services.Alert.AssignAlert(id, input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// закрыть оповещение, в теле - описание принятых мер
+func (h *Handler) ResolveAlert(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		NewResponseError(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	id, ok := alertID(c)
+	if !ok {
+		return
+	}
+
+	var input entities.AlertResolve
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&input); err != nil {
+			NewResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	alert, err := h.services.Alert.ResolveAlert(id, userID, input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+func alertID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid alert id")
+		return 0, false
+	}
+	return uint(id), true
+}
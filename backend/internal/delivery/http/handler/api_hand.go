@@ -1,12 +1,14 @@
 package handler
 
 import (
-	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
@@ -17,11 +19,11 @@ var upgrader = websocket.Upgrader{
 		return true // для разрабы
 	},
 	WriteBufferSize: 1024,
-	ReadBufferSize:  10,
+	ReadBufferSize:  1024,
 }
 
 func (h *Handler) Robots(c *gin.Context) {
-	_, ok := c.Get(robotCtx)
+	robotID, ok := c.Get(robotCtx)
 	if !ok {
 		NewResponseError(c, http.StatusInternalServerError, "robot id not found")
 		return
@@ -33,16 +35,28 @@ func (h *Handler) Robots(c *gin.Context) {
 		return
 	}
 
-	err := h.services.Robot.AddData(rd)
+	// робот может отправлять данные только от своего имени
+	if rd.RobotId != robotID {
+		NewResponseError(c, http.StatusForbidden, fmt.Sprintf("robot %v cannot send data as %s", robotID, rd.RobotId))
+		return
+	}
+
+	// отклоненное сообщение (4xx) робот не повторяет, ошибка сервера (5xx) повторяется
+	result, err := h.services.Robot.AddData(rd)
 	if err != nil {
-		NewResponseError(c, http.StatusInternalServerError, err.Error())
+		NewResponseError(c, errorStatus(err), err.Error())
 		return
 	}
 
+	status := "received"
+	if result.Duplicate {
+		status = "duplicate"
+	}
+
 	logrus.Print("data received successfuly")
 	c.JSON(http.StatusOK, gin.H{
-		"status":     "received",
-		"message_id": "tmp_message_id",
+		"status":     status,
+		"message_id": result.MessageID,
 	})
 }
 
@@ -54,41 +68,11 @@ func (h *Handler) WebsocketDashBoard(c *gin.Context) {
 	}
 	defer conn.Close()
 
-	// Подписываемся на Redis channel
-	if h.services.Redis != nil {
-		go h.HandleRedisSubscriptions(conn)
-	}
-
-	// Старая логика
+	// события хаба и статусы роботов из Redis с фильтрами подписок соединения
 	h.services.WebsocketDashBoard.RunStream(conn)
 	logrus.Print("вебсокет закрыт")
 }
 
-// Обработка Redis подписок
-func (h *Handler) HandleRedisSubscriptions(conn *websocket.Conn) {
-	ctx := context.Background()
-
-	// Подписываемся на канал robot_updates
-	pubsub := h.services.Redis.Subscribe("robot_updates")
-	defer pubsub.Close()
-
-	for {
-		msg, err := pubsub.ReceiveMessage(ctx)
-		if err != nil {
-			logrus.Errorf("Redis subscription error: %v", err)
-			return
-		}
-
-		// Отправляем сообщение через WebSocket
-		err = conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload))
-		if err != nil {
-			logrus.Errorf("WebSocket send error: %v", err)
-			return
-		}
-		logrus.Info("Sent Redis message to WebSocket client")
-	}
-}
-
 func (h *Handler) GetDashInfo(c *gin.Context) {
 	_, ok := c.Get(userCtx)
 	if !ok {
@@ -129,9 +113,88 @@ func (h *Handler) AIRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"predictions": res.Predictions,
 		"confidence":  res.Confidence,
+		"provider":    res.Provider,
+		"dropped":     res.Dropped,
 	})
 }
 
+// вопрос об остатках на естественном языке, ответ со ссылками на строки данных
+func (h *Handler) AskAI(c *gin.Context) {
+	var rq entities.AskRequest
+	if err := c.BindJSON(&rq); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	answer, err := h.services.Assistant.Ask(rq.Question)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, answer)
+}
+
+// сохраненные прогнозы с фильтрами, без нового запроса к модели
+func (h *Handler) ListPredictions(c *gin.Context) {
+	var query entities.PredictionQuery
+	if err := c.BindQuery(&query); err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid query parameters: "+err.Error())
+		return
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 1000 {
+		query.Limit = 1000
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	predictions, err := h.services.AI.ListPredictions(query)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, predictions)
+}
+
+// последний прогноз по каждому товару
+func (h *Handler) LatestPredictions(c *gin.Context) {
+	var query entities.PredictionQuery
+	if err := c.BindQuery(&query); err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid query parameters: "+err.Error())
+		return
+	}
+
+	predictions, err := h.services.AI.LatestPredictions(query)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"predictions": predictions})
+}
+
+// точность прошлых прогнозов: ?from=&to=&tolerance=
+func (h *Handler) GetAccuracy(c *gin.Context) {
+	var query entities.AccuracyQuery
+	if err := c.BindQuery(&query); err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid query parameters: "+err.Error())
+		return
+	}
+
+	report, err := h.services.Accuracy.Report(query)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 func (h *Handler) ExportExcel(c *gin.Context) {
 	userID, ok := c.Get(userCtx)
 	if !ok {
@@ -213,6 +276,78 @@ func (h *Handler) exportInventoryHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, historyData)
 }
 
+// текущие остатки товаров по ячейкам склада
+func (h *Handler) GetStock(c *gin.Context) {
+	var query entities.StockQuery
+	if err := c.BindQuery(&query); err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid query parameters: "+err.Error())
+		return
+	}
+
+	stock, err := h.services.Inventory.GetStock(query.ProductID, query.Zone)
+	if err != nil {
+		NewResponseError(c, http.StatusInternalServerError, "failed to get stock: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, stock)
+}
+
+// выбросы в сканированиях роботов
+func (h *Handler) ListAnomalies(c *gin.Context) {
+	var query entities.AnomalyQuery
+	if err := c.BindQuery(&query); err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid query parameters: "+err.Error())
+		return
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 1000 {
+		query.Limit = 1000
+	}
+
+	anomalies, err := h.services.Inventory.ListAnomalies(query)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, anomalies)
+}
+
+// подтверждение выброса: остаток на полке действительно изменился
+func (h *Handler) ConfirmAnomaly(c *gin.Context) {
+	h.resolveAnomaly(c, true)
+}
+
+// отклонение выброса как ошибки сканирования
+func (h *Handler) RejectAnomaly(c *gin.Context) {
+	h.resolveAnomaly(c, false)
+}
+
+func (h *Handler) resolveAnomaly(c *gin.Context, confirm bool) {
+	userID, ok := currentUser(c)
+	if !ok {
+		NewResponseError(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid scan id")
+		return
+	}
+
+	history, err := h.services.Inventory.ResolveAnomaly(uint(id), confirm, userID)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // Получение статусов всех роботов
 func (h *Handler) GetRobotsStatus(c *gin.Context) {
 	if h.services.Redis == nil {
@@ -223,15 +358,25 @@ func (h *Handler) GetRobotsStatus(c *gin.Context) {
 		return
 	}
 
-	// В реальном приложении здесь бы брали список роботов из БД
-	// Для демо используем тестовые ID
-	robotIDs := []string{"RB-001", "RB-002", "RB-003", "RB-004", "RB-005"}
+	// список роботов берется из реестра, списанные не отслеживаются
+	robots, err := h.services.Robot.ListRobots()
+	if err != nil {
+		NewResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
 
 	statuses := make(map[string]interface{})
 	onlineCount := 0
 	totalBattery := 0
+	totalRobots := 0
+
+	for _, robot := range robots {
+		if robot.Status == models.RobotStatusDecommissioned {
+			continue
+		}
+		totalRobots++
+		robotID := robot.ID
 
-	for _, robotID := range robotIDs {
 		online, _ := h.services.Redis.IsRobotOnline(robotID)
 		battery, _ := h.services.Redis.GetRobotBattery(robotID)
 		status, _ := h.services.Redis.GetRobotStatus(robotID)
@@ -257,7 +402,7 @@ func (h *Handler) GetRobotsStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"online_robots": onlineCount,
-		"total_robots":  len(robotIDs),
+		"total_robots":  totalRobots,
 		"avg_battery":   avgBattery,
 		"robots":        statuses,
 		"last_updated":  time.Now().Format("15:04:05"),
@@ -22,6 +22,9 @@ func (h *Handler) SignUp(c *gin.Context) { // not task
 		return
 	}
 
+	// новые пользователи только читают, роль повышает администратор
+	input.Role = models.RoleViewer
+
 	id, err := h.services.Authorization.CreateUser(input)
 	if err != nil {
 		NewResponseError(c, http.StatusInternalServerError, err.Error())
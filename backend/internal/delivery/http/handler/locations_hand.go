@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/gin-gonic/gin"
+)
+
+// раскладка склада для карты дашборда
+func (h *Handler) ListLocations(c *gin.Context) {
+	locations, err := h.services.Location.ListLocations()
+	if err != nil {
+		NewResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"locations": locations,
+		"total":     len(locations),
+	})
+}
+
+func (h *Handler) GetLocation(c *gin.Context) {
+	location, err := h.services.Location.GetLocation(c.Param("zone"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+func (h *Handler) CreateLocation(c *gin.Context) {
+	var input entities.LocationInput
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	location, err := h.services.Location.CreateLocation(input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, location)
+}
+
+func (h *Handler) UpdateLocation(c *gin.Context) {
+	var input entities.LocationUpdate
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	location, err := h.services.Location.UpdateLocation(c.Param("zone"), input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// удаление зоны, в которой не осталось товара
+func (h *Handler) DeleteLocation(c *gin.Context) {
+	if err := h.services.Location.DeleteLocation(c.Param("zone")); err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "deleted",
+	})
+}
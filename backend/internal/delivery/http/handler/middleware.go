@@ -1,19 +1,27 @@
 package handler
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	authorizationHeader = "Authorization"
-	userCtx             = "userId"
-	robotCtx            = "robotId"
+	authorizationHeader  = "Authorization"
+	robotKeyHeader       = "X-Robot-Key"
+	robotTimestampHeader = "X-Robot-Timestamp"
+	robotSignatureHeader = "X-Robot-Signature"
+	userCtx              = "userId"
+	robotCtx             = "robotId"
+
+	maxRobotBodySize = 10 << 20
 )
 
 // валидация jwt токенов
@@ -45,9 +53,78 @@ func (h *Handler) UserIdentity(c *gin.Context) {
 	c.Set(userCtx, userID)
 }
 
-// валидация id роботов
+// id пользователя, установленный UserIdentity
+func currentUser(c *gin.Context) (uint, bool) {
+	value, ok := c.Get(userCtx)
+	if !ok {
+		return 0, false
+	}
+	userID, ok := value.(uint)
+	return userID, ok
+}
+
+// проверка подписи запроса робота по выданному ему ключу
 func (h *Handler) RobotIdentity(c *gin.Context) {
-	header := c.GetHeader("Authorization")
+	keyID := c.GetHeader(robotKeyHeader)
+	if keyID == "" {
+		h.legacyRobotIdentity(c)
+		return
+	}
+
+	body, ok := readRobotBody(c)
+	if !ok {
+		return
+	}
+
+	robotID, err := h.services.RobotAuth.VerifyRequest(entities.RobotSignedRequest{
+		KeyID:     keyID,
+		Timestamp: c.GetHeader(robotTimestampHeader),
+		Signature: c.GetHeader(robotSignatureHeader),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Body:      body,
+	})
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	if !h.services.Robot.CheckId(robotID) {
+		NewResponseError(c, http.StatusUnauthorized, fmt.Errorf("robot with id=%s is not active", robotID).Error())
+		return
+	}
+	c.Set(robotCtx, robotID)
+}
+
+// тело запроса робота с ограничением размера, ok=false - ответ уже отправлен
+func readRobotBody(c *gin.Context) ([]byte, bool) {
+	if c.Request.Body == nil {
+		return nil, true
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRobotBodySize+1))
+	if err != nil {
+		NewResponseError(c, http.StatusBadRequest, "failed to read body: "+err.Error())
+		return nil, false
+	}
+	if len(body) > maxRobotBodySize {
+		NewResponseError(c, http.StatusRequestEntityTooLarge, "request body is too large")
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body)) // тело нужно хэндлеру после проверки
+	return body, true
+}
+
+// старая схема "Bearer token_<robotId>", включается только через ROBOT_LEGACY_AUTH
+func (h *Handler) legacyRobotIdentity(c *gin.Context) {
+	if !h.services.RobotAuth.LegacyAuthAllowed() {
+		NewResponseError(c, http.StatusUnauthorized, "missing robot signature headers")
+		return
+	}
+	if _, ok := readRobotBody(c); !ok {
+		return
+	}
+
+	header := c.GetHeader(authorizationHeader)
 	if header == "" {
 		NewResponseError(c, http.StatusUnauthorized, "empty auth header")
 		return
@@ -59,7 +136,11 @@ func (h *Handler) RobotIdentity(c *gin.Context) {
 		return
 	}
 
-	robotID := strings.Split(headerParts[1], "_")[1]
+	_, robotID, found := strings.Cut(headerParts[1], "_")
+	if !found || robotID == "" {
+		NewResponseError(c, http.StatusUnauthorized, "invalid robot token")
+		return
+	}
 	if !h.services.Robot.CheckId(robotID) {
 		NewResponseError(c, http.StatusUnauthorized, fmt.Errorf("robot with id=%s does not exist", robotID).Error())
 		return
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListNotificationChannels(c *gin.Context) {
+	channels, err := h.services.Notification.ListChannels()
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+func (h *Handler) GetNotificationChannel(c *gin.Context) {
+	id, ok := channelID(c)
+	if !ok {
+		return
+	}
+
+	channel, err := h.services.Notification.GetChannel(id)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *Handler) CreateNotificationChannel(c *gin.Context) {
+	var input entities.NotificationChannelInput
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	channel, err := h.services.Notification.CreateChannel(input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, channel)
+}
+
+func (h *Handler) UpdateNotificationChannel(c *gin.Context) {
+	id, ok := channelID(c)
+	if !ok {
+		return
+	}
+
+	var input entities.NotificationChannelUpdate
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	channel, err := h.services.Notification.UpdateChannel(id, input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *Handler) DeleteNotificationChannel(c *gin.Context) {
+	id, ok := channelID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Notification.DeleteChannel(id); err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "deleted",
+	})
+}
+
+// проверочное уведомление, ошибка канала возвращается в ответе
+func (h *Handler) TestNotificationChannel(c *gin.Context) {
+	id, ok := channelID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.services.Notification.TestChannel(c.Request.Context(), id)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// журнал доставки с фильтрами по статусу, каналу и оповещению
+func (h *Handler) ListNotificationDeliveries(c *gin.Context) {
+	var query entities.DeliveryQuery
+	if err := c.BindQuery(&query); err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid query parameters: "+err.Error())
+		return
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 1000 {
+		query.Limit = 1000
+	}
+
+	deliveries, err := h.services.Notification.ListDeliveries(query)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func channelID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid notification channel id")
+		return 0, false
+	}
+	return uint(id), true
+}
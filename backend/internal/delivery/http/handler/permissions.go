@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const roleCtx = "userRole"
+
+// действия, на которые проверяются права пользователя
+type permission string
+
+const (
+	permRead           permission = "read"            // просмотр дашборда, истории и прогнозов
+	permInventoryWrite permission = "inventory:write" // импорт и экспорт инвентаризации, каталог товаров
+	permAIPredict      permission = "ai:predict"      // запуск прогноза ИИ
+	permManageRobots   permission = "robots:manage"   // управление роботами
+	permManageUsers    permission = "users:manage"    // управление пользователями
+	permManageLayout   permission = "layout:manage"   // раскладка склада
+	permManageAlerts   permission = "alerts:manage"   // правила оповещений и каналы уведомлений
+)
+
+// политика доступа по ролям из users.role
+var rolePermissions = map[string][]permission{
+	models.RoleViewer:   {permRead},
+	models.RoleOperator: {permRead, permInventoryWrite, permAIPredict},
+	models.RoleAdmin:    {permRead, permInventoryWrite, permAIPredict, permManageRobots, permManageUsers, permManageLayout, permManageAlerts},
+}
+
+func roleAllows(role string, perm permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// проверка прав пользователя, вызывается после UserIdentity
+func (h *Handler) RequirePermission(perm permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, err := h.userRole(c)
+		if err != nil {
+			// 401 только если пользователя нет, сбой бд - ошибка сервера
+			NewResponseError(c, errorStatus(err), err.Error())
+			return
+		}
+
+		if !roleAllows(role, perm) {
+			NewResponseError(c, http.StatusForbidden, fmt.Sprintf("forbidden: role %q has no %q permission", role, perm))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// роль пользователя, загружается из бд один раз за запрос
+func (h *Handler) userRole(c *gin.Context) (string, error) {
+	if role, ok := c.Get(roleCtx); ok {
+		return role.(string), nil
+	}
+
+	value, ok := c.Get(userCtx)
+	if !ok {
+		return "", fmt.Errorf("%w: user not authenticated", entities.ErrUnauthorized)
+	}
+	userID, ok := value.(uint)
+	if !ok {
+		return "", fmt.Errorf("invalid user id in context")
+	}
+
+	role, err := h.services.Authorization.GetUserRole(userID)
+	if errors.Is(err, entities.ErrNotFound) {
+		return "", fmt.Errorf("%w: user not found", entities.ErrUnauthorized)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load user role: %w", err)
+	}
+
+	c.Set(roleCtx, role)
+	return role, nil
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/gin-gonic/gin"
+)
+
+// каталог товаров, ?search=&category=&include_archived=true
+func (h *Handler) ListProducts(c *gin.Context) {
+	var query entities.ProductQuery
+	if err := c.BindQuery(&query); err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid query parameters: "+err.Error())
+		return
+	}
+
+	products, err := h.services.Product.ListProducts(query)
+	if err != nil {
+		NewResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"products": products,
+		"total":    len(products),
+	})
+}
+
+func (h *Handler) GetProduct(c *gin.Context) {
+	product, err := h.services.Product.GetProduct(c.Param("id"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+func (h *Handler) CreateProduct(c *gin.Context) {
+	var input entities.ProductInput
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product, err := h.services.Product.CreateProduct(input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, product)
+}
+
+func (h *Handler) UpdateProduct(c *gin.Context) {
+	var input entities.ProductUpdate
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product, err := h.services.Product.UpdateProduct(c.Param("id"), input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// архивирование вместо удаления, на товар ссылается история
+func (h *Handler) ArchiveProduct(c *gin.Context) {
+	product, err := h.services.Product.ArchiveProduct(c.Param("id"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// возврат товара из архива, сканирования снова принимаются
+func (h *Handler) UnarchiveProduct(c *gin.Context) {
+	product, err := h.services.Product.UnarchiveProduct(c.Param("id"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// массовая загрузка каталога из csv файла
+func (h *Handler) ImportProducts(c *gin.Context) {
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		NewResponseError(c, http.StatusBadRequest, "failed to get file: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	result, err := h.services.Product.ImportProductsCSV(file)
+	if err != nil {
+		NewResponseError(c, http.StatusInternalServerError, "import failed: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -14,3 +18,21 @@ func NewResponseError(c *gin.Context, statusCode int, message string) {
 	logrus.Error(message)
 	c.AbortWithStatusJSON(statusCode, errorResponse{message})
 }
+
+// выбор http статуса по ошибке сервиса
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, entities.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entities.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, entities.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, entities.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, entities.ErrNotSupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sirupsen/logrus"
+)
+
+const maxRobotBatchItems = 1000
+
+// пакетная загрузка сканирований из буфера робота: JSON массив или NDJSON
+func (h *Handler) RobotsBatch(c *gin.Context) {
+	robotID, ok := c.Get(robotCtx)
+	if !ok {
+		NewResponseError(c, http.StatusInternalServerError, "robot id not found")
+		return
+	}
+
+	raw, err := readBatch(c.Request.Body, c.ContentType())
+	if err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(raw) == 0 {
+		NewResponseError(c, http.StatusBadRequest, "empty batch")
+		return
+	}
+	if len(raw) > maxRobotBatchItems {
+		NewResponseError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("batch is limited to %d items", maxRobotBatchItems))
+		return
+	}
+
+	response := entities.BatchIngestResult{Items: make([]entities.BatchItemResult, len(raw))}
+	valid := make([]entities.RobotsData, 0, len(raw))
+	positions := make([]int, 0, len(raw))
+
+	// проверка каждого сообщения, невалидные отклоняются без записи
+	for i, item := range raw {
+		response.Items[i].Index = i
+
+		var rd entities.RobotsData
+		if err := json.Unmarshal(item, &rd); err != nil {
+			response.Items[i].Status, response.Items[i].Error = "rejected", err.Error()
+			continue
+		}
+		response.Items[i].ClientMessageID = rd.MessageID
+		if err := binding.Validator.ValidateStruct(&rd); err != nil {
+			response.Items[i].Status, response.Items[i].Error = "rejected", err.Error()
+			continue
+		}
+		if rd.RobotId != robotID {
+			response.Items[i].Status, response.Items[i].Error = "rejected", fmt.Sprintf("robot %v cannot send data as %s", robotID, rd.RobotId)
+			continue
+		}
+
+		valid = append(valid, rd)
+		positions = append(positions, i)
+	}
+
+	results := h.services.Robot.AddBatch(valid)
+	for j, result := range results {
+		item := &response.Items[positions[j]]
+		item.MessageID = result.MessageID
+		switch {
+		case result.Error != "":
+			item.Status, item.Error = "rejected", result.Error
+		case result.Duplicate:
+			item.Status = "duplicate"
+		default:
+			item.Status = "accepted"
+		}
+	}
+
+	for _, item := range response.Items {
+		switch item.Status {
+		case "accepted":
+			response.Accepted++
+		case "duplicate":
+			response.Duplicates++
+		default:
+			response.Rejected++
+		}
+	}
+
+	logrus.Printf("batch from robot %v: accepted=%d duplicates=%d rejected=%d", robotID, response.Accepted, response.Duplicates, response.Rejected)
+	c.JSON(http.StatusOK, response)
+}
+
+// разбор тела пакета на отдельные сообщения
+func readBatch(body io.Reader, contentType string) ([]json.RawMessage, error) {
+	if strings.Contains(contentType, "ndjson") || strings.Contains(contentType, "jsonlines") {
+		var items []json.RawMessage
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 64*1024), maxRobotBodySize)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			items = append(items, json.RawMessage(append([]byte(nil), line...)))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read ndjson: %w", err)
+		}
+		return items, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("batch must be a JSON array or NDJSON: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// список роботов реестра
+func (h *Handler) ListRobots(c *gin.Context) {
+	robots, err := h.services.Robot.ListRobots()
+	if err != nil {
+		NewResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"robots": robots,
+		"total":  len(robots),
+	})
+}
+
+func (h *Handler) GetRobot(c *gin.Context) {
+	robot, err := h.services.Robot.GetRobot(c.Param("id"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, robot)
+}
+
+// регистрация нового робота
+func (h *Handler) RegisterRobot(c *gin.Context) {
+	var input entities.RobotRegistration
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	robot, err := h.services.Robot.RegisterRobot(input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, robot)
+}
+
+func (h *Handler) UpdateRobot(c *gin.Context) {
+	var input entities.RobotUpdate
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	robot, err := h.services.Robot.UpdateRobot(c.Param("id"), input)
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, robot)
+}
+
+// списание робота без удаления истории
+func (h *Handler) DecommissionRobot(c *gin.Context) {
+	robot, err := h.services.Robot.DecommissionRobot(c.Param("id"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	logrus.Printf("robot %s decommissioned", robot.ID)
+	c.JSON(http.StatusOK, robot)
+}
+
+func (h *Handler) DeleteRobot(c *gin.Context) {
+	if err := h.services.Robot.DeleteRobot(c.Param("id")); err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "deleted",
+	})
+}
+
+// список ключей робота без секретов
+func (h *Handler) ListRobotKeys(c *gin.Context) {
+	keys, err := h.services.RobotAuth.ListKeys(c.Param("id"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"keys": keys,
+	})
+}
+
+// выпуск дополнительного ключа робота
+func (h *Handler) IssueRobotKey(c *gin.Context) {
+	key, err := h.services.RobotAuth.IssueKey(c.Param("id"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	logrus.Printf("key %s issued for robot %s", key.KeyID, key.RobotID)
+	c.JSON(http.StatusCreated, key)
+}
+
+// выпуск нового ключа с отзывом всех старых
+func (h *Handler) RotateRobotKeys(c *gin.Context) {
+	key, err := h.services.RobotAuth.RotateKeys(c.Param("id"))
+	if err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	logrus.Printf("keys of robot %s rotated, active key %s", key.RobotID, key.KeyID)
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) RevokeRobotKey(c *gin.Context) {
+	if err := h.services.RobotAuth.RevokeKey(c.Param("id"), c.Param("keyId")); err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	logrus.Printf("key %s of robot %s revoked", c.Param("keyId"), c.Param("id"))
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "revoked",
+	})
+}
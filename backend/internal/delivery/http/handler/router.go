@@ -39,37 +39,118 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			auth.POST("/login", h.Login)
 		}
 
-		robots := api.Group("/robots", h.RobotIdentity)
+		robots := api.Group("/robots")
 		{
-			robots.POST("/data", h.Robots)
+			robots.POST("/data", h.RobotIdentity, h.Robots)
+			robots.POST("/data/batch", h.RobotIdentity, h.RobotsBatch)
+
+			registry := robots.Group("", h.UserIdentity)
+			registry.GET("", h.RequirePermission(permRead), h.ListRobots)
+			registry.GET("/:id", h.RequirePermission(permRead), h.GetRobot)
+			registry.POST("", h.RequirePermission(permManageRobots), h.RegisterRobot)
+			registry.PUT("/:id", h.RequirePermission(permManageRobots), h.UpdateRobot)
+			registry.POST("/:id/decommission", h.RequirePermission(permManageRobots), h.DecommissionRobot)
+			registry.DELETE("/:id", h.RequirePermission(permManageRobots), h.DeleteRobot)
+
+			keys := registry.Group("/:id/keys", h.RequirePermission(permManageRobots))
+			keys.GET("", h.ListRobotKeys)
+			keys.POST("", h.IssueRobotKey)
+			keys.POST("/rotate", h.RotateRobotKeys)
+			keys.DELETE("/:keyId", h.RevokeRobotKey)
 		}
-		ws := api.Group("/ws", h.UserIdentity, h.WebsocketIdentity)
+		ws := api.Group("/ws", h.UserIdentity, h.RequirePermission(permRead), h.WebsocketIdentity)
 		{
 			ws.GET("/dashboard", h.WebsocketDashBoard)
 		}
 		inventory := api.Group("/inventory", h.UserIdentity)
 		{
-			inventory.POST("/import", h.ImportInventory)
-			inventory.GET("/history", h.exportInventoryHistory)
+			inventory.POST("/import", h.RequirePermission(permInventoryWrite), h.ImportInventory)
+			inventory.GET("/history", h.RequirePermission(permRead), h.exportInventoryHistory)
+			inventory.GET("/stock", h.RequirePermission(permRead), h.GetStock)
+			inventory.GET("/anomalies", h.RequirePermission(permRead), h.ListAnomalies)
+			inventory.POST("/anomalies/:id/confirm", h.RequirePermission(permInventoryWrite), h.ConfirmAnomaly)
+			inventory.POST("/anomalies/:id/reject", h.RequirePermission(permInventoryWrite), h.RejectAnomaly)
+		}
+
+		products := api.Group("/products", h.UserIdentity)
+		{
+			products.GET("", h.RequirePermission(permRead), h.ListProducts)
+			products.GET("/:id", h.RequirePermission(permRead), h.GetProduct)
+			products.POST("", h.RequirePermission(permInventoryWrite), h.CreateProduct)
+			products.POST("/import", h.RequirePermission(permInventoryWrite), h.ImportProducts)
+			products.PUT("/:id", h.RequirePermission(permInventoryWrite), h.UpdateProduct)
+			products.POST("/:id/archive", h.RequirePermission(permInventoryWrite), h.ArchiveProduct)
+			products.POST("/:id/unarchive", h.RequirePermission(permInventoryWrite), h.UnarchiveProduct)
 		}
 
-		export := api.Group("/export", h.UserIdentity)
+		locations := api.Group("/locations", h.UserIdentity)
+		{
+			locations.GET("", h.RequirePermission(permRead), h.ListLocations)
+			locations.GET("/:zone", h.RequirePermission(permRead), h.GetLocation)
+			locations.POST("", h.RequirePermission(permManageLayout), h.CreateLocation)
+			locations.PUT("/:zone", h.RequirePermission(permManageLayout), h.UpdateLocation)
+			locations.DELETE("/:zone", h.RequirePermission(permManageLayout), h.DeleteLocation)
+		}
+
+		export := api.Group("/export", h.UserIdentity, h.RequirePermission(permInventoryWrite))
 		{
 			export.GET("/excel", h.ExportExcel)
 		}
-		dashboard := api.Group("/dashboard", h.UserIdentity)
+		dashboard := api.Group("/dashboard", h.UserIdentity, h.RequirePermission(permRead))
 		{
 			dashboard.GET("/current", h.GetDashInfo)
 		}
 		ai := api.Group("/ai", h.UserIdentity)
 		{
-			ai.POST("/predict", h.AIRequest)
+			ai.POST("/predict", h.RequirePermission(permAIPredict), h.AIRequest)
+			ai.POST("/ask", h.RequirePermission(permAIPredict), h.AskAI)
+			ai.GET("/accuracy", h.RequirePermission(permRead), h.GetAccuracy)
+			ai.GET("/predictions", h.RequirePermission(permRead), h.ListPredictions)
+			ai.GET("/predictions/latest", h.RequirePermission(permRead), h.LatestPredictions)
+		}
+
+		alerts := api.Group("/alerts", h.UserIdentity)
+		{
+			alerts.GET("", h.RequirePermission(permRead), h.ListAlerts)
+			alerts.GET("/:id", h.RequirePermission(permRead), h.GetAlert)
+			alerts.POST("/:id/acknowledge", h.RequirePermission(permInventoryWrite), h.AcknowledgeAlert)
+			alerts.POST("/:id/assign", h.RequirePermission(permInventoryWrite), h.AssignAlert)
+			alerts.POST("/:id/resolve", h.RequirePermission(permInventoryWrite), h.ResolveAlert)
 		}
 
-		monitoring := api.Group("/monitoring", h.UserIdentity)
+		alertRules := api.Group("/alert-rules", h.UserIdentity)
+		{
+			alertRules.GET("", h.RequirePermission(permRead), h.ListAlertRules)
+			alertRules.GET("/:id", h.RequirePermission(permRead), h.GetAlertRule)
+			alertRules.POST("", h.RequirePermission(permManageAlerts), h.CreateAlertRule)
+			alertRules.PUT("/:id", h.RequirePermission(permManageAlerts), h.UpdateAlertRule)
+			alertRules.DELETE("/:id", h.RequirePermission(permManageAlerts), h.DeleteAlertRule)
+			alertRules.POST("/evaluate", h.RequirePermission(permManageAlerts), h.EvaluateAlertRules)
+		}
+
+		notifications := api.Group("/notifications", h.UserIdentity)
+		{
+			notifications.GET("/deliveries", h.RequirePermission(permRead), h.ListNotificationDeliveries)
+
+			channels := notifications.Group("/channels", h.RequirePermission(permManageAlerts))
+			channels.GET("", h.ListNotificationChannels)
+			channels.GET("/:id", h.GetNotificationChannel)
+			channels.POST("", h.CreateNotificationChannel)
+			channels.PUT("/:id", h.UpdateNotificationChannel)
+			channels.DELETE("/:id", h.DeleteNotificationChannel)
+			channels.POST("/:id/test", h.TestNotificationChannel)
+		}
+
+		monitoring := api.Group("/monitoring", h.UserIdentity, h.RequirePermission(permRead))
 		{
 			monitoring.GET("/robots/status", h.GetRobotsStatus)
 		}
+
+		users := api.Group("/users", h.UserIdentity, h.RequirePermission(permManageUsers))
+		{
+			users.GET("", h.ListUsers)
+			users.PATCH("/:id/role", h.UpdateUserRole)
+		}
 	}
 
 	return router
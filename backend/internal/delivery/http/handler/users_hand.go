@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type updateRoleInput struct {
+	Role string `json:"role" binding:"required,oneof=operator admin viewer"`
+}
+
+// список пользователей без хэшей паролей
+func (h *Handler) ListUsers(c *gin.Context) {
+	users, err := h.services.Authorization.ListUsers()
+	if err != nil {
+		NewResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := make([]map[string]interface{}, 0, len(users))
+	for _, user := range users {
+		result = append(result, map[string]interface{}{
+			"id":         user.ID,
+			"email":      user.Email,
+			"name":       user.Name,
+			"role":       user.Role,
+			"created_at": user.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"users": result,
+	})
+}
+
+// смена роли пользователя
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		NewResponseError(c, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	var input updateRoleInput
+	if err := c.BindJSON(&input); err != nil {
+		NewResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.Authorization.UpdateUserRole(uint(id), input.Role); err != nil {
+		NewResponseError(c, errorStatus(err), err.Error())
+		return
+	}
+
+	logrus.Printf("role of user %d changed to %s", id, input.Role)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"id":   id,
+		"role": input.Role,
+	})
+}
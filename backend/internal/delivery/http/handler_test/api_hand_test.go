@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/Senpa1k/Smart_Warehouse/internal/delivery/http/handler"
 	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"github.com/Senpa1k/Smart_Warehouse/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -27,10 +29,18 @@ func setupTestRouter() *gin.Engine {
 func createTestHandler(mocks *MockServices) *handler.Handler {
 	services := &service.Service{
 		Robot:              mocks.Robot,
+		RobotAuth:          mocks.RobotAuth,
 		DashBoard:          mocks.DashBoard,
 		WebsocketDashBoard: mocks.WebsocketDashBoard,
 		AI:                 mocks.AI,
+		Assistant:          mocks.Assistant,
+		Accuracy:           mocks.Accuracy,
+		Alert:              mocks.Alert,
+		AlertRule:          mocks.AlertRule,
+		Notification:       mocks.Notification,
 		Inventory:          mocks.Inventory,
+		Product:            mocks.Product,
+		Location:           mocks.Location,
 		Redis:              mocks.Redis,
 		Authorization:      mocks.Authorization,
 	}
@@ -80,7 +90,7 @@ func TestRobotsEndpoint(t *testing.T) {
 				NextCheckpoint: "checkpoint1",
 			},
 			mockSetup: func() {
-				mocks.Robot.On("AddData", mock.AnythingOfType("entities.RobotsData")).Return(nil)
+				mocks.Robot.On("AddData", mock.AnythingOfType("entities.RobotsData")).Return(&entities.IngestResult{MessageID: "msg_1"}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -111,7 +121,7 @@ func TestRobotsEndpoint(t *testing.T) {
 				NextCheckpoint: "checkpoint1",
 			},
 			mockSetup: func() {
-				mocks.Robot.On("AddData", mock.AnythingOfType("entities.RobotsData")).Return(errors.New("service error"))
+				mocks.Robot.On("AddData", mock.AnythingOfType("entities.RobotsData")).Return(nil, errors.New("service error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -134,6 +144,94 @@ func TestRobotsEndpoint(t *testing.T) {
 	}
 }
 
+func TestRobotsEndpointMessageID(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.POST("/robots", func(c *gin.Context) {
+		c.Set("robotId", "RB-001")
+		h.Robots(c)
+	})
+
+	body := `{"message_id":"client-1","robot_id":"RB-001","timestamp":"2025-01-01T10:00:00Z",
+		"location":{"zone":"A","row":1,"shelf":2},"scan_results":[],"battery_level":80,"next_checkpoint":"A-2-2"}`
+
+	send := func() map[string]interface{} {
+		req, _ := http.NewRequest("POST", "/robots", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	withClientID := mock.MatchedBy(func(rd entities.RobotsData) bool { return rd.MessageID == "client-1" })
+
+	mocks.Robot.On("AddData", withClientID).Return(&entities.IngestResult{MessageID: "msg_abc"}, nil).Once()
+	resp := send()
+	assert.Equal(t, "received", resp["status"])
+	assert.Equal(t, "msg_abc", resp["message_id"])
+
+	mocks.Robot.On("AddData", withClientID).Return(&entities.IngestResult{MessageID: "msg_abc", Duplicate: true}, nil).Once()
+	resp = send()
+	assert.Equal(t, "duplicate", resp["status"])
+	assert.Equal(t, "msg_abc", resp["message_id"])
+
+	mocks.Robot.AssertExpectations(t)
+}
+
+func TestRobotsEndpointForeignRobot(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.POST("/robots", func(c *gin.Context) {
+		c.Set("robotId", "RB-002")
+		h.Robots(c)
+	})
+
+	body := `{"robot_id":"RB-001","timestamp":"2025-01-01T10:00:00Z",
+		"location":{"zone":"A","row":1,"shelf":2},"scan_results":[],"battery_level":80,"next_checkpoint":"A-2-2"}`
+	req, _ := http.NewRequest("POST", "/robots", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mocks.Robot.AssertNotCalled(t, "AddData", mock.Anything)
+}
+
+func TestRobotsEndpointRejectedScan(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.POST("/robots", func(c *gin.Context) {
+		c.Set("robotId", "RB-001")
+		h.Robots(c)
+	})
+	send := func() int {
+		body := `{"robot_id":"RB-001","timestamp":"2025-01-01T10:00:00Z",
+			"location":{"zone":"Z","row":1,"shelf":2},"scan_results":[],"battery_level":80,"next_checkpoint":"A-2-2"}`
+		req, _ := http.NewRequest("POST", "/robots", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// такое сообщение не будет принято и при повторе
+	mocks.Robot.On("AddData", mock.Anything).Return(nil, fmt.Errorf("%w: unknown zone \"Z\"", entities.ErrValidation)).Once()
+	assert.Equal(t, http.StatusBadRequest, send())
+
+	mocks.Robot.On("AddData", mock.Anything).Return(nil, errors.New("connection reset")).Once()
+	assert.Equal(t, http.StatusInternalServerError, send())
+}
+
 func TestGetDashInfo(t *testing.T) {
 	mocks := NewMockServices()
 	h := createTestHandler(mocks)
@@ -256,6 +354,444 @@ func TestExportExcel(t *testing.T) {
 	})
 }
 
+func TestGetStock(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.GET("/stock", h.GetStock)
+
+	t.Run("filters are passed to service", func(t *testing.T) {
+		stock := &entities.StockResponse{Products: []entities.ProductStock{
+			{ProductID: "TEL-4567", Total: 70, Status: models.StockOK},
+		}}
+		mocks.Inventory.On("GetStock", "TEL-4567", "A").Return(stock, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/stock?product_id=TEL-4567&zone=A", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body entities.StockResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, 70, body.Products[0].Total)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mocks.Inventory.On("GetStock", "", "").Return(nil, errors.New("db down")).Once()
+
+		req, _ := http.NewRequest("GET", "/stock", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestResolveAnomaly(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	withUser := func(handler gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("userId", uint(3))
+			handler(c)
+		}
+	}
+	router.GET("/anomalies", h.ListAnomalies)
+	router.POST("/anomalies/:id/confirm", withUser(h.ConfirmAnomaly))
+	router.POST("/anomalies/:id/reject", withUser(h.RejectAnomaly))
+
+	t.Run("list uses default limit", func(t *testing.T) {
+		response := &entities.HistoryResponse{Total: 1, Items: []models.InventoryHistory{{ID: 7, AnomalyStatus: models.AnomalyPending}}}
+		mocks.Inventory.On("ListAnomalies", entities.AnomalyQuery{Limit: 50}).Return(response, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/anomalies", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"anomaly_status":"pending"`)
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		history := &models.InventoryHistory{ID: 7, AnomalyStatus: models.AnomalyConfirmed}
+		mocks.Inventory.On("ResolveAnomaly", uint(7), true, uint(3)).Return(history, nil).Once()
+
+		req, _ := http.NewRequest("POST", "/anomalies/7/confirm", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("already resolved", func(t *testing.T) {
+		mocks.Inventory.On("ResolveAnomaly", uint(8), false, uint(3)).Return(nil, entities.ErrConflict).Once()
+
+		req, _ := http.NewRequest("POST", "/anomalies/8/reject", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/anomalies/abc/confirm", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAlerts(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	withUser := func(handler gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("userId", uint(3))
+			handler(c)
+		}
+	}
+	router.GET("/alerts", h.ListAlerts)
+	router.GET("/alerts/:id", h.GetAlert)
+	router.POST("/alerts/:id/acknowledge", withUser(h.AcknowledgeAlert))
+	router.POST("/alerts/:id/assign", h.AssignAlert)
+	router.POST("/alerts/:id/resolve", withUser(h.ResolveAlert))
+
+	t.Run("list with filters", func(t *testing.T) {
+		query := entities.AlertQuery{Status: models.AlertOpen, Zone: "A", Limit: 50}
+		response := &entities.AlertsResponse{Total: 1, Items: []models.Alert{{ID: 1, Status: models.AlertOpen}}}
+		mocks.Alert.On("ListAlerts", query).Return(response, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/alerts?status=open&zone=A", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total":1`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mocks.Alert.On("GetAlert", uint(9)).Return(nil, entities.ErrNotFound).Once()
+
+		req, _ := http.NewRequest("GET", "/alerts/9", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("acknowledge without body", func(t *testing.T) {
+		alert := &models.Alert{ID: 1, Status: models.AlertAcknowledged}
+		mocks.Alert.On("AcknowledgeAlert", uint(1), uint(3), entities.AlertAcknowledge{}).Return(alert, nil).Once()
+
+		req, _ := http.NewRequest("POST", "/alerts/1/acknowledge", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"acknowledged"`)
+	})
+
+	t.Run("assign requires assignee", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/alerts/1/assign", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mocks.Alert.AssertNotCalled(t, "AssignAlert", mock.Anything, mock.Anything)
+	})
+
+	t.Run("resolve already resolved", func(t *testing.T) {
+		input := entities.AlertResolve{Note: "пополнено"}
+		mocks.Alert.On("ResolveAlert", uint(2), uint(3), input).Return(nil, entities.ErrConflict).Once()
+
+		req, _ := http.NewRequest("POST", "/alerts/2/resolve", bytes.NewBufferString(`{"note":"пополнено"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestAlertRules(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.POST("/alert-rules", h.CreateAlertRule)
+	router.PUT("/alert-rules/:id", h.UpdateAlertRule)
+	router.DELETE("/alert-rules/:id", h.DeleteAlertRule)
+	router.POST("/alert-rules/evaluate", h.EvaluateAlertRules)
+
+	t.Run("create", func(t *testing.T) {
+		input := entities.AlertRuleInput{Name: "Заряд робота", Kind: models.RuleRobotBattery, Threshold: 15, Severity: models.SeverityWarning}
+		rule := &models.AlertRule{ID: 5, Name: input.Name, Kind: input.Kind, Threshold: 15, Severity: input.Severity, Enabled: true}
+		mocks.AlertRule.On("CreateRule", input).Return(rule, nil).Once()
+
+		body := `{"name":"Заряд робота","kind":"robot_battery_below","threshold":15,"severity":"warning"}`
+		req, _ := http.NewRequest("POST", "/alert-rules", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"kind":"robot_battery_below"`)
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		mocks.AlertRule.On("UpdateRule", uint(5), mock.Anything).Return(nil, entities.ErrValidation).Once()
+
+		req, _ := http.NewRequest("PUT", "/alert-rules/5", bytes.NewBufferString(`{"threshold":150}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("delete unknown rule", func(t *testing.T) {
+		mocks.AlertRule.On("DeleteRule", uint(9)).Return(entities.ErrNotFound).Once()
+
+		req, _ := http.NewRequest("DELETE", "/alert-rules/9", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("evaluate", func(t *testing.T) {
+		mocks.AlertRule.On("EvaluateRules", mock.AnythingOfType("time.Time")).Return(2, nil).Once()
+
+		req, _ := http.NewRequest("POST", "/alert-rules/evaluate", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"raised":2`)
+	})
+}
+
+func TestNotifications(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.POST("/channels", h.CreateNotificationChannel)
+	router.DELETE("/channels/:id", h.DeleteNotificationChannel)
+	router.POST("/channels/:id/test", h.TestNotificationChannel)
+	router.GET("/deliveries", h.ListNotificationDeliveries)
+
+	t.Run("create", func(t *testing.T) {
+		input := entities.NotificationChannelInput{Name: "Дежурный", Kind: models.ChannelTelegram, Target: "1001", Severities: "critical"}
+		channel := &models.NotificationChannel{ID: 2, Name: input.Name, Kind: input.Kind, Target: "1001", Severities: "critical", Enabled: true}
+		mocks.Notification.On("CreateChannel", input).Return(channel, nil).Once()
+
+		body := `{"name":"Дежурный","kind":"telegram","target":"1001","severities":"critical"}`
+		req, _ := http.NewRequest("POST", "/channels", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"kind":"telegram"`)
+	})
+
+	t.Run("secret is not returned", func(t *testing.T) {
+		input := entities.NotificationChannelInput{Name: "ERP", Kind: models.ChannelWebhook, Target: "https://erp.local/hook", Secret: "whsec_0123456789abcdef"}
+		channel := &models.NotificationChannel{ID: 3, Name: "ERP", Kind: models.ChannelWebhook, Target: input.Target, Secret: input.Secret, Enabled: true}
+		mocks.Notification.On("CreateChannel", input).Return(channel, nil).Once()
+
+		body := `{"name":"ERP","kind":"webhook","target":"https://erp.local/hook","secret":"whsec_0123456789abcdef"}`
+		req, _ := http.NewRequest("POST", "/channels", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotContains(t, w.Body.String(), "whsec_")
+	})
+
+	t.Run("test reports channel error", func(t *testing.T) {
+		result := &entities.ChannelTestResult{Status: models.DeliveryFailed, Error: "webhook responded 503: busy"}
+		mocks.Notification.On("TestChannel", mock.Anything, uint(3)).Return(result, nil).Once()
+
+		req, _ := http.NewRequest("POST", "/channels/3/test", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"failed"`)
+	})
+
+	t.Run("delete unknown channel", func(t *testing.T) {
+		mocks.Notification.On("DeleteChannel", uint(9)).Return(entities.ErrNotFound).Once()
+
+		req, _ := http.NewRequest("DELETE", "/channels/9", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("deliveries of alert", func(t *testing.T) {
+		alertID := uint(12)
+		query := entities.DeliveryQuery{AlertID: &alertID, Limit: 50}
+		response := &entities.DeliveriesResponse{Total: 1, Items: []models.NotificationDelivery{{ID: 1, AlertID: 12, Status: models.DeliverySent}}}
+		mocks.Notification.On("ListDeliveries", query).Return(response, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/deliveries?alert_id=12", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"sent"`)
+	})
+}
+
+func TestAskAI(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.POST("/ask", h.AskAI)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/ask", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("answer with citations", func(t *testing.T) {
+		answer := &entities.AskResponse{
+			Question:  "что в зоне B ниже минимума?",
+			Answer:    "Роутер",
+			Citations: []entities.AskFact{{Ref: "S1", Source: "stock_levels", ProductID: "TEL-4567", Zone: "B", Quantity: 15}},
+			Provider:  "gigachat",
+		}
+		mocks.Assistant.On("Ask", "что в зоне B ниже минимума?").Return(answer, nil).Once()
+
+		w := post(`{"question": "что в зоне B ниже минимума?"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body entities.AskResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, *answer, body)
+	})
+
+	t.Run("question is required", func(t *testing.T) {
+		w := post(`{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("provider cannot answer", func(t *testing.T) {
+		mocks.Assistant.On("Ask", "сколько роутеров?").
+			Return(nil, fmt.Errorf("%w: provider statistical does not answer questions", entities.ErrNotSupported)).Once()
+
+		w := post(`{"question": "сколько роутеров?"}`)
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
+
+func TestGetAccuracy(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.GET("/accuracy", h.GetAccuracy)
+
+	t.Run("query is passed to service", func(t *testing.T) {
+		tolerance := 3
+		query := entities.AccuracyQuery{From: "2025-01-01", To: "2025-01-31", Tolerance: &tolerance}
+		report := &entities.AccuracyReport{
+			ToleranceDays: 3,
+			Overall:       entities.AccuracyMetrics{Key: "all", Evaluated: 4, MAE: 1.5, Bias: -0.5, HitRate: 0.75},
+		}
+		mocks.Accuracy.On("Report", query).Return(report, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/accuracy?from=2025-01-01&to=2025-01-31&tolerance=3", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body entities.AccuracyReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, report.Overall, body.Overall)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		mocks.Accuracy.On("Report", entities.AccuracyQuery{From: "yesterday"}).
+			Return(nil, fmt.Errorf("%w: invalid from date", entities.ErrValidation)).Once()
+
+		req, _ := http.NewRequest("GET", "/accuracy?from=yesterday", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestListPredictions(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.GET("/predictions", h.ListPredictions)
+	router.GET("/predictions/latest", h.LatestPredictions)
+
+	t.Run("filters and default limit", func(t *testing.T) {
+		confidence := 0.6
+		query := entities.PredictionQuery{ProductID: "TEL-4567", From: "2025-01-01", MinConfidence: &confidence, Limit: 50}
+		response := &entities.PredictionsResponse{Total: 1, Items: []models.AiPrediction{{ID: 1, DaysUntilStockout: 5}}}
+		mocks.AI.On("ListPredictions", query).Return(response, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/predictions?product_id=TEL-4567&from=2025-01-01&min_confidence=0.6", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body entities.PredictionsResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, int64(1), body.Total)
+		assert.Equal(t, 5, body.Items[0].DaysUntilStockout)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		mocks.AI.On("ListPredictions", entities.PredictionQuery{From: "tomorrow", Limit: 50}).
+			Return(nil, fmt.Errorf("%w: invalid date", entities.ErrValidation)).Once()
+
+		req, _ := http.NewRequest("GET", "/predictions?from=tomorrow", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("latest per product", func(t *testing.T) {
+		latest := []models.AiPrediction{{ID: 3, ProductID: "TEL-4567"}, {ID: 9, ProductID: "TEL-8901"}}
+		mocks.AI.On("LatestPredictions", entities.PredictionQuery{Category: "network"}).Return(latest, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/predictions/latest?category=network", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Predictions []models.AiPrediction `json:"predictions"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Len(t, body.Predictions, 2)
+	})
+}
+
 func TestGetRobotsStatus(t *testing.T) {
 	mocks := NewMockServices()
 	h := createTestHandler(mocks)
@@ -264,6 +800,16 @@ func TestGetRobotsStatus(t *testing.T) {
 	router.GET("/robots-status", h.GetRobotsStatus)
 
 	t.Run("redis available", func(t *testing.T) {
+		robots := []models.Robots{
+			{ID: "RB-001", Status: models.RobotStatusActive},
+			{ID: "RB-002", Status: models.RobotStatusActive},
+			{ID: "RB-003", Status: models.RobotStatusActive},
+			{ID: "RB-004", Status: models.RobotStatusActive},
+			{ID: "RB-005", Status: models.RobotStatusActive},
+			{ID: "RB-006", Status: models.RobotStatusDecommissioned},
+		}
+		mocks.Robot.On("ListRobots").Return(robots, nil)
+
 		// Mock responses for each robot
 		for _, robotID := range []string{"RB-001", "RB-002", "RB-003", "RB-004", "RB-005"} {
 			mocks.Redis.On("IsRobotOnline", robotID).Return(true, nil)
@@ -0,0 +1,99 @@
+package test_handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarehouseLayout(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.GET("/locations", h.ListLocations)
+	router.POST("/locations", h.CreateLocation)
+	router.PUT("/locations/:zone", h.UpdateLocation)
+	router.DELETE("/locations/:zone", h.DeleteLocation)
+
+	rows := 10
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "list layout",
+			method: "GET",
+			path:   "/locations",
+			mockSetup: func() {
+				mocks.Location.On("ListLocations").
+					Return([]models.Location{{Zone: "A", Rows: 20, ShelvesPerRow: 10}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "add zone",
+			method: "POST",
+			path:   "/locations",
+			body:   `{"zone":"F","rows":12,"shelves_per_row":8}`,
+			mockSetup: func() {
+				mocks.Location.On("CreateLocation", entities.LocationInput{Zone: "F", Rows: 12, ShelvesPerRow: 8}).
+					Return(&models.Location{Zone: "F", Rows: 12, ShelvesPerRow: 8}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "zone without rows",
+			method:         "POST",
+			path:           "/locations",
+			body:           `{"zone":"F","shelves_per_row":8}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "shrink zone with stock",
+			method: "PUT",
+			path:   "/locations/A",
+			body:   `{"rows":10}`,
+			mockSetup: func() {
+				mocks.Location.On("UpdateLocation", "A", entities.LocationUpdate{Rows: &rows}).
+					Return(nil, fmt.Errorf("%w: zone A has stock outside of the new bounds", entities.ErrConflict)).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "delete unknown zone",
+			method: "DELETE",
+			path:   "/locations/Z",
+			mockSetup: func() {
+				mocks.Location.On("DeleteLocation", "Z").Return(entities.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+	mocks.Location.AssertExpectations(t)
+}
@@ -2,10 +2,13 @@ package test_handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -105,6 +108,7 @@ func TestRobotIdentityMiddleware(t *testing.T) {
 	})
 
 	t.Run("valid robot authorization", func(t *testing.T) {
+		mocks.RobotAuth.On("LegacyAuthAllowed").Return(true).Once()
 		mocks.Robot.On("CheckId", "test-robot").Return(true)
 
 		req, _ := http.NewRequest("POST", "/robot-data", nil)
@@ -117,6 +121,7 @@ func TestRobotIdentityMiddleware(t *testing.T) {
 	})
 
 	t.Run("invalid robot id", func(t *testing.T) {
+		mocks.RobotAuth.On("LegacyAuthAllowed").Return(true).Once()
 		mocks.Robot.On("CheckId", "invalid-robot").Return(false)
 
 		req, _ := http.NewRequest("POST", "/robot-data", nil)
@@ -127,6 +132,82 @@ func TestRobotIdentityMiddleware(t *testing.T) {
 
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
+
+	t.Run("legacy token without robot id", func(t *testing.T) {
+		mocks.RobotAuth.On("LegacyAuthAllowed").Return(true).Once()
+
+		req, _ := http.NewRequest("POST", "/robot-data", nil)
+		req.Header.Set("Authorization", "Bearer token")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("legacy body over the limit", func(t *testing.T) {
+		mocks.RobotAuth.On("LegacyAuthAllowed").Return(true).Once()
+
+		req, _ := http.NewRequest("POST", "/robot-data", strings.NewReader(strings.Repeat("x", 10<<20+1)))
+		req.Header.Set("Authorization", "Bearer token_test-robot")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("legacy auth disabled", func(t *testing.T) {
+		mocks.RobotAuth.On("LegacyAuthAllowed").Return(false).Once()
+
+		req, _ := http.NewRequest("POST", "/robot-data", nil)
+		req.Header.Set("Authorization", "Bearer token_test-robot")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("signed request", func(t *testing.T) {
+		body := `{"robot_id":"RB-001"}`
+		mocks.RobotAuth.On("VerifyRequest", entities.RobotSignedRequest{
+			KeyID:     "rk_valid",
+			Timestamp: "1700000000",
+			Signature: "abcd",
+			Method:    "POST",
+			Path:      "/robot-data",
+			Body:      []byte(body),
+		}).Return("RB-001", nil).Once()
+		mocks.Robot.On("CheckId", "RB-001").Return(true).Once()
+
+		req, _ := http.NewRequest("POST", "/robot-data", strings.NewReader(body))
+		req.Header.Set("X-Robot-Key", "rk_valid")
+		req.Header.Set("X-Robot-Timestamp", "1700000000")
+		req.Header.Set("X-Robot-Signature", "abcd")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "RB-001")
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		mocks.RobotAuth.On("VerifyRequest", mock.MatchedBy(func(req entities.RobotSignedRequest) bool {
+			return req.KeyID == "rk_forged"
+		})).Return("", fmt.Errorf("%w: signature mismatch", entities.ErrUnauthorized)).Once()
+
+		req, _ := http.NewRequest("POST", "/robot-data", strings.NewReader(`{}`))
+		req.Header.Set("X-Robot-Key", "rk_forged")
+		req.Header.Set("X-Robot-Timestamp", "1700000000")
+		req.Header.Set("X-Robot-Signature", "ffff")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
 }
 
 func TestRateLimitMiddleware(t *testing.T) {
@@ -1,6 +1,7 @@
 package test_handler
 
 import (
+	"context"
 	"io"
 	"time"
 
@@ -16,9 +17,17 @@ type MockRobotService struct {
 	mock.Mock
 }
 
-func (m *MockRobotService) AddData(data entities.RobotsData) error {
+func (m *MockRobotService) AddData(data entities.RobotsData) (*entities.IngestResult, error) {
 	args := m.Called(data)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.IngestResult), args.Error(1)
+}
+
+func (m *MockRobotService) AddBatch(batch []entities.RobotsData) []entities.IngestResult {
+	args := m.Called(batch)
+	return args.Get(0).([]entities.IngestResult)
 }
 
 func (m *MockRobotService) CheckId(id string) bool {
@@ -26,6 +35,95 @@ func (m *MockRobotService) CheckId(id string) bool {
 	return args.Bool(0)
 }
 
+func (m *MockRobotService) ListRobots() ([]models.Robots, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Robots), args.Error(1)
+}
+
+func (m *MockRobotService) GetRobot(id string) (*models.Robots, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Robots), args.Error(1)
+}
+
+func (m *MockRobotService) RegisterRobot(input entities.RobotRegistration) (*models.Robots, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Robots), args.Error(1)
+}
+
+func (m *MockRobotService) UpdateRobot(id string, input entities.RobotUpdate) (*models.Robots, error) {
+	args := m.Called(id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Robots), args.Error(1)
+}
+
+func (m *MockRobotService) DecommissionRobot(id string) (*models.Robots, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Robots), args.Error(1)
+}
+
+func (m *MockRobotService) DeleteRobot(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// MockRobotAuthService мок сервиса ключей роботов
+type MockRobotAuthService struct {
+	mock.Mock
+}
+
+func (m *MockRobotAuthService) IssueKey(robotID string) (*entities.RobotKey, error) {
+	args := m.Called(robotID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.RobotKey), args.Error(1)
+}
+
+func (m *MockRobotAuthService) RotateKeys(robotID string) (*entities.RobotKey, error) {
+	args := m.Called(robotID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.RobotKey), args.Error(1)
+}
+
+func (m *MockRobotAuthService) ListKeys(robotID string) ([]models.RobotCredential, error) {
+	args := m.Called(robotID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RobotCredential), args.Error(1)
+}
+
+func (m *MockRobotAuthService) RevokeKey(robotID, keyID string) error {
+	args := m.Called(robotID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockRobotAuthService) VerifyRequest(req entities.RobotSignedRequest) (string, error) {
+	args := m.Called(req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRobotAuthService) LegacyAuthAllowed() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
 // MockDashboardService мок сервиса дашборда
 type MockDashboardService struct {
 	mock.Mock
@@ -58,6 +156,215 @@ func (m *MockAIService) Predict(request entities.AIRequest) (*entities.AIRespons
 	return args.Get(0).(*entities.AIResponse), args.Error(1)
 }
 
+func (m *MockAIService) ListPredictions(query entities.PredictionQuery) (*entities.PredictionsResponse, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PredictionsResponse), args.Error(1)
+}
+
+func (m *MockAIService) LatestPredictions(query entities.PredictionQuery) ([]models.AiPrediction, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AiPrediction), args.Error(1)
+}
+
+// MockAssistantService мок ответов на вопросы
+type MockAssistantService struct {
+	mock.Mock
+}
+
+func (m *MockAssistantService) Ask(question string) (*entities.AskResponse, error) {
+	args := m.Called(question)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.AskResponse), args.Error(1)
+}
+
+// MockAccuracyService мок сервиса точности прогнозов
+type MockAccuracyService struct {
+	mock.Mock
+}
+
+func (m *MockAccuracyService) Run(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockAccuracyService) EvaluatePredictions(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccuracyService) Report(query entities.AccuracyQuery) (*entities.AccuracyReport, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.AccuracyReport), args.Error(1)
+}
+
+// MockAlertService мок сервиса оповещений
+type MockAlertService struct {
+	mock.Mock
+}
+
+func (m *MockAlertService) ListAlerts(query entities.AlertQuery) (*entities.AlertsResponse, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.AlertsResponse), args.Error(1)
+}
+
+func (m *MockAlertService) GetAlert(id uint) (*models.Alert, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Alert), args.Error(1)
+}
+
+func (m *MockAlertService) AcknowledgeAlert(id, userID uint, input entities.AlertAcknowledge) (*models.Alert, error) {
+	args := m.Called(id, userID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Alert), args.Error(1)
+}
+
+func (m *MockAlertService) AssignAlert(id uint, input entities.AlertAssign) (*models.Alert, error) {
+	args := m.Called(id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Alert), args.Error(1)
+}
+
+func (m *MockAlertService) ResolveAlert(id, userID uint, input entities.AlertResolve) (*models.Alert, error) {
+	args := m.Called(id, userID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Alert), args.Error(1)
+}
+
+// MockAlertRuleService мок сервиса правил оповещений
+type MockAlertRuleService struct {
+	mock.Mock
+}
+
+func (m *MockAlertRuleService) Run(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockAlertRuleService) EvaluateRules(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAlertRuleService) ListRules() ([]models.AlertRule, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRuleService) GetRule(id uint) (*models.AlertRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRuleService) CreateRule(input entities.AlertRuleInput) (*models.AlertRule, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRuleService) UpdateRule(id uint, input entities.AlertRuleUpdate) (*models.AlertRule, error) {
+	args := m.Called(id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRuleService) DeleteRule(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// MockNotificationService мок сервиса уведомлений
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) Run(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockNotificationService) ListChannels() ([]models.NotificationChannel, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationService) GetChannel(id uint) (*models.NotificationChannel, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationService) CreateChannel(input entities.NotificationChannelInput) (*models.NotificationChannel, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationService) UpdateChannel(id uint, input entities.NotificationChannelUpdate) (*models.NotificationChannel, error) {
+	args := m.Called(id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationService) DeleteChannel(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) TestChannel(ctx context.Context, id uint) (*entities.ChannelTestResult, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ChannelTestResult), args.Error(1)
+}
+
+func (m *MockNotificationService) ListDeliveries(query entities.DeliveryQuery) (*entities.DeliveriesResponse, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.DeliveriesResponse), args.Error(1)
+}
+
 // MockInventoryService мок сервиса инвентаря
 type MockInventoryService struct {
 	mock.Mock
@@ -87,6 +394,133 @@ func (m *MockInventoryService) GetHistory(from, to, zone, status string, limit,
 	return args.Get(0).(*entities.HistoryResponse), args.Error(1)
 }
 
+func (m *MockInventoryService) GetStock(productID, zone string) (*entities.StockResponse, error) {
+	args := m.Called(productID, zone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.StockResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) ListAnomalies(query entities.AnomalyQuery) (*entities.HistoryResponse, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.HistoryResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) ResolveAnomaly(id uint, confirm bool, userID uint) (*models.InventoryHistory, error) {
+	args := m.Called(id, confirm, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InventoryHistory), args.Error(1)
+}
+
+// MockProductService мок сервиса каталога товаров
+type MockProductService struct {
+	mock.Mock
+}
+
+func (m *MockProductService) ListProducts(query entities.ProductQuery) ([]models.Products, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Products), args.Error(1)
+}
+
+func (m *MockProductService) GetProduct(id string) (*models.Products, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Products), args.Error(1)
+}
+
+func (m *MockProductService) CreateProduct(input entities.ProductInput) (*models.Products, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Products), args.Error(1)
+}
+
+func (m *MockProductService) UpdateProduct(id string, input entities.ProductUpdate) (*models.Products, error) {
+	args := m.Called(id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Products), args.Error(1)
+}
+
+func (m *MockProductService) ArchiveProduct(id string) (*models.Products, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Products), args.Error(1)
+}
+
+func (m *MockProductService) UnarchiveProduct(id string) (*models.Products, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Products), args.Error(1)
+}
+
+func (m *MockProductService) ImportProductsCSV(csvData io.Reader) (*entities.ImportResult, error) {
+	args := m.Called(csvData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ImportResult), args.Error(1)
+}
+
+// MockLocationService мок сервиса раскладки склада
+type MockLocationService struct {
+	mock.Mock
+}
+
+func (m *MockLocationService) ListLocations() ([]models.Location, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Location), args.Error(1)
+}
+
+func (m *MockLocationService) GetLocation(zone string) (*models.Location, error) {
+	args := m.Called(zone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Location), args.Error(1)
+}
+
+func (m *MockLocationService) CreateLocation(input entities.LocationInput) (*models.Location, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Location), args.Error(1)
+}
+
+func (m *MockLocationService) UpdateLocation(zone string, input entities.LocationUpdate) (*models.Location, error) {
+	args := m.Called(zone, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Location), args.Error(1)
+}
+
+func (m *MockLocationService) DeleteLocation(zone string) error {
+	args := m.Called(zone)
+	return args.Error(0)
+}
+
 // MockRedisService мок Redis сервиса
 type MockRedisService struct {
 	mock.Mock
@@ -152,13 +586,39 @@ func (m *MockAuthService) ParseToken(token string) (uint, error) {
 	return uint(args.Int(0)), args.Error(1)
 }
 
+func (m *MockAuthService) GetUserRole(userID uint) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthService) ListUsers() ([]models.Users, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Users), args.Error(1)
+}
+
+func (m *MockAuthService) UpdateUserRole(userID uint, role string) error {
+	args := m.Called(userID, role)
+	return args.Error(0)
+}
+
 // MockServices мок всех сервисов
 type MockServices struct {
 	Robot              *MockRobotService
+	RobotAuth          *MockRobotAuthService
 	DashBoard          *MockDashboardService
 	WebsocketDashBoard *MockWebsocketDashboardService
 	AI                 *MockAIService
+	Assistant          *MockAssistantService
+	Accuracy           *MockAccuracyService
+	Alert              *MockAlertService
+	AlertRule          *MockAlertRuleService
+	Notification       *MockNotificationService
 	Inventory          *MockInventoryService
+	Product            *MockProductService
+	Location           *MockLocationService
 	Redis              *MockRedisService
 	Authorization      *MockAuthService
 }
@@ -167,10 +627,18 @@ type MockServices struct {
 func NewMockServices() *MockServices {
 	return &MockServices{
 		Robot:              new(MockRobotService),
+		RobotAuth:          new(MockRobotAuthService),
 		DashBoard:          new(MockDashboardService),
 		WebsocketDashBoard: new(MockWebsocketDashboardService),
 		AI:                 new(MockAIService),
+		Assistant:          new(MockAssistantService),
+		Accuracy:           new(MockAccuracyService),
+		Alert:              new(MockAlertService),
+		AlertRule:          new(MockAlertRuleService),
+		Notification:       new(MockNotificationService),
 		Inventory:          new(MockInventoryService),
+		Product:            new(MockProductService),
+		Location:           new(MockLocationService),
 		Redis:              new(MockRedisService),
 		Authorization:      new(MockAuthService),
 	}
@@ -181,6 +649,11 @@ func (m *MockRedisService) Exists(key string) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockRedisService) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	args := m.Called(key, value, expiration)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockRedisService) Publish(channel string, message interface{}) error {
 	args := m.Called(channel, message)
 	return args.Error(0)
@@ -0,0 +1,118 @@
+package test_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		roleErr        error
+		path           string
+		expectedStatus int
+	}{
+		{name: "viewer reads history", role: models.RoleViewer, path: "/read", expectedStatus: http.StatusOK},
+		{name: "viewer cannot import", role: models.RoleViewer, path: "/import", expectedStatus: http.StatusForbidden},
+		{name: "operator imports", role: models.RoleOperator, path: "/import", expectedStatus: http.StatusOK},
+		{name: "operator cannot manage users", role: models.RoleOperator, path: "/users", expectedStatus: http.StatusForbidden},
+		{name: "admin manages users", role: models.RoleAdmin, path: "/users", expectedStatus: http.StatusOK},
+		{name: "unknown role", role: "user", path: "/read", expectedStatus: http.StatusForbidden},
+		{name: "user deleted", roleErr: entities.ErrNotFound, path: "/read", expectedStatus: http.StatusUnauthorized},
+		{name: "role lookup fails", roleErr: errors.New("db error"), path: "/read", expectedStatus: http.StatusInternalServerError},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocks := NewMockServices()
+			h := createTestHandler(mocks)
+			userID := uint(i + 1)
+			mocks.Authorization.On("GetUserRole", userID).Return(tt.role, tt.roleErr).Once()
+
+			router := setupTestRouter()
+			router.Use(func(c *gin.Context) { c.Set("userId", userID) })
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/read", h.RequirePermission("read"), ok)
+			router.GET("/import", h.RequirePermission("inventory:write"), ok)
+			router.GET("/users", h.RequirePermission("users:manage"), ok)
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				var resp map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Contains(t, resp["message"], "forbidden")
+			}
+			mocks.Authorization.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.PATCH("/users/:id/role", h.UpdateUserRole)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "role changed",
+			path: "/users/2/role",
+			body: `{"role":"operator"}`,
+			mockSetup: func() {
+				mocks.Authorization.On("UpdateUserRole", uint(2), "operator").Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown role",
+			path:           "/users/2/role",
+			body:           `{"role":"root"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "user not found",
+			path: "/users/42/role",
+			body: `{"role":"viewer"}`,
+			mockSetup: func() {
+				mocks.Authorization.On("UpdateUserRole", uint(42), "viewer").Return(entities.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req, _ := http.NewRequest("PATCH", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+	mocks.Authorization.AssertExpectations(t)
+}
@@ -0,0 +1,155 @@
+package test_handler
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProductCatalog(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.GET("/products", h.ListProducts)
+	router.GET("/products/:id", h.GetProduct)
+	router.POST("/products", h.CreateProduct)
+	router.PUT("/products/:id", h.UpdateProduct)
+	router.POST("/products/:id/archive", h.ArchiveProduct)
+	router.POST("/products/:id/unarchive", h.UnarchiveProduct)
+
+	minStock := 20
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "search products",
+			method: "GET",
+			path:   "/products?search=router&category=network",
+			mockSetup: func() {
+				mocks.Product.On("ListProducts", entities.ProductQuery{Search: "router", Category: "network"}).
+					Return([]models.Products{{ID: "TEL-4567"}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "unknown product",
+			method: "GET",
+			path:   "/products/NOPE",
+			mockSetup: func() {
+				mocks.Product.On("GetProduct", "NOPE").Return(nil, entities.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "create product",
+			method: "POST",
+			path:   "/products",
+			body:   `{"id":"TEL-9999","name":"Точка доступа"}`,
+			mockSetup: func() {
+				mocks.Product.On("CreateProduct", entities.ProductInput{ID: "TEL-9999", Name: "Точка доступа"}).
+					Return(&models.Products{ID: "TEL-9999"}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "create without name",
+			method:         "POST",
+			path:           "/products",
+			body:           `{"id":"TEL-9999"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid thresholds",
+			method: "PUT",
+			path:   "/products/TEL-4567",
+			body:   `{"min_stock":20}`,
+			mockSetup: func() {
+				mocks.Product.On("UpdateProduct", "TEL-4567", entities.ProductUpdate{MinStock: &minStock}).
+					Return(nil, fmt.Errorf("%w: min_stock 20 exceeds optimal_stock 10", entities.ErrValidation)).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "archive product",
+			method: "POST",
+			path:   "/products/TEL-4567/archive",
+			mockSetup: func() {
+				mocks.Product.On("ArchiveProduct", "TEL-4567").Return(&models.Products{ID: "TEL-4567"}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "unarchive product",
+			method: "POST",
+			path:   "/products/TEL-4567/unarchive",
+			mockSetup: func() {
+				mocks.Product.On("UnarchiveProduct", "TEL-4567").Return(&models.Products{ID: "TEL-4567"}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "unarchive unknown product",
+			method: "POST",
+			path:   "/products/NOPE/unarchive",
+			mockSetup: func() {
+				mocks.Product.On("UnarchiveProduct", "NOPE").Return(nil, entities.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+	mocks.Product.AssertExpectations(t)
+}
+
+func TestImportProducts(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.POST("/products/import", h.ImportProducts)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "products.csv")
+	part.Write([]byte("id;name;category;min_stock;optimal_stock\nTEL-9999;Точка доступа;network;5;50\n"))
+	writer.Close()
+
+	mocks.Product.On("ImportProductsCSV", mock.Anything).
+		Return(&entities.ImportResult{SuccessCount: 1}, nil).Once()
+
+	req, _ := http.NewRequest("POST", "/products/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mocks.Product.AssertExpectations(t)
+}
@@ -0,0 +1,100 @@
+package test_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// scanJSON собирает валидное сообщение робота
+func scanJSON(messageID, robotID string) string {
+	return "{\"message_id\":\"" + messageID + "\",\"robot_id\":\"" + robotID + "\",\"timestamp\":\"2025-01-01T10:00:00Z\"," +
+		"\"location\":{\"zone\":\"A\",\"row\":1,\"shelf\":2},\"scan_results\":[],\"battery_level\":80,\"next_checkpoint\":\"A-2-2\"}"
+}
+
+func TestRobotsBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "json array",
+			contentType: "application/json",
+			body:        "[" + scanJSON("m1", "RB-001") + "," + scanJSON("m2", "RB-001") + `,{"robot_id":"RB-001"},` + scanJSON("m4", "RB-002") + "]",
+		},
+		{
+			name:        "ndjson stream",
+			contentType: "application/x-ndjson",
+			body:        scanJSON("m1", "RB-001") + "\n" + scanJSON("m2", "RB-001") + "\n\n" + `{"robot_id":"RB-001"}` + "\n" + scanJSON("m4", "RB-002") + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocks := NewMockServices()
+			h := createTestHandler(mocks)
+
+			router := setupTestRouter()
+			router.POST("/robots/batch", func(c *gin.Context) {
+				c.Set("robotId", "RB-001")
+				h.RobotsBatch(c)
+			})
+
+			mocks.Robot.On("AddBatch", mock.MatchedBy(func(batch []entities.RobotsData) bool {
+				return len(batch) == 2 && batch[0].MessageID == "m1" && batch[1].MessageID == "m2"
+			})).Return([]entities.IngestResult{
+				{MessageID: "msg_1"},
+				{MessageID: "msg_0", Duplicate: true},
+			}).Once()
+
+			req, _ := http.NewRequest("POST", "/robots/batch", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var resp entities.BatchIngestResult
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			assert.Equal(t, 1, resp.Accepted)
+			assert.Equal(t, 1, resp.Duplicates)
+			assert.Equal(t, 2, resp.Rejected)
+			if assert.Len(t, resp.Items, 4) {
+				assert.Equal(t, "msg_1", resp.Items[0].MessageID)
+				assert.Equal(t, "duplicate", resp.Items[1].Status)
+				assert.Equal(t, "rejected", resp.Items[2].Status)
+				assert.Equal(t, "rejected", resp.Items[3].Status)
+			}
+			mocks.Robot.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRobotsBatchInvalidBody(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.POST("/robots/batch", func(c *gin.Context) {
+		c.Set("robotId", "RB-001")
+		h.RobotsBatch(c)
+	})
+
+	for _, body := range []string{`{"robot_id":"RB-001"}`, `[]`} {
+		req, _ := http.NewRequest("POST", "/robots/batch", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+	mocks.Robot.AssertNotCalled(t, "AddBatch", mock.Anything)
+}
@@ -0,0 +1,136 @@
+package test_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRobotRegistry(t *testing.T) {
+	mocks := NewMockServices()
+	h := createTestHandler(mocks)
+
+	router := setupTestRouter()
+	router.GET("/robots", h.ListRobots)
+	router.GET("/robots/:id", h.GetRobot)
+	router.POST("/robots", h.RegisterRobot)
+	router.PUT("/robots/:id", h.UpdateRobot)
+	router.POST("/robots/:id/decommission", h.DecommissionRobot)
+	router.DELETE("/robots/:id", h.DeleteRobot)
+
+	maintenance := models.RobotStatusMaintenance
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "list robots",
+			method: "GET",
+			path:   "/robots",
+			mockSetup: func() {
+				mocks.Robot.On("ListRobots").Return([]models.Robots{{ID: "RB-001"}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "unknown robot",
+			method: "GET",
+			path:   "/robots/RB-404",
+			mockSetup: func() {
+				mocks.Robot.On("GetRobot", "RB-404").Return(nil, entities.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "register robot",
+			method: "POST",
+			path:   "/robots",
+			body:   `{"id":"RB-010","current_zone":"A"}`,
+			mockSetup: func() {
+				mocks.Robot.On("RegisterRobot", entities.RobotRegistration{ID: "RB-010", CurrentZone: "A"}).
+					Return(&models.Robots{ID: "RB-010", Status: models.RobotStatusActive}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "register without id",
+			method:         "POST",
+			path:           "/robots",
+			body:           `{"current_zone":"A"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "register duplicate",
+			method: "POST",
+			path:   "/robots",
+			body:   `{"id":"RB-001"}`,
+			mockSetup: func() {
+				mocks.Robot.On("RegisterRobot", entities.RobotRegistration{ID: "RB-001"}).
+					Return(nil, fmt.Errorf("%w: robot RB-001 already registered", entities.ErrConflict)).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "update robot status",
+			method: "PUT",
+			path:   "/robots/RB-001",
+			body:   `{"status":"maintenance"}`,
+			mockSetup: func() {
+				mocks.Robot.On("UpdateRobot", "RB-001", entities.RobotUpdate{Status: &maintenance}).
+					Return(&models.Robots{ID: "RB-001", Status: maintenance}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "decommission robot",
+			method: "POST",
+			path:   "/robots/RB-002/decommission",
+			mockSetup: func() {
+				mocks.Robot.On("DecommissionRobot", "RB-002").
+					Return(&models.Robots{ID: "RB-002", Status: models.RobotStatusDecommissioned}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "delete robot with history",
+			method: "DELETE",
+			path:   "/robots/RB-003",
+			mockSetup: func() {
+				mocks.Robot.On("DeleteRobot", "RB-003").Return(entities.ErrConflict).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code == http.StatusOK && tt.method == "GET" {
+				var resp map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Equal(t, float64(1), resp["total"])
+			}
+		})
+	}
+	mocks.Robot.AssertExpectations(t)
+}
@@ -8,6 +8,7 @@ import (
 
 // структура для данных от роботоа
 type RobotsData struct {
+	MessageID string    `json:"message_id" binding:"max=255"`
 	RobotId   string    `json:"robot_id" binding:"required"`
 	Timestamp time.Time `json:"timestamp" binding:"required"`
 	Location  struct {
@@ -20,6 +21,39 @@ type RobotsData struct {
 	NextCheckpoint string        `json:"next_checkpoint" binding:"required"`
 }
 
+// сообщение робота с выданным сервером id
+type IngestItem struct {
+	Data      RobotsData
+	MessageID string
+}
+
+// результат приема сообщения робота, Error заполняется для отклоненных
+type IngestResult struct {
+	MessageID string         `json:"message_id"`
+	Duplicate bool           `json:"duplicate"`
+	Error     string         `json:"error,omitempty"`
+	Err       error          `json:"-"` // причина отказа, ErrValidation - сообщение не будет принято и при повторе
+	Statuses  []string       `json:"-"` // вычисленные статусы в порядке scan_results
+	Anomalies []bool         `json:"-"` // сканирования, отмеченные как выбросы, в том же порядке
+	Alerts    []models.Alert `json:"-"` // оповещения, открытые или усиленные сообщением
+}
+
+// результат пакетной загрузки сканирований
+type BatchIngestResult struct {
+	Accepted   int               `json:"accepted"`
+	Duplicates int               `json:"duplicates"`
+	Rejected   int               `json:"rejected"`
+	Items      []BatchItemResult `json:"items"`
+}
+
+type BatchItemResult struct {
+	Index           int    `json:"index"`
+	ClientMessageID string `json:"client_message_id,omitempty"`
+	MessageID       string `json:"message_id,omitempty"`
+	Status          string `json:"status"` // accepted, duplicate, rejected
+	Error           string `json:"error,omitempty"`
+}
+
 type ScanResults struct {
 	ProductId   string `json:"product_id" binding:"required"`
 	ProductName string `json:"product_name" binding:"required"`
@@ -27,6 +61,79 @@ type ScanResults struct {
 	Status      string `json:"status" binding:"required"`
 }
 
+// структуры для реестра роботов
+type RobotRegistration struct {
+	ID           string `json:"id" binding:"required,max=50"`
+	Status       string `json:"status"`
+	CurrentZone  string `json:"current_zone" binding:"max=10"`
+	CurrentRow   int    `json:"current_row"`
+	CurrentShelf int    `json:"current_shelf"`
+}
+
+type RobotUpdate struct {
+	Status       *string `json:"status"`
+	CurrentZone  *string `json:"current_zone" binding:"omitempty,max=10"`
+	CurrentRow   *int    `json:"current_row"`
+	CurrentShelf *int    `json:"current_shelf"`
+}
+
+type ProductQuery struct {
+	Search          string `form:"search"`
+	Category        string `form:"category"`
+	IncludeArchived bool   `form:"include_archived"`
+}
+
+// новый товар, пороги по умолчанию как в таблице products
+type ProductInput struct {
+	ID           string `json:"id" binding:"required,max=50"`
+	Name         string `json:"name" binding:"required,max=255"`
+	Category     string `json:"category" binding:"max=100"`
+	MinStock     *int   `json:"min_stock"`
+	OptimalStock *int   `json:"optimal_stock"`
+	LeadTimeDays *int   `json:"lead_time_days"`
+}
+
+type ProductUpdate struct {
+	Name         *string `json:"name" binding:"omitempty,max=255"`
+	Category     *string `json:"category" binding:"omitempty,max=100"`
+	MinStock     *int    `json:"min_stock"`
+	OptimalStock *int    `json:"optimal_stock"`
+	LeadTimeDays *int    `json:"lead_time_days"`
+}
+
+type LocationInput struct {
+	Zone          string `json:"zone" binding:"required,max=10"`
+	Name          string `json:"name" binding:"max=100"`
+	Rows          int    `json:"rows" binding:"required,min=1"`
+	ShelvesPerRow int    `json:"shelves_per_row" binding:"required,min=1"`
+	ShelfCapacity int    `json:"shelf_capacity" binding:"min=0"`
+}
+
+type LocationUpdate struct {
+	Name          *string `json:"name" binding:"omitempty,max=100"`
+	Rows          *int    `json:"rows" binding:"omitempty,min=1"`
+	ShelvesPerRow *int    `json:"shelves_per_row" binding:"omitempty,min=1"`
+	ShelfCapacity *int    `json:"shelf_capacity" binding:"omitempty,min=0"`
+}
+
+// ключ робота, секрет отдается только при выпуске
+type RobotKey struct {
+	RobotID   string    `json:"robot_id"`
+	KeyID     string    `json:"key_id"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// данные подписанного запроса робота для проверки
+type RobotSignedRequest struct {
+	KeyID     string
+	Timestamp string
+	Signature string
+	Method    string
+	Path      string
+	Body      []byte
+}
+
 // структура для дашборда
 type DashInfo struct {
 	ListRobots []models.Robots              `json:"robots"`
@@ -46,8 +153,12 @@ type Statistics struct {
 type InventoryAlert struct {
 	Type string `json:"type"`
 	Data struct {
+		AlertID         uint      `json:"alert_id"`
+		Severity        string    `json:"severity"`
 		ProductId       string    `json:"product_id"`
 		ProductName     string    `json:"product_name"`
+		Category        string    `json:"category,omitempty"`
+		RobotID         string    `json:"robot_id,omitempty"`
 		CurrentQuantity int       `json:"current_quantity"`
 		Zone            string    `json:"zone"`
 		Row             int       `json:"row"`
@@ -56,9 +167,28 @@ type InventoryAlert struct {
 		AlterType       string    `json:"alter_type"`
 		Timestamp       time.Time `json:"timestamp"`
 		Message         string    `json:"message"`
+		Occurrences     int       `json:"occurrences"`
+		Tier            int       `json:"tier"`
 	} `json:"data"`
 }
 
+// команда дашборда в вебсокете: subscribe, unsubscribe или list
+type StreamCommand struct {
+	Action string       `json:"action"`
+	ID     string       `json:"id"` // id подписки, без id subscribe назначает его сам, unsubscribe снимает все
+	Filter StreamFilter `json:"filter"`
+}
+
+// фильтр подписки: пустое поле не ограничивает, значения внутри поля - любое из них.
+// Событие без значения в поле (например, оповещение прогноза без зоны) под фильтр поля не попадает.
+type StreamFilter struct {
+	Types      []string `json:"types,omitempty"` // robot_update, robot_data, inventory_alert
+	Zones      []string `json:"zones,omitempty"`
+	Robots     []string `json:"robots,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Severities []string `json:"severities,omitempty"`
+}
+
 type UpdateRobot struct {
 	ID           string    `gorm:"primaryKey;type:varchar(50)" json:"id"`
 	Status       string    `gorm:"size:50;default:active" json:"status"`
@@ -71,13 +201,30 @@ type UpdateRobot struct {
 
 // для работы с ИИ
 type AIRequest struct {
-	PeriodDays int      `json:"period_days" binding:"required"`
-	Categories []string `json:"categories" binding:"required"`
+	PeriodDays   int      `json:"period_days" binding:"required"`
+	Categories   []string `json:"categories" binding:"required"`
+	LookbackDays int      `json:"lookback_days" binding:"omitempty,min=1,max=365"` // глубина истории, по умолчанию от периода прогноза
+}
+
+// окно истории для прогноза: от From до DetailFrom данные сводятся по дням,
+// после DetailFrom - по интервалам Slot
+type HistoryWindow struct {
+	From       time.Time
+	DetailFrom time.Time
+	Slot       time.Duration
 }
 
 type AIResponse struct {
-	Predictions []Predictions `json:"predictions"`
-	Confidence  float64       `json:"confidience"`
+	Predictions []Predictions       `json:"predictions"`
+	Confidence  float64             `json:"confidience"`
+	Provider    string              `json:"provider,omitempty"` // поставщик, построивший прогноз
+	Dropped     []DroppedPrediction `json:"dropped,omitempty"`  // прогнозы, не прошедшие проверку
+}
+
+// прогноз, отброшенный при проверке ответа модели
+type DroppedPrediction struct {
+	ProductID string `json:"product_id"`
+	Reason    string `json:"reason"`
 }
 
 type Predictions struct {
@@ -89,6 +236,63 @@ type Predictions struct {
 	ConfidenceScore   float64 `json:"confidence_score"`
 }
 
+// вопрос оператора об остатках
+type AskRequest struct {
+	Question string `json:"question" binding:"required,max=500"`
+}
+
+// строка данных, на которую ссылается ответ: остаток товара в зоне или сканирование
+type AskFact struct {
+	Ref          string    `json:"ref"`
+	Source       string    `json:"source"` // stock_levels или inventory_history
+	ProductID    string    `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	Category     string    `json:"category,omitempty"`
+	Zone         string    `json:"zone"`
+	Row          int       `json:"row,omitempty"`
+	Shelf        int       `json:"shelf,omitempty"`
+	Cells        int       `json:"cells,omitempty"` // сколько ячеек сведено в остаток зоны
+	Quantity     int       `json:"quantity"`
+	MinStock     int       `json:"min_stock"`
+	OptimalStock int       `json:"optimal_stock"`
+	Status       string    `json:"status"`
+	ScannedAt    time.Time `json:"scanned_at"`
+}
+
+// ответ модели: текст и ссылки на использованные строки
+type AskAnswer struct {
+	Answer string
+	Refs   []string
+}
+
+type AskResponse struct {
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	Citations []AskFact `json:"citations"`
+	Provider  string    `json:"provider"`
+}
+
+// фильтры истории прогнозов, даты в формате yyyy-mm-dd
+type PredictionQuery struct {
+	ProductID     string   `form:"product_id"`
+	Category      string   `form:"category"`
+	Provider      string   `form:"provider"`
+	From          string   `form:"from"`
+	To            string   `form:"to"`
+	MinConfidence *float64 `form:"min_confidence"`
+	Limit         int      `form:"limit"`
+	Offset        int      `form:"offset"`
+}
+
+type PredictionsResponse struct {
+	Total      int64                 `json:"total"`
+	Items      []models.AiPrediction `json:"items"`
+	Pagination struct {
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+	} `json:"pagination"`
+}
+
 // струтуры для импортов и экспортов
 type ImportResult struct {
 	SuccessCount int      `json:"success_count"`
@@ -113,3 +317,184 @@ type HistoryQuery struct {
 	Limit  int    `form:"limit"`
 	Offset int    `form:"offset"`
 }
+
+// учтенные сканирования с фильтрами из вопроса оператора, пустой список не фильтрует
+type RecentScansQuery struct {
+	Since      time.Time
+	Zones      []string
+	Categories []string
+	ProductIDs []string
+	Limit      int
+}
+
+// остаток товара в одной ячейке
+type StockLocation struct {
+	Zone      string    `json:"zone"`
+	Row       int       `json:"row"`
+	Shelf     int       `json:"shelf"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// суммарный остаток товара по складу
+type ProductStock struct {
+	ProductID   string          `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Total       int             `json:"total"`
+	Status      string          `json:"status"`
+	Locations   []StockLocation `json:"locations"`
+}
+
+type StockResponse struct {
+	Products []ProductStock `json:"products"`
+}
+
+// фильтр выбросов в истории сканирований, пустой статус - все отмеченные
+type AnomalyQuery struct {
+	Status string `form:"status"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+// фильтры списка оповещений
+type AlertQuery struct {
+	Status     string `form:"status"`
+	Severity   string `form:"severity"`
+	Type       string `form:"type"`
+	ProductID  string `form:"product_id"`
+	Zone       string `form:"zone"`
+	RobotID    string `form:"robot_id"`
+	RuleID     *uint  `form:"rule_id"`
+	AssigneeID *uint  `form:"assignee_id"`
+	Limit      int    `form:"limit"`
+	Offset     int    `form:"offset"`
+}
+
+type AlertsResponse struct {
+	Total      int64          `json:"total"`
+	Items      []models.Alert `json:"items"`
+	Pagination struct {
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+	} `json:"pagination"`
+}
+
+// подтверждение оповещения, без исполнителя назначается подтвердивший
+type AlertAcknowledge struct {
+	AssigneeID *uint `json:"assignee_id"`
+}
+
+type AlertAssign struct {
+	AssigneeID uint `json:"assignee_id" binding:"required"`
+}
+
+type AlertResolve struct {
+	Note string `json:"note"`
+}
+
+// новое правило оповещений, пустые фильтры - без ограничений
+type AlertRuleInput struct {
+	Name            string  `json:"name" binding:"required,max=255"`
+	Kind            string  `json:"kind" binding:"required"`
+	Threshold       float64 `json:"threshold"`
+	Severity        string  `json:"severity" binding:"required"`
+	Category        string  `json:"category" binding:"max=100"`
+	ProductID       string  `json:"product_id" binding:"max=50"`
+	Zone            string  `json:"zone" binding:"max=10"`
+	CooldownSeconds int     `json:"cooldown_seconds"`
+	DedupKey        string  `json:"dedup_key" binding:"max=255"`
+	Enabled         *bool   `json:"enabled"` // по умолчанию включено
+}
+
+type AlertRuleUpdate struct {
+	Name            *string  `json:"name" binding:"omitempty,max=255"`
+	Threshold       *float64 `json:"threshold"`
+	Severity        *string  `json:"severity"`
+	Category        *string  `json:"category" binding:"omitempty,max=100"`
+	ProductID       *string  `json:"product_id" binding:"omitempty,max=50"`
+	Zone            *string  `json:"zone" binding:"omitempty,max=10"`
+	CooldownSeconds *int     `json:"cooldown_seconds"`
+	DedupKey        *string  `json:"dedup_key" binding:"omitempty,max=255"`
+	Enabled         *bool    `json:"enabled"`
+}
+
+// новый канал уведомлений: target - url вебхука, адреса через запятую или chat_id бота
+type NotificationChannelInput struct {
+	Name       string `json:"name" binding:"required,max=255"`
+	Kind       string `json:"kind" binding:"required"`
+	Target     string `json:"target" binding:"required"`
+	Secret     string `json:"secret" binding:"max=255"`    // ключ подписи, обязателен для вебхука
+	Severities string `json:"severities" binding:"max=50"` // через запятую, пусто - любая
+	Zones      string `json:"zones" binding:"max=255"`     // через запятую, пусто - любая
+	Tier       int    `json:"tier"`                        // 1 по умолчанию, старшие уровни получают эскалации
+	Enabled    *bool  `json:"enabled"`                     // по умолчанию включен
+}
+
+type NotificationChannelUpdate struct {
+	Name       *string `json:"name" binding:"omitempty,max=255"`
+	Target     *string `json:"target"`
+	Secret     *string `json:"secret" binding:"omitempty,max=255"`
+	Severities *string `json:"severities" binding:"omitempty,max=50"`
+	Zones      *string `json:"zones" binding:"omitempty,max=255"`
+	Tier       *int    `json:"tier"`
+	Enabled    *bool   `json:"enabled"`
+}
+
+// результат проверочной отправки в канал
+type ChannelTestResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// фильтры журнала доставки уведомлений
+type DeliveryQuery struct {
+	Status    string `form:"status"`
+	ChannelID *uint  `form:"channel_id"`
+	AlertID   *uint  `form:"alert_id"`
+	Limit     int    `form:"limit"`
+	Offset    int    `form:"offset"`
+}
+
+type DeliveriesResponse struct {
+	Total      int64                         `json:"total"`
+	Items      []models.NotificationDelivery `json:"items"`
+	Pagination struct {
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+	} `json:"pagination"`
+}
+
+type StockQuery struct {
+	ProductID string `form:"product_id"`
+	Zone      string `form:"zone"`
+}
+
+// параметры отчета о точности прогнозов, даты в формате yyyy-mm-dd
+type AccuracyQuery struct {
+	From      string `form:"from"`
+	To        string `form:"to"`
+	Tolerance *int   `form:"tolerance"` // допустимая ошибка в днях для попадания
+}
+
+// метрики точности группы прогнозов, ошибка = прогноз - факт в днях
+type AccuracyMetrics struct {
+	Key       string  `json:"key"`
+	Name      string  `json:"name,omitempty"`
+	Evaluated int     `json:"evaluated"`
+	Observed  int     `json:"observed"` // прогнозы, после которых нехватка действительно наступила
+	Censored  int     `json:"censored"` // нехватки не было до конца окна ожидания, в ошибки не входят
+	MAE       float64 `json:"mae_days"`
+	Bias      float64 `json:"bias_days"` // > 0 - нехватка наступает раньше прогноза
+	HitRate   float64 `json:"hit_rate"`  // доля наблюдавшихся нехваток в пределах допуска
+}
+
+type AccuracyReport struct {
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	ToleranceDays int               `json:"tolerance_days"`
+	Overall       AccuracyMetrics   `json:"overall"`
+	Providers     []AccuracyMetrics `json:"providers"`
+	Categories    []AccuracyMetrics `json:"categories"`
+	Products      []AccuracyMetrics `json:"products"`
+}
@@ -0,0 +1,12 @@
+package entities
+
+import "errors"
+
+// общие ошибки, по которым хэндлеры выбирают http статус
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrValidation   = errors.New("validation failed")
+	ErrConflict     = errors.New("conflict")
+	ErrNotSupported = errors.New("not supported")
+	ErrUnauthorized = errors.New("unauthorized")
+)
@@ -0,0 +1,144 @@
+package fakegigachat
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxStockoutDays = 90
+	citedFacts      = 3
+)
+
+// строка истории из промпта прогноза
+type historyRow struct {
+	ProductID string    `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	ScannedAt time.Time `json:"scanned_at"`
+	Product   struct {
+		Name         string `json:"name"`
+		OptimalStock int    `json:"optimal_stock"`
+	} `json:"product"`
+}
+
+// строка данных из промпта вопроса
+type fact struct {
+	Ref         string `json:"ref"`
+	ProductName string `json:"product_name"`
+	Zone        string `json:"zone"`
+	Quantity    int    `json:"quantity"`
+	Status      string `json:"status"`
+}
+
+// ответ по данным из первого сообщения пользователя: на исправление модель
+// отвечает тем же, потому что сгенерированный ответ всегда корректен
+func generate(messages []Message) string {
+	prompt := ""
+	for _, m := range messages {
+		if m.Role == "user" {
+			prompt = m.Content
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(prompt, `"predictions"`):
+		var rows []historyRow
+		decodeFirstArray(prompt, &rows)
+		return forecast(rows)
+	case strings.Contains(prompt, `"refs"`):
+		var facts []fact
+		decodeFirstArray(prompt, &facts)
+		return answer(facts)
+	default:
+		return "Тестовый ответ GigaChat"
+	}
+}
+
+// первый json массив в тексте, остальной текст промпта пропускается
+func decodeFirstArray(text string, target interface{}) {
+	for i, r := range text {
+		if r != '[' {
+			continue
+		}
+		var raw json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(text[i:])).Decode(&raw); err != nil {
+			continue
+		}
+		if json.Unmarshal(raw, target) == nil {
+			return
+		}
+	}
+}
+
+// прогноз по линейному расходу между первым и последним сканированием товара
+func forecast(rows []historyRow) string {
+	series := make(map[string][]historyRow)
+	var order []string
+	for _, row := range rows {
+		if _, ok := series[row.ProductID]; !ok {
+			order = append(order, row.ProductID)
+		}
+		series[row.ProductID] = append(series[row.ProductID], row)
+	}
+
+	type prediction struct {
+		ProductID         string  `json:"product_id"`
+		ProductName       string  `json:"product_name"`
+		PredictionDate    string  `json:"prediction_date"`
+		DaysUntilStockout int     `json:"days_until_stockout"`
+		RecommendedOrder  int     `json:"recommended_order"`
+		ConfidenceScore   float64 `json:"confidence_score"`
+	}
+	predictions := make([]prediction, 0, len(order))
+	today := time.Now().Format("02.01.2006")
+	for _, productID := range order {
+		points := series[productID]
+		sort.Slice(points, func(i, j int) bool { return points[i].ScannedAt.Before(points[j].ScannedAt) })
+		first, last := points[0], points[len(points)-1]
+
+		days, confidence := maxStockoutDays, 0.3
+		if elapsed := last.ScannedAt.Sub(first.ScannedAt).Hours() / 24; elapsed > 0 {
+			confidence = 0.6
+			if rate := float64(first.Quantity-last.Quantity) / elapsed; rate > 0 {
+				days = int(math.Min(math.Ceil(float64(last.Quantity)/rate), maxStockoutDays))
+			}
+		}
+
+		predictions = append(predictions, prediction{
+			ProductID:         productID,
+			ProductName:       last.Product.Name,
+			PredictionDate:    today,
+			DaysUntilStockout: days,
+			RecommendedOrder:  max(last.Product.OptimalStock-last.Quantity, 0),
+			ConfidenceScore:   confidence,
+		})
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"predictions": predictions, "confidence": 0.5})
+	return string(body)
+}
+
+// ответ с перечислением первых строк данных и ссылками на них
+func answer(facts []fact) string {
+	refs := []string{}
+	parts := []string{}
+	for _, f := range facts {
+		if len(refs) == citedFacts {
+			break
+		}
+		refs = append(refs, f.Ref)
+		parts = append(parts, fmt.Sprintf("%s в зоне %s: %d шт. (%s)", f.ProductName, f.Zone, f.Quantity, f.Status))
+	}
+
+	text := "Данных для ответа недостаточно."
+	if len(parts) > 0 {
+		text = "По данным склада: " + strings.Join(parts, "; ") + "."
+	}
+	body, _ := json.Marshal(map[string]interface{}{"answer": text, "refs": refs})
+	return string(body)
+}
@@ -0,0 +1,242 @@
+// Package fakegigachat - локальная замена GigaChat для тестов и разработки.
+// Реализует выдачу токена и chat completions в том виде, в каком их вызывает gigago.
+// Ответы берутся из сценария, затем из правил, иначе строятся по данным из промпта.
+package fakegigachat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// пути совпадают с API Сбера, бэкенд строит по ним адреса от GIGACHAT_BASE_URL
+const (
+	OAuthPath = "/api/v2/oauth"
+	ChatPath  = "/api/v1/chat/completions"
+
+	tokenTTL = 30 * time.Minute
+)
+
+// ответ модели или ошибка с кодом статуса
+type Reply struct {
+	Status  int    `json:"status,omitempty"` // по умолчанию 200
+	Content string `json:"content"`
+}
+
+// ответ на сообщения, подходящие под регулярное выражение
+type Rule struct {
+	Match string `json:"match"` // по последнему сообщению пользователя
+	Reply
+}
+
+type Config struct {
+	Key    string  `json:"key"`    // ожидаемый ключ авторизации, пусто - любой
+	Script []Reply `json:"script"` // ответы по порядку, после них - правила
+	Rules  []Rule  `json:"rules"`
+}
+
+// конфигурация из json файла
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// принятый запрос к chat completions
+type Request struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+}
+
+type rule struct {
+	match *regexp.Regexp
+	reply Reply
+}
+
+type Server struct {
+	key   string
+	rules []rule
+
+	mu       sync.Mutex
+	script   []Reply
+	tokens   map[string]time.Time
+	requests []Request
+	mux      *http.ServeMux
+}
+
+func NewServer(cfg Config) (*Server, error) {
+	s := &Server{
+		key:    cfg.Key,
+		script: append([]Reply(nil), cfg.Script...),
+		tokens: make(map[string]time.Time),
+		mux:    http.NewServeMux(),
+	}
+	for _, r := range cfg.Rules {
+		match, err := regexp.Compile(r.Match)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Match, err)
+		}
+		s.rules = append(s.rules, rule{match: match, reply: r.Reply})
+	}
+
+	s.mux.HandleFunc(OAuthPath, s.oauth)
+	s.mux.HandleFunc(ChatPath, s.chat)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// принятые запросы к модели в порядке поступления
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) oauth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Basic ")
+	if !ok || key == "" || (s.key != "" && key != s.key) {
+		writeError(w, http.StatusUnauthorized, "invalid authorization key")
+		return
+	}
+	if r.Header.Get("RqUID") == "" {
+		writeError(w, http.StatusBadRequest, "RqUID header is required")
+		return
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	expiresAt := time.Now().Add(tokenTTL)
+
+	s.mu.Lock()
+	s.tokens[token] = expiresAt
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"expires_at":   expiresAt.UnixMilli(),
+	})
+}
+
+func (s *Server) chat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !s.validToken(token) {
+		// gigago на 401 обновляет токен и повторяет запрос
+		writeError(w, http.StatusUnauthorized, "token expired or invalid")
+		return
+	}
+
+	var request Request
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+	if len(request.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "messages are required")
+		return
+	}
+
+	reply := s.reply(request)
+	if reply.Status != 0 && reply.Status != http.StatusOK {
+		writeError(w, reply.Status, reply.Content)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"choices": []map[string]interface{}{{
+			"message":       map[string]string{"role": "assistant", "content": reply.Content},
+			"index":         0,
+			"finish_reason": "stop",
+		}},
+		"created": time.Now().Unix(),
+		"model":   request.Model,
+		"object":  "chat.completion",
+	})
+}
+
+func (s *Server) validToken(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt, ok := s.tokens[token]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// ответ по сценарию, затем по правилам, иначе сгенерированный по промпту
+func (s *Server) reply(request Request) Reply {
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	if len(s.script) > 0 {
+		reply := s.script[0]
+		s.script = s.script[1:]
+		s.mu.Unlock()
+		return reply
+	}
+	s.mu.Unlock()
+
+	last := lastUserMessage(request.Messages)
+	for _, r := range s.rules {
+		if r.match.MatchString(last) {
+			return r.reply
+		}
+	}
+	return Reply{Content: generate(request.Messages)}
+}
+
+func lastUserMessage(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "fake_" + hex.EncodeToString(b), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// ошибки в формате API Сбера
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"status": status, "message": message})
+}
@@ -0,0 +1,22 @@
+package models
+
+// оповещение еще ждет решения
+func (a Alert) Active() bool {
+	return a.Status == AlertOpen || a.Status == AlertAcknowledged
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// важность a выше b
+func MoreSevere(a, b string) bool {
+	return severityRank(a) > severityRank(b)
+}
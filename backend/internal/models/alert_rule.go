@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ключи по умолчанию: одна ячейка, товар, робот или зона - одно активное оповещение,
+// правила разной важности с одним ключом повышают важность общего оповещения
+var defaultDedupKeys = map[string]string{
+	RuleCellBelowMin:           "cell:{product}:{zone}:{row}:{shelf}",
+	RuleCellBelowOptimal:       "cell:{product}:{zone}:{row}:{shelf}",
+	RuleProductBelowOptimal:    "stock:{product}",
+	RuleRobotBattery:           "robot:{robot}",
+	RuleZoneNotScanned:         "zone:{zone}",
+	RuleForecastStockout:       "forecast:{product}",
+	RuleForecastBeforeLeadTime: "forecast:{product}",
+}
+
+// подстановки в шаблоне ключа
+var DedupPlaceholders = []string{"{rule}", "{product}", "{category}", "{zone}", "{row}", "{shelf}", "{robot}"}
+
+// состояние, по которому проверяются правила: ячейка, товар, робот, зона или прогноз
+type RuleSubject struct {
+	Type              string // тип оповещения, которое может открыть проверка
+	Product           Products
+	Zone              string
+	RowNumber         int
+	ShelfNumber       int
+	RobotID           string
+	Quantity          int
+	BatteryLevel      int
+	LastScanAt        time.Time // последнее сканирование зоны
+	DaysUntilStockout int
+	RecommendedOrder  int
+	ConfidenceScore   float64
+	At                time.Time // время данных: сканирования, отчета робота или прогноза; пусто, если данных нет
+	CheckedAt         time.Time // время проверки, от него считается давность сканирования зоны
+}
+
+// вид правила существует
+func KnownRuleKind(kind string) bool {
+	_, ok := defaultDedupKeys[kind]
+	return ok
+}
+
+// тип оповещения, которое открывает правило
+func RuleAlertType(kind string) string {
+	switch kind {
+	case RuleCellBelowMin, RuleCellBelowOptimal:
+		return AlertTypeScanned
+	case RuleProductBelowOptimal:
+		return AlertTypeStock
+	case RuleRobotBattery:
+		return AlertTypeRobot
+	case RuleZoneNotScanned:
+		return AlertTypeZone
+	case RuleForecastStockout, RuleForecastBeforeLeadTime:
+		return AlertTypePredicted
+	default:
+		return ""
+	}
+}
+
+func (r AlertRule) AlertType() string {
+	return RuleAlertType(r.Kind)
+}
+
+func (r AlertRule) Cooldown() time.Duration {
+	return time.Duration(r.CooldownSeconds) * time.Second
+}
+
+// правило относится к состоянию: тот же тип и подходят фильтры
+func (r AlertRule) Applies(s RuleSubject) bool {
+	if !r.Enabled || r.AlertType() != s.Type {
+		return false
+	}
+	if r.Category != "" && r.Category != s.Product.Category {
+		return false
+	}
+	if r.ProductID != "" && r.ProductID != s.Product.ID {
+		return false
+	}
+	if r.Zone != "" && r.Zone != s.Zone {
+		return false
+	}
+	return true
+}
+
+// условие правила выполняется
+func (r AlertRule) Fires(s RuleSubject) bool {
+	if !r.Applies(s) {
+		return false
+	}
+	switch r.Kind {
+	case RuleCellBelowMin:
+		return s.Quantity <= s.Product.MinStock
+	case RuleCellBelowOptimal, RuleProductBelowOptimal:
+		return float64(s.Quantity) <= float64(s.Product.OptimalStock)*r.Threshold/100
+	case RuleRobotBattery:
+		return float64(s.BatteryLevel) < r.Threshold
+	case RuleZoneNotScanned:
+		return s.CheckedAt.Sub(s.LastScanAt).Hours() >= r.Threshold
+	case RuleForecastStockout:
+		return float64(s.DaysUntilStockout) <= r.Threshold
+	case RuleForecastBeforeLeadTime:
+		return float64(s.DaysUntilStockout) < float64(s.Product.LeadTimeDays)+r.Threshold
+	default:
+		return false
+	}
+}
+
+// ключ дедупликации по шаблону правила
+func (r AlertRule) Key(s RuleSubject) string {
+	template := r.DedupKey
+	if template == "" {
+		template = defaultDedupKeys[r.Kind]
+	}
+	return strings.NewReplacer(
+		"{rule}", strconv.FormatUint(uint64(r.ID), 10),
+		"{product}", s.Product.ID,
+		"{category}", s.Product.Category,
+		"{zone}", s.Zone,
+		"{row}", strconv.Itoa(s.RowNumber),
+		"{shelf}", strconv.Itoa(s.ShelfNumber),
+		"{robot}", s.RobotID,
+	).Replace(template)
+}
+
+// текст оповещения для дашборда
+func (r AlertRule) Message(s RuleSubject) string {
+	switch r.Kind {
+	case RuleCellBelowMin, RuleCellBelowOptimal:
+		return fmt.Sprintf("%s остаток! Требуется пополнение. %s: %d шт. в ячейке %s-%d-%d",
+			s.Product.StockStatus(s.Quantity), s.Product.Name, s.Quantity, s.Zone, s.RowNumber, s.ShelfNumber)
+	case RuleProductBelowOptimal:
+		return fmt.Sprintf("%s: на складе %d шт. при оптимальном запасе %d", s.Product.Name, s.Quantity, s.Product.OptimalStock)
+	case RuleRobotBattery:
+		return fmt.Sprintf("Низкий заряд робота %s: %d%%", s.RobotID, s.BatteryLevel)
+	case RuleZoneNotScanned:
+		return fmt.Sprintf("Зона %s не сканировалась %.0f ч.", s.Zone, s.CheckedAt.Sub(s.LastScanAt).Hours())
+	case RuleForecastStockout, RuleForecastBeforeLeadTime:
+		return fmt.Sprintf("Товар закончится через %d дней (срок поставки %d дней). Рекомендуемый заказ: %d единиц. Уверенность прогноза: %.1f%%",
+			s.DaysUntilStockout, s.Product.LeadTimeDays, s.RecommendedOrder, s.ConfidenceScore*100)
+	default:
+		return r.Name
+	}
+}
+
+// новое оповещение по сработавшему правилу
+func (r AlertRule) NewAlert(s RuleSubject) Alert {
+	id := r.ID
+	now := time.Now()
+	scannedAt := s.At
+	if scannedAt.IsZero() { // товар без остатков ни в одной ячейке
+		scannedAt = now
+	}
+	return Alert{
+		Type:        r.AlertType(),
+		Severity:    r.Severity,
+		Status:      AlertOpen,
+		RuleID:      &id,
+		DedupKey:    r.Key(s),
+		ProductID:   s.Product.ID,
+		ProductName: s.Product.Name,
+		Category:    s.Product.Category,
+		RobotID:     s.RobotID,
+		Zone:        s.Zone,
+		RowNumber:   s.RowNumber,
+		ShelfNumber: s.ShelfNumber,
+		Quantity:    s.Quantity,
+		Message:     r.Message(s),
+		ScannedAt:   scannedAt,
+		Occurrences: 1,
+		Tier:        1,
+		TierSince:   now,
+	}
+}
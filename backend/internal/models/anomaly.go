@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// поиск выбросов в сканированиях ячейки по робастному z-score:
+// отклонение от медианы недавних сканирований в единицах MAD
+type AnomalyDetector struct {
+	Window     time.Duration // недавние сканирования ячейки, с которыми сравнивается новое
+	MaxSamples int
+	MinSamples int     // при меньшей истории выбросы не ищутся
+	Threshold  float64 // 0 - поиск выключен
+	MinChange  int     // скачок меньше этого не выброс даже при ровной истории
+}
+
+func DefaultAnomalyDetector() AnomalyDetector {
+	return AnomalyDetector{
+		Window:     24 * time.Hour,
+		MaxSamples: 20,
+		MinSamples: 3,
+		Threshold:  5,
+		MinChange:  10,
+	}
+}
+
+func (d AnomalyDetector) Enabled() bool {
+	return d.Threshold > 0
+}
+
+// оценка нового количества по недавним сканированиям ячейки,
+// возвращает z-score и причину, если это выброс
+func (d AnomalyDetector) Check(recent []int, quantity int) (float64, string, bool) {
+	if !d.Enabled() || len(recent) == 0 || len(recent) < d.MinSamples {
+		return 0, "", false
+	}
+
+	median := medianOf(recent)
+	deviations := make([]int, len(recent))
+	for i, q := range recent {
+		deviations[i] = int(math.Abs(float64(q) - median))
+	}
+	// без нижней границы ровная история делает выбросом любое движение
+	scale := math.Max(1.4826*medianOf(deviations), math.Max(1, 0.05*median))
+
+	change := math.Abs(float64(quantity) - median)
+	z := math.Round(change/scale*100) / 100
+	if z < d.Threshold || change < float64(d.MinChange) {
+		return z, "", false
+	}
+	return z, fmt.Sprintf("quantity %d deviates from median %.0f of %d recent scans (z=%.1f)", quantity, median, len(recent), z), true
+}
+
+// новое сканирование подтверждает ранее отмеченное: остаток действительно сменился
+func (d AnomalyDetector) Agrees(flagged, quantity int) bool {
+	diff := flagged - quantity
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff < d.MinChange
+}
+
+func medianOf(values []int) float64 {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return float64(sorted[n/2])
+	}
+	return float64(sorted[n/2-1]+sorted[n/2]) / 2
+}
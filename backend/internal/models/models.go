@@ -4,6 +4,79 @@ import (
 	"time"
 )
 
+// роли пользователей, совпадают с CHECK в таблице users
+const (
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+	RoleViewer   = "viewer"
+)
+
+// статусы роботов в реестре
+const (
+	RobotStatusActive         = "active"
+	RobotStatusMaintenance    = "maintenance"
+	RobotStatusOffline        = "offline"
+	RobotStatusDecommissioned = "decommissioned"
+)
+
+// служебный робот, от имени которого пишутся строки импорта CSV; заведен в сид-миграции
+const ImportRobotID = "IMPORT_SERVICE"
+
+// статусы остатка товара
+const (
+	StockOK       = "OK"
+	StockLow      = "LOW_STOCK"
+	StockCritical = "CRITICAL"
+)
+
+// статусы выбросов в истории сканирований, пустой - обычное сканирование
+const (
+	AnomalyPending   = "pending"
+	AnomalyConfirmed = "confirmed"
+	AnomalyRejected  = "rejected"
+)
+
+// жизненный цикл оповещения
+const (
+	AlertOpen         = "open"
+	AlertAcknowledged = "acknowledged"
+	AlertResolved     = "resolved"
+)
+
+// источники и важность оповещений
+const (
+	AlertTypeScanned   = "scanned" // ячейка по сканированию
+	AlertTypeStock     = "stock"   // суммарный остаток товара
+	AlertTypeRobot     = "robot"
+	AlertTypeZone      = "zone"
+	AlertTypePredicted = "predicted"
+
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+)
+
+// виды правил оповещений, единицы порога зависят от вида
+const (
+	RuleCellBelowMin           = "cell_below_min"            // остаток в ячейке не выше min_stock, порог не используется
+	RuleCellBelowOptimal       = "cell_below_optimal"        // остаток в ячейке не выше порога в % от optimal_stock
+	RuleProductBelowOptimal    = "product_below_optimal"     // суммарный остаток товара не выше порога в % от optimal_stock
+	RuleRobotBattery           = "robot_battery_below"       // заряд робота ниже порога в %
+	RuleZoneNotScanned         = "zone_not_scanned"          // зону не сканировали порог часов
+	RuleForecastStockout       = "forecast_stockout"         // по прогнозу товар закончится не позже чем через порог дней
+	RuleForecastBeforeLeadTime = "forecast_before_lead_time" // товар закончится раньше срока поставки плюс порог дней
+)
+
+// каналы уведомлений и статусы доставки
+const (
+	ChannelWebhook  = "webhook"
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
 // структыры таблиц бд для сопоставления с gorm
 
 type Users struct {
@@ -16,11 +89,13 @@ type Users struct {
 }
 
 type Products struct {
-	ID           string `gorm:"primaryKey;type:varchar(50)" json:"id"`
-	Name         string `gorm:"type:varchar(255);not null" json:"name"`
-	Category     string `gorm:"type:varchar(100)" json:"category"`
-	MinStock     int    `gorm:"default:10" json:"min_stock"`
-	OptimalStock int    `gorm:"default:100" json:"optimal_stock"`
+	ID           string     `gorm:"primaryKey;type:varchar(50)" json:"id"`
+	Name         string     `gorm:"type:varchar(255);not null" json:"name"`
+	Category     string     `gorm:"type:varchar(100)" json:"category"`
+	MinStock     int        `gorm:"default:10" json:"min_stock"`
+	OptimalStock int        `gorm:"default:100" json:"optimal_stock"`
+	LeadTimeDays int        `gorm:"default:7" json:"lead_time_days"`               // срок поставки после заказа
+	ArchivedAt   *time.Time `gorm:"type:timestamptz" json:"archived_at,omitempty"` // архивный товар скрыт из каталога, история сохраняется
 }
 
 type Robots struct {
@@ -34,16 +109,25 @@ type Robots struct {
 }
 
 type InventoryHistory struct {
-	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	RobotID     string    `gorm:"type:varchar(50);not null" json:"robot_id"`
-	ProductID   string    `gorm:"type:varchar(50);not null" json:"product_id"`
-	Quantity    int       `gorm:"type:integer;not null" json:"quantity"`
-	Zone        string    `gorm:"size:10;not null" json:"zone"`
-	RowNumber   int       `gorm:"type:integer" json:"row_number"`
-	ShelfNumber int       `gorm:"type:integer" json:"shelf_number"`
-	Status      string    `gorm:"size:50" json:"status"`
-	ScannedAt   time.Time `gorm:"type:timestamptz;not null" json:"scanned_at"`
-	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RobotID        string    `gorm:"type:varchar(50);not null" json:"robot_id"`
+	ProductID      string    `gorm:"type:varchar(50);not null" json:"product_id"`
+	Quantity       int       `gorm:"type:integer;not null" json:"quantity"`
+	Zone           string    `gorm:"size:10;not null" json:"zone"`
+	RowNumber      int       `gorm:"type:integer" json:"row_number"`
+	ShelfNumber    int       `gorm:"type:integer" json:"shelf_number"`
+	Status         string    `gorm:"size:50" json:"status"`                    // вычисляется сервером по порогам товара
+	ReportedStatus string    `gorm:"size:50" json:"reported_status,omitempty"` // статус, присланный роботом
+	ScannedAt      time.Time `gorm:"type:timestamptz;not null" json:"scanned_at"`
+	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	MessageID      *string   `gorm:"type:varchar(64)" json:"message_id,omitempty"`
+
+	// выброс относительно недавних сканирований ячейки, до подтверждения не учитывается в остатках и прогнозах
+	AnomalyStatus     string     `gorm:"size:20;not null;default:''" json:"anomaly_status,omitempty"`
+	AnomalyScore      *float64   `json:"anomaly_score,omitempty"`
+	AnomalyReason     string     `gorm:"size:255" json:"anomaly_reason,omitempty"`
+	AnomalyResolvedAt *time.Time `gorm:"type:timestamptz" json:"anomaly_resolved_at,omitempty"`
+	AnomalyResolvedBy *uint      `json:"anomaly_resolved_by,omitempty"`
 
 	// Связи
 	Robot   Robots   `gorm:"foreignKey:RobotID;references:ID" json:"robot"`
@@ -51,17 +135,173 @@ type InventoryHistory struct {
 }
 
 type AiPrediction struct {
-	ID                uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	ProductID         string    `gorm:"type:varchar(50);not null" json:"-"`
-	PredictionDate    time.Time `gorm:"type:date;not null" json:"prediction_date"`
-	DaysUntilStockout int       `gorm:"not null" json:"days_until_stockout"`
-	RecommendedOrder  int       `gorm:"not null" json:"recommended_order"`
-	ConfidenceScore   float64   `gorm:"type:decimal(3,2)" json:"confidence_score"`
-	CreatedAt         time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	ID                uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductID         string     `gorm:"type:varchar(50);not null" json:"-"`
+	PredictionDate    time.Time  `gorm:"type:date;not null" json:"prediction_date"`
+	DaysUntilStockout int        `gorm:"not null" json:"days_until_stockout"`
+	RecommendedOrder  int        `gorm:"not null" json:"recommended_order"`
+	ConfidenceScore   float64    `gorm:"type:decimal(3,2)" json:"confidence_score"`
+	Provider          string     `gorm:"size:50" json:"provider"`
+	CreatedAt         time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	ActualStockoutAt  *time.Time `gorm:"type:timestamptz" json:"actual_stockout_at,omitempty"` // первое сканирование с остатком не выше min_stock
+	ActualDays        *int       `json:"actual_days,omitempty"`                                // фактические дни до нехватки, пусто - нехватки не было
+	EvaluatedAt       *time.Time `gorm:"type:timestamptz" json:"evaluated_at,omitempty"`
 
 	AIPredictionProduct Products `gorm:"foreignKey:ProductID;references:ID;" json:"product"`
 }
 
+// ключ робота, секрет хранится зашифрованным ключом сервера
+type RobotCredential struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	RobotID         string     `gorm:"type:varchar(50);not null" json:"robot_id"`
+	KeyID           string     `gorm:"type:varchar(64);unique;not null" json:"key_id"`
+	SecretEncrypted string     `gorm:"type:text;not null" json:"-"` // секрет, зашифрованный ROBOT_SECRET_KEY
+	CreatedAt       time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	RevokedAt       *time.Time `gorm:"type:timestamptz" json:"revoked_at"`
+}
+
+// принятое сообщение робота для защиты от повторной обработки
+type RobotMessage struct {
+	ID              string    `gorm:"primaryKey;type:varchar(64)" json:"id"`
+	RobotID         string    `gorm:"type:varchar(50);not null" json:"robot_id"`
+	ClientMessageID string    `gorm:"type:varchar(255);not null" json:"client_message_id"`
+	ReceivedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"received_at"`
+}
+
+// текущий остаток товара в ячейке склада, обновляется при каждом сканировании и импорте
+type StockLevel struct {
+	ProductID   string    `gorm:"primaryKey;type:varchar(50)" json:"product_id"`
+	Zone        string    `gorm:"primaryKey;size:10" json:"zone"`
+	RowNumber   int       `gorm:"primaryKey" json:"row_number"`
+	ShelfNumber int       `gorm:"primaryKey" json:"shelf_number"`
+	Quantity    int       `gorm:"not null" json:"quantity"`
+	Status      string    `gorm:"size:50" json:"status"`
+	ScannedAt   time.Time `gorm:"type:timestamptz;not null" json:"scanned_at"`
+	UpdatedAt   time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+
+	Product Products `gorm:"foreignKey:ProductID;references:ID" json:"-"`
+}
+
+// зона склада: ряды, полки в ряду и вместимость полки
+type Location struct {
+	Zone          string    `gorm:"primaryKey;size:10" json:"zone"`
+	Name          string    `gorm:"size:100" json:"name"`
+	Rows          int       `gorm:"not null" json:"rows"`
+	ShelvesPerRow int       `gorm:"not null" json:"shelves_per_row"`
+	ShelfCapacity int       `gorm:"not null;default:0" json:"shelf_capacity"` // 0 - без ограничения
+	CreatedAt     time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// оповещение, открытое правилом: по ячейке, товару, роботу, зоне или прогнозу
+type Alert struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Type           string     `gorm:"size:20;not null" json:"type"`
+	Severity       string     `gorm:"size:20;not null" json:"severity"`
+	Status         string     `gorm:"size:20;not null;default:open" json:"status"`
+	RuleID         *uint      `json:"rule_id,omitempty"` // пусто, если правило удалено
+	DedupKey       string     `gorm:"size:255;not null" json:"dedup_key"`
+	ProductID      string     `gorm:"type:varchar(50);not null;default:''" json:"product_id"` // пусто у робота и зоны
+	ProductName    string     `gorm:"size:255" json:"product_name"`
+	Category       string     `gorm:"size:100;not null;default:''" json:"category,omitempty"` // категория товара на момент открытия
+	RobotID        string     `gorm:"type:varchar(50);not null;default:''" json:"robot_id,omitempty"`
+	Zone           string     `gorm:"size:10" json:"zone"` // пусто у прогноза
+	RowNumber      int        `json:"row_number"`
+	ShelfNumber    int        `json:"shelf_number"`
+	Quantity       int        `json:"quantity"`
+	Message        string     `gorm:"type:text" json:"message"`
+	ScannedAt      time.Time  `gorm:"type:timestamptz;not null" json:"scanned_at"` // сканирование или прогноз, обновившие оповещение
+	Occurrences    int        `gorm:"not null;default:1" json:"occurrences"`       // сколько раз условие наблюдалось
+	Tier           int        `gorm:"not null;default:1" json:"tier"`              // уровень уведомлений
+	TierSince      time.Time  `gorm:"type:timestamptz;not null;default:now()" json:"tier_since"`
+	AssigneeID     *uint      `json:"assignee_id,omitempty"`
+	AcknowledgedAt *time.Time `gorm:"type:timestamptz" json:"acknowledged_at,omitempty"`
+	AcknowledgedBy *uint      `json:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time `gorm:"type:timestamptz" json:"resolved_at,omitempty"`
+	ResolvedBy     *uint      `json:"resolved_by,omitempty"` // пусто - закрыто автоматически
+	ResolutionNote string     `gorm:"type:text" json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// условие оповещения, управляется через API
+type AlertRule struct {
+	ID              uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name            string    `gorm:"size:255;not null" json:"name"`
+	Kind            string    `gorm:"size:50;not null" json:"kind"`
+	Threshold       float64   `gorm:"not null;default:0" json:"threshold"`
+	Severity        string    `gorm:"size:20;not null" json:"severity"`
+	Category        string    `gorm:"size:100;not null;default:''" json:"category,omitempty"` // пусто - любая
+	ProductID       string    `gorm:"type:varchar(50);not null;default:''" json:"product_id,omitempty"`
+	Zone            string    `gorm:"size:10;not null;default:''" json:"zone,omitempty"`
+	CooldownSeconds int       `gorm:"not null;default:0" json:"cooldown_seconds"`
+	DedupKey        string    `gorm:"size:255;not null;default:''" json:"dedup_key"` // шаблон, пусто - по виду правила
+	Enabled         bool      `gorm:"not null" json:"enabled"`                       // без default в теге, иначе gorm не запишет false
+	CreatedAt       time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// внешний канал уведомлений об оповещениях
+type NotificationChannel struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name       string    `gorm:"size:255;not null" json:"name"`
+	Kind       string    `gorm:"size:20;not null" json:"kind"`
+	Target     string    `gorm:"type:text;not null" json:"target"` // url вебхука, адреса через запятую или chat_id бота
+	Secret     string    `gorm:"size:255;not null;default:''" json:"-"`
+	Severities string    `gorm:"size:50;not null;default:''" json:"severities"` // через запятую, пусто - любая
+	Zones      string    `gorm:"size:255;not null;default:''" json:"zones"`     // через запятую, пусто - любая
+	Tier       int       `gorm:"not null;default:1" json:"tier"`                // 1 - новые оповещения, старше - эскалации
+	Enabled    bool      `gorm:"not null" json:"enabled"`
+	CreatedAt  time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// доставка оповещения в канал, запись журнала и очереди повторов
+type NotificationDelivery struct {
+	ID            uint64               `gorm:"primaryKey;autoIncrement" json:"id"`
+	AlertID       uint                 `gorm:"not null" json:"alert_id"`
+	ChannelID     *uint                `json:"channel_id"` // пусто, если канал удален
+	ChannelKind   string               `gorm:"size:20;not null" json:"channel_kind"`
+	Severity      string               `gorm:"size:20;not null" json:"severity"`
+	Tier          int                  `gorm:"not null;default:1" json:"tier"`
+	Status        string               `gorm:"size:20;not null;default:pending" json:"status"`
+	Attempts      int                  `gorm:"not null;default:0" json:"attempts"`
+	LastError     string               `gorm:"type:text;not null;default:''" json:"last_error,omitempty"`
+	NextAttemptAt time.Time            `gorm:"type:timestamptz;not null" json:"next_attempt_at"`
+	SentAt        *time.Time           `gorm:"type:timestamptz" json:"sent_at,omitempty"`
+	CreatedAt     time.Time            `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt     time.Time            `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+	Alert         *Alert               `gorm:"foreignKey:AlertID" json:"alert,omitempty"`
+	Channel       *NotificationChannel `gorm:"foreignKey:ChannelID" json:"-"`
+}
+
+// ячейка (ряд, полка) существует в зоне
+func (l Location) Contains(row, shelf int) bool {
+	return row >= 1 && row <= l.Rows && shelf >= 1 && shelf <= l.ShelvesPerRow
+}
+
+// количество помещается на полку
+func (l Location) Fits(quantity int) bool {
+	return l.ShelfCapacity == 0 || quantity <= l.ShelfCapacity
+}
+
+// статус остатка по порогам товара
+func (p Products) StockStatus(quantity int) string {
+	switch {
+	case quantity <= p.MinStock:
+		return StockCritical
+	case quantity <= p.OptimalStock/2:
+		return StockLow
+	default:
+		return StockOK
+	}
+}
+
+// сканирование учитывается в остатках и прогнозах
+func (h InventoryHistory) Counted() bool {
+	return h.AnomalyStatus == "" || h.AnomalyStatus == AnomalyConfirmed
+}
+
 func (InventoryHistory) TableName() string {
 	return "inventory_history"
 }
@@ -81,3 +321,35 @@ func (Robots) TableName() string {
 func (AiPrediction) TableName() string {
 	return "ai_predictions"
 }
+
+func (RobotCredential) TableName() string {
+	return "robot_credentials"
+}
+
+func (RobotMessage) TableName() string {
+	return "robot_messages"
+}
+
+func (StockLevel) TableName() string {
+	return "stock_levels"
+}
+
+func (Location) TableName() string {
+	return "locations"
+}
+
+func (Alert) TableName() string {
+	return "alerts"
+}
+
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// значения списка через запятую без пробелов и пустых
+func SplitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func listAllows(list, value string) bool {
+	values := SplitList(list)
+	if len(values) == 0 {
+		return true
+	}
+	for _, allowed := range values {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// незаданный уровень - первый
+func tierOf(tier int) int {
+	if tier < 1 {
+		return 1
+	}
+	return tier
+}
+
+// Оповещение уходит в канал: канал включен, совпадает уровень, подходят важность и зона.
+// Оповещения без зоны (прогноз, остаток товара) получают только каналы без фильтра зон.
+func (c NotificationChannel) Routes(alert Alert) bool {
+	if !c.Enabled || tierOf(c.Tier) != tierOf(alert.Tier) || !listAllows(c.Severities, alert.Severity) {
+		return false
+	}
+	if alert.Zone == "" {
+		return len(SplitList(c.Zones)) == 0
+	}
+	return listAllows(c.Zones, alert.Zone)
+}
+
+// Ближайший уровень эскалации выше текущего среди каналов, куда оповещение уйдет
+// с этим уровнем. false - эскалировать некуда.
+func NextTier(channels []NotificationChannel, alert Alert) (int, bool) {
+	next := 0
+	for _, channel := range channels {
+		tier := tierOf(channel.Tier)
+		if tier <= tierOf(alert.Tier) || (next != 0 && tier >= next) {
+			continue
+		}
+		candidate := alert
+		candidate.Tier = tier
+		if channel.Routes(candidate) {
+			next = tier
+		}
+	}
+	return next, next != 0
+}
+
+// ожидающие доставки оповещения во все каналы, куда оно уходит
+func PendingDeliveries(channels []NotificationChannel, alert Alert, now time.Time) []NotificationDelivery {
+	var deliveries []NotificationDelivery
+	for _, channel := range channels {
+		if !channel.Routes(alert) {
+			continue
+		}
+		id := channel.ID
+		deliveries = append(deliveries, NotificationDelivery{
+			AlertID:       alert.ID,
+			ChannelID:     &id,
+			ChannelKind:   channel.Kind,
+			Severity:      alert.Severity,
+			Tier:          channel.Tier,
+			Status:        DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	return deliveries
+}
+
+// адреса получателей письма или chat_id бота
+func (c NotificationChannel) Recipients() []string {
+	return SplitList(c.Target)
+}
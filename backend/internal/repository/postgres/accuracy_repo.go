@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+)
+
+type AccuracyPostgres struct {
+	db *gorm.DB
+}
+
+func NewAccuracyPostgres(db *gorm.DB) *AccuracyPostgres {
+	return &AccuracyPostgres{db: db}
+}
+
+// прогнозы, для которых исход еще не оценен
+func (r *AccuracyPostgres) PendingPredictions() ([]models.AiPrediction, error) {
+	var predictions []models.AiPrediction
+	err := r.db.Where("evaluated_at IS NULL").Order("created_at").Find(&predictions).Error
+	return predictions, err
+}
+
+// первое сканирование товара после момента прогноза, после которого общий остаток
+// (последние сканирования всех ячеек товара на этот момент) опустился до min_stock
+func (r *AccuracyPostgres) FirstStockout(productID string, after time.Time) (*time.Time, error) {
+	var stockout sql.NullTime
+	err := r.db.Raw(`
+		SELECT MIN(scans.scanned_at)
+		FROM (
+			SELECT DISTINCT inventory_history.scanned_at
+			FROM inventory_history
+			WHERE inventory_history.product_id = ? AND inventory_history.scanned_at > ? AND `+countedScan+`
+		) scans
+		JOIN products ON products.id = ?
+		WHERE (
+			SELECT COALESCE(SUM(latest.quantity), 0)
+			FROM (
+				SELECT DISTINCT ON (inventory_history.zone, inventory_history.row_number, inventory_history.shelf_number)
+					inventory_history.quantity
+				FROM inventory_history
+				WHERE inventory_history.product_id = ? AND inventory_history.scanned_at <= scans.scanned_at AND `+countedScan+`
+				ORDER BY inventory_history.zone, inventory_history.row_number, inventory_history.shelf_number, inventory_history.scanned_at DESC
+			) latest
+		) <= products.min_stock`,
+		productID, after, productID, productID).
+		Row().Scan(&stockout)
+	if err != nil || !stockout.Valid {
+		return nil, err
+	}
+	return &stockout.Time, nil
+}
+
+// сохранение фактического исхода прогноза
+func (r *AccuracyPostgres) SaveOutcome(prediction *models.AiPrediction) error {
+	return r.db.Model(&models.AiPrediction{}).Where("id = ?", prediction.ID).Updates(map[string]interface{}{
+		"actual_stockout_at": prediction.ActualStockoutAt,
+		"actual_days":        prediction.ActualDays,
+		"evaluated_at":       prediction.EvaluatedAt,
+	}).Error
+}
+
+// оцененные прогнозы, сделанные в интервале [from, to)
+func (r *AccuracyPostgres) EvaluatedPredictions(from, to time.Time) ([]models.AiPrediction, error) {
+	var predictions []models.AiPrediction
+	err := r.db.Preload("AIPredictionProduct").
+		Where("evaluated_at IS NOT NULL AND created_at >= ? AND created_at < ?", from, to).
+		Order("created_at").
+		Find(&predictions).Error
+	return predictions, err
+}
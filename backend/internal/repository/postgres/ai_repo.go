@@ -17,31 +17,38 @@ func NewAIPostgres(db *gorm.DB) *AIPostgres {
 }
 
 // getting the necessary data to send a request to the AI
-func (ai *AIPostgres) AIRequest(rq entities.AIRequest) ([]models.InventoryHistory, error) {
+func (ai *AIPostgres) AIRequest(rq entities.AIRequest, window entities.HistoryWindow) ([]models.InventoryHistory, error) {
 	var products []models.InventoryHistory
 
-	startTime := time.Now().Add(-72 * time.Hour)
-
-	// creating an additional query to split the data into intervals and reduce the total number
+	// последнее сканирование каждой ячейки товара в интервале: свежие данные по слотам, старые по дням
+	slotSeconds := int(window.Slot.Seconds())
 	subQuery := ai.db.
 		Table("inventory_history").
 		Select(`
-			product_id,
-			DATE_TRUNC('hour', scanned_at) + 
-			INTERVAL '10 min' * FLOOR(EXTRACT(minute FROM scanned_at) / 10) as time_slot,
-			MAX(scanned_at) as latest_in_slot`).
-		Where("scanned_at >= ?", startTime).
-		Group("product_id, time_slot")
+			product_id, zone, row_number, shelf_number,
+			CASE WHEN scanned_at >= ?
+				THEN to_timestamp(FLOOR(EXTRACT(EPOCH FROM scanned_at) / ?) * ?)
+				ELSE DATE_TRUNC('day', scanned_at)
+			END as time_slot,
+			MAX(scanned_at) as latest_in_slot`, window.DetailFrom, slotSeconds, slotSeconds).
+		Where("scanned_at >= ?", window.From).
+		Where(countedScan).
+		Group("product_id, zone, row_number, shelf_number, time_slot")
 
 	// create a query to get the necessary data from the database and select by category if any
 	query := ai.db.Select(`
 						inventory_history.id,
 						inventory_history.product_id,
 						inventory_history.quantity,
+						inventory_history.zone,
+						inventory_history.row_number,
+						inventory_history.shelf_number,
 						inventory_history.status,
 						inventory_history.scanned_at`).
 					Preload("Product").Joins("JOIN products ON inventory_history.product_id = products.id").
-					Joins("JOIN (?) as time_slots ON inventory_history.product_id = time_slots.product_id AND inventory_history.scanned_at = time_slots.latest_in_slot", subQuery)
+					Joins("JOIN (?) as time_slots ON inventory_history.product_id = time_slots.product_id AND inventory_history.zone = time_slots.zone AND inventory_history.row_number = time_slots.row_number AND inventory_history.shelf_number = time_slots.shelf_number AND inventory_history.scanned_at = time_slots.latest_in_slot", subQuery)
+	// неподтвержденные выбросы не попадают в прогноз
+	query = query.Where(countedScan)
 	if len(rq.Categories) > 0 {
 		query = query.Where("products.category IN ? ", rq.Categories)
 	}
@@ -55,14 +62,15 @@ func (ai *AIPostgres) AIRequest(rq entities.AIRequest) ([]models.InventoryHistor
 	return products, nil
 }
 
-// recording the response from the AI in the database
-func (ai *AIPostgres) AIResponse(rp entities.AIResponse) error {
+// recording the response from the AI in the database, returns alerts raised by the forecast
+func (ai *AIPostgres) AIResponse(rp entities.AIResponse) ([]models.Alert, error) {
+	saved := make([]models.AiPrediction, 0, len(rp.Predictions))
 	for _, elem := range rp.Predictions {
 		predictionDate, err := time.Parse("2006-01-02", elem.PredictionDate)
 			if err != nil {
 				predictionDate, err = time.Parse("02.01.2006", elem.PredictionDate)
 				if err != nil {
-					return err
+					return nil, err
 				}
 			}
 
@@ -72,12 +80,83 @@ func (ai *AIPostgres) AIResponse(rp entities.AIResponse) error {
 			DaysUntilStockout: elem.DaysUntilStockout,
 			RecommendedOrder:  elem.RecommendedOrder,
 			ConfidenceScore:   elem.ConfidenceScore,
+			Provider:          rp.Provider,
 		}
 
-		if err := ai.db.Create(&prediction).Error; err != nil {
+		saved = append(saved, prediction)
+	}
+
+	// прогнозы, оповещения по ним и журнал доставки записываются вместе
+	var alerts []models.Alert
+	err := ai.db.Transaction(func(tx *gorm.DB) error {
+		for i := range saved {
+			if err := tx.Create(&saved[i]).Error; err != nil {
+				return err
+			}
+		}
+		subjects, err := forecastSubjects(tx, saved)
+		if err != nil {
 			return err
 		}
+		alerts, err = evaluateRules(tx, subjects)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// фильтры истории прогнозов, даты уже проверены сервисом
+func (ai *AIPostgres) predictionsQuery(query entities.PredictionQuery) *gorm.DB {
+	db := ai.db.Model(&models.AiPrediction{}).
+		Joins("JOIN products ON products.id = ai_predictions.product_id")
+	if query.ProductID != "" {
+		db = db.Where("ai_predictions.product_id = ?", query.ProductID)
+	}
+	if query.Category != "" {
+		db = db.Where("products.category = ?", query.Category)
 	}
+	if query.Provider != "" {
+		db = db.Where("ai_predictions.provider = ?", query.Provider)
+	}
+	if query.From != "" {
+		db = db.Where("ai_predictions.prediction_date >= ?", query.From)
+	}
+	if query.To != "" {
+		db = db.Where("ai_predictions.prediction_date <= ?", query.To)
+	}
+	if query.MinConfidence != nil {
+		db = db.Where("ai_predictions.confidence_score >= ?", *query.MinConfidence)
+	}
+	return db
+}
+
+// история прогнозов, новые первыми
+func (ai *AIPostgres) ListPredictions(query entities.PredictionQuery) ([]models.AiPrediction, int64, error) {
+	var predictions []models.AiPrediction
+	var total int64
+
+	db := ai.predictionsQuery(query)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("AIPredictionProduct").
+		Order("ai_predictions.created_at DESC, ai_predictions.id DESC").
+		Limit(query.Limit).Offset(query.Offset).
+		Find(&predictions).Error
+	return predictions, total, err
+}
 
-	return nil
+// последний прогноз по каждому товару каталога среди подходящих под фильтры
+func (ai *AIPostgres) LatestPredictions(query entities.PredictionQuery) ([]models.AiPrediction, error) {
+	var predictions []models.AiPrediction
+	err := ai.predictionsQuery(query).
+		Select("DISTINCT ON (ai_predictions.product_id) ai_predictions.*").
+		Where("products.archived_at IS NULL").
+		Preload("AIPredictionProduct").
+		Order("ai_predictions.product_id, ai_predictions.created_at DESC, ai_predictions.id DESC").
+		Find(&predictions).Error
+	return predictions, err
 }
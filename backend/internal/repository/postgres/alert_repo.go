@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AlertPostgres struct {
+	db *gorm.DB
+}
+
+func NewAlertPostgres(db *gorm.DB) *AlertPostgres {
+	return &AlertPostgres{db: db}
+}
+
+// оповещения с фильтрами, новые первыми
+func (r *AlertPostgres) ListAlerts(query entities.AlertQuery) ([]models.Alert, int64, error) {
+	var alerts []models.Alert
+	var total int64
+
+	db := r.db.Model(&models.Alert{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Severity != "" {
+		db = db.Where("severity = ?", query.Severity)
+	}
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+	if query.ProductID != "" {
+		db = db.Where("product_id = ?", query.ProductID)
+	}
+	if query.Zone != "" {
+		db = db.Where("zone = ?", query.Zone)
+	}
+	if query.RobotID != "" {
+		db = db.Where("robot_id = ?", query.RobotID)
+	}
+	if query.RuleID != nil {
+		db = db.Where("rule_id = ?", *query.RuleID)
+	}
+	if query.AssigneeID != nil {
+		db = db.Where("assignee_id = ?", *query.AssigneeID)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Limit(query.Limit).Offset(query.Offset).Order("created_at DESC, id DESC").Find(&alerts).Error
+	return alerts, total, err
+}
+
+func (r *AlertPostgres) GetAlert(id uint) (*models.Alert, error) {
+	var alert models.Alert
+	if err := r.db.First(&alert, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// сохранение перехода, если статус с момента чтения не изменился
+func (r *AlertPostgres) UpdateAlert(alert *models.Alert, fromStatus string) error {
+	alert.UpdatedAt = time.Now()
+	result := r.db.Model(&models.Alert{}).
+		Where("id = ? AND status = ?", alert.ID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":          alert.Status,
+			"assignee_id":     alert.AssigneeID,
+			"acknowledged_at": alert.AcknowledgedAt,
+			"acknowledged_by": alert.AcknowledgedBy,
+			"resolved_at":     alert.ResolvedAt,
+			"resolved_by":     alert.ResolvedBy,
+			"resolution_note": alert.ResolutionNote,
+			"updated_at":      alert.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: alert %d was changed concurrently", entities.ErrConflict, alert.ID)
+	}
+	return nil
+}
+
+// Неподтвержденные критические оповещения, которые дольше after держатся на своем уровне,
+// поднимаются на ближайший уровень, каналы которого принимают их важность и зону.
+// Доставки в каналы нового уровня записываются в той же транзакции.
+func (r *AlertPostgres) EscalateAlerts(now time.Time, after time.Duration) ([]models.Alert, error) {
+	var alerts []models.Alert
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var channels []models.NotificationChannel
+		if err := tx.Where("enabled").Order("id").Find(&channels).Error; err != nil {
+			return err
+		}
+		var stale []models.Alert
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND severity = ? AND tier_since <= ?", models.AlertOpen, models.SeverityCritical, now.Add(-after)).
+			Order("id").Find(&stale).Error
+		if err != nil {
+			return err
+		}
+
+		// следующий уровень выбирается только из каналов, куда оповещение уйдет по важности и зоне
+		for _, alert := range stale {
+			tier, ok := models.NextTier(channels, alert)
+			if !ok {
+				continue
+			}
+			err := tx.Model(&models.Alert{}).Where("id = ?", alert.ID).Updates(map[string]interface{}{
+				"tier":       tier,
+				"tier_since": now,
+				"updated_at": now,
+			}).Error
+			if err != nil {
+				return err
+			}
+			alert.Tier, alert.TierSince, alert.UpdatedAt = tier, now, now
+			alerts = append(alerts, alert)
+		}
+		return enqueueDeliveries(tx, alerts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// активное оповещение с ключом, nil если его нет
+func activeAlert(tx *gorm.DB, key string) (*models.Alert, error) {
+	var alert models.Alert
+	err := tx.Where("dedup_key = ? AND status <> ?", key, models.AlertResolved).First(&alert).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func autoResolve(tx *gorm.DB, alert *models.Alert, note string) error {
+	now := time.Now()
+	return tx.Model(alert).Updates(map[string]interface{}{
+		"status":          models.AlertResolved,
+		"resolved_at":     now,
+		"resolution_note": note,
+		"updated_at":      now,
+	}).Error
+}
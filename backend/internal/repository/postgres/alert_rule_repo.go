@@ -0,0 +1,567 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AlertRulePostgres struct {
+	db *gorm.DB
+}
+
+func NewAlertRulePostgres(db *gorm.DB) *AlertRulePostgres {
+	return &AlertRulePostgres{db: db}
+}
+
+func (r *AlertRulePostgres) ListRules() ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := r.db.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+func (r *AlertRulePostgres) GetRule(id uint) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *AlertRulePostgres) CreateRule(rule *models.AlertRule) error {
+	return r.db.Create(rule).Error
+}
+
+// измененное правило применяется при следующей проверке
+func (r *AlertRulePostgres) UpdateRule(rule *models.AlertRule) error {
+	rule.UpdatedAt = time.Now()
+	return r.db.Save(rule).Error
+}
+
+// оповещения удаленного правила остаются в истории, активные закроет следующая проверка
+func (r *AlertRulePostgres) DeleteRule(id uint) error {
+	result := r.db.Delete(&models.AlertRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+// полная проверка правил по текущему состоянию склада.
+// Активные оповещения, которые не подтвердило ни одно включенное правило, закрываются.
+func (r *AlertRulePostgres) EvaluateRules(now time.Time) ([]models.Alert, error) {
+	var raised []models.Alert
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		rules, err := enabledRules(tx)
+		if err != nil {
+			return err
+		}
+
+		types := make(map[string]bool)
+		for _, rule := range rules {
+			types[rule.AlertType()] = true
+		}
+		// состояния загружаются только для типов, по которым есть правила
+		loaders := []struct {
+			alertType string
+			load      func() ([]models.RuleSubject, error)
+		}{
+			{models.AlertTypeScanned, func() ([]models.RuleSubject, error) { return cellSubjects(tx, nil) }},
+			{models.AlertTypeStock, func() ([]models.RuleSubject, error) { return productSubjects(tx, nil) }},
+			{models.AlertTypeRobot, func() ([]models.RuleSubject, error) { return robotSubjects(tx, nil) }},
+			{models.AlertTypeZone, func() ([]models.RuleSubject, error) { return zoneSubjects(tx, nil, now) }},
+			{models.AlertTypePredicted, func() ([]models.RuleSubject, error) { return latestForecastSubjects(tx) }},
+		}
+		var subjects []models.RuleSubject
+		for _, loader := range loaders {
+			if !types[loader.alertType] {
+				continue
+			}
+			loaded, err := loader.load()
+			if err != nil {
+				return err
+			}
+			subjects = append(subjects, loaded...)
+		}
+
+		alerts, held, err := applyRules(tx, rules, subjects, true)
+		if err != nil {
+			return err
+		}
+		raised = alerts
+
+		var active []models.Alert
+		if err := tx.Where("status <> ?", models.AlertResolved).Find(&active).Error; err != nil {
+			return err
+		}
+		for i := range active {
+			if held[active[i].DedupKey] {
+				continue
+			}
+			if err := autoResolve(tx, &active[i], "no enabled rule matches anymore"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return raised, nil
+}
+
+func enabledRules(tx *gorm.DB) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := tx.Where("enabled = ?", true).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// проверка правил по состояниям, вызывается внутри транзакции записи данных.
+// Возвращает новые оповещения и оповещения, важность которых выросла.
+func evaluateRules(tx *gorm.DB, subjects []models.RuleSubject) ([]models.Alert, error) {
+	if len(subjects) == 0 {
+		return nil, nil
+	}
+	rules, err := enabledRules(tx)
+	if err != nil {
+		return nil, err
+	}
+	raised, _, err := applyRules(tx, rules, subjects, false)
+	return raised, err
+}
+
+// состояние и сработавшие на нем правила одного ключа
+type keySubject struct {
+	subject models.RuleSubject
+	fired   []models.AlertRule
+}
+
+// правила группируются по ключу: по каждому ключу не больше одного активного оповещения.
+// Ключ с собственным шаблоном может объединять несколько состояний, решение по нему
+// принимается по всем состояниям сразу. complete - в subjects все состояния склада.
+// held - ключи, по которым после проверки осталось активное оповещение.
+// Новые и усиленные оповещения сразу записываются в журнал доставки.
+func applyRules(tx *gorm.DB, rules []models.AlertRule, subjects []models.RuleSubject, complete bool) ([]models.Alert, map[string]bool, error) {
+	var keys []string
+	byKey := make(map[string][]keySubject)
+	for _, s := range subjects {
+		var subjectKeys []string
+		fired := make(map[string][]models.AlertRule)
+		for _, rule := range rules {
+			if !rule.Applies(s) {
+				continue
+			}
+			key := rule.Key(s)
+			if _, ok := fired[key]; !ok {
+				subjectKeys = append(subjectKeys, key)
+				fired[key] = nil
+			}
+			if rule.Fires(s) {
+				fired[key] = append(fired[key], rule)
+			}
+		}
+		for _, key := range subjectKeys {
+			if _, ok := byKey[key]; !ok {
+				keys = append(keys, key)
+			}
+			byKey[key] = append(byKey[key], keySubject{subject: s, fired: fired[key]})
+		}
+	}
+
+	var raised []models.Alert
+	held := make(map[string]bool)
+	for _, key := range keys {
+		alert, active, err := applyKey(tx, rules, key, byKey[key], complete)
+		if err != nil {
+			return nil, nil, err
+		}
+		if alert != nil {
+			raised = append(raised, *alert)
+		}
+		if active {
+			held[key] = true
+		}
+	}
+	if err := enqueueDeliveries(tx, raised); err != nil {
+		return nil, nil, err
+	}
+	return raised, held, nil
+}
+
+// оповещение по одному ключу: открывается самым важным сработавшим правилом,
+// меняет важность вслед за правилами и закрывается, когда его правило перестало
+// срабатывать на всех состояниях ключа
+func applyKey(tx *gorm.DB, rules []models.AlertRule, key string, entries []keySubject, complete bool) (*models.Alert, bool, error) {
+	var best *models.AlertRule
+	var bestSubject models.RuleSubject
+	for _, e := range entries {
+		for i := range e.fired {
+			if best == nil || models.MoreSevere(e.fired[i].Severity, best.Severity) {
+				best = &e.fired[i]
+				bestSubject = e.subject
+			}
+		}
+	}
+
+	active, err := activeAlert(tx, key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if active != nil {
+		owner := ruleByID(rules, active.RuleID)
+		var ownerApplies, ownerFires bool
+		var ownerSubject models.RuleSubject
+		for _, e := range entries {
+			if owner == nil || !owner.Applies(e.subject) || owner.Key(e.subject) != key {
+				continue
+			}
+			if !ownerApplies || (!ownerFires && owner.Fires(e.subject)) {
+				ownerSubject = e.subject
+			}
+			ownerApplies = true
+			ownerFires = ownerFires || owner.Fires(e.subject)
+		}
+
+		keeper, s := best, bestSubject
+		if ownerFires && !models.MoreSevere(best.Severity, owner.Severity) {
+			keeper, s = owner, ownerSubject
+		}
+		switch {
+		case keeper != nil:
+			// повтор условия по новым данным считается, но не рассылается;
+			// рассылается только повышение важности
+			raised := models.MoreSevere(keeper.Severity, active.Severity)
+			repeated := s.At.After(active.ScannedAt)
+			ruleID := keeper.ID
+			active.RuleID = &ruleID
+			active.Severity = keeper.Severity
+			active.Quantity = s.Quantity
+			active.Message = keeper.Message(s)
+			active.UpdatedAt = time.Now()
+			updates := map[string]interface{}{
+				"rule_id":    ruleID,
+				"severity":   active.Severity,
+				"quantity":   active.Quantity,
+				"message":    active.Message,
+				"updated_at": active.UpdatedAt,
+			}
+			if repeated {
+				// повтор - только по более новым данным, проверка без них не считается
+				active.ScannedAt = s.At
+				active.Occurrences++
+				updates["scanned_at"] = active.ScannedAt
+				updates["occurrences"] = gorm.Expr("occurrences + 1")
+			}
+			if raised {
+				// время до эскалации отсчитывается от повышения важности
+				active.Tier = 1
+				active.TierSince = active.UpdatedAt
+				updates["tier"] = active.Tier
+				updates["tier_since"] = active.TierSince
+			}
+			if err := tx.Model(&models.Alert{}).Where("id = ?", active.ID).Updates(updates).Error; err != nil || !raised {
+				return nil, true, err
+			}
+			return active, true, nil
+		case ownerApplies && !complete && owner.DedupKey != "":
+			// ключ шаблона может объединять состояния вне этой записи,
+			// закрытие решит полная проверка
+			return nil, true, nil
+		case ownerApplies:
+			err := autoResolve(tx, active, fmt.Sprintf("condition of rule %d cleared at %s", owner.ID, ownerSubject.At.Format(time.RFC3339)))
+			return nil, false, err
+		default:
+			// оповещение другого состояния с тем же ключом или отключенного правила
+			return nil, false, nil
+		}
+	}
+
+	if best == nil {
+		return nil, false, nil
+	}
+	if cooling, err := inCooldown(tx, *best, key); err != nil || cooling {
+		return nil, false, err
+	}
+
+	alert := best.NewAlert(bestSubject)
+	result := tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "dedup_key"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status <> 'resolved'"}}},
+		DoNothing:   true,
+	}).Create(&alert)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 { // параллельная проверка уже открыла оповещение
+		return nil, true, nil
+	}
+	return &alert, true, nil
+}
+
+func ruleByID(rules []models.AlertRule, id *uint) *models.AlertRule {
+	if id == nil {
+		return nil
+	}
+	for i := range rules {
+		if rules[i].ID == *id {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// правило недавно закрытого оповещения не открывает его снова до конца паузы
+func inCooldown(tx *gorm.DB, rule models.AlertRule, key string) (bool, error) {
+	if rule.CooldownSeconds == 0 {
+		return false, nil
+	}
+	var count int64
+	err := tx.Model(&models.Alert{}).
+		Where("rule_id = ? AND dedup_key = ? AND resolved_at > ?", rule.ID, key, time.Now().Add(-rule.Cooldown())).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// состояния, затронутые записанными сканированиями: ячейки, товары и зоны
+func scanSubjects(tx *gorm.DB, histories []models.InventoryHistory) ([]models.RuleSubject, error) {
+	cells := make([][]interface{}, 0, len(histories))
+	productIDs := make([]string, 0, len(histories))
+	zones := make([]string, 0, 1)
+	seen := make(map[string]bool)
+	for _, h := range histories {
+		if !h.Counted() {
+			continue
+		}
+		cells = append(cells, []interface{}{h.ProductID, h.Zone, h.RowNumber, h.ShelfNumber})
+		if !seen["product:"+h.ProductID] {
+			seen["product:"+h.ProductID] = true
+			productIDs = append(productIDs, h.ProductID)
+		}
+		if !seen["zone:"+h.Zone] {
+			seen["zone:"+h.Zone] = true
+			zones = append(zones, h.Zone)
+		}
+	}
+	if len(cells) == 0 {
+		return nil, nil
+	}
+
+	subjects, err := cellSubjects(tx, cells)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	products, err := productSubjects(tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	zoneStates, err := zoneSubjects(tx, zones, now)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(subjects, products...), zoneStates...), nil
+}
+
+// ячейки по текущим остаткам, nil - все ячейки
+func cellSubjects(tx *gorm.DB, cells [][]interface{}) ([]models.RuleSubject, error) {
+	query := tx.Preload("Product")
+	if cells != nil {
+		query = query.Where("(product_id, zone, row_number, shelf_number) IN ?", cells)
+	}
+	var levels []models.StockLevel
+	if err := query.Find(&levels).Error; err != nil {
+		return nil, err
+	}
+
+	subjects := make([]models.RuleSubject, 0, len(levels))
+	for _, level := range levels {
+		if level.Product.ArchivedAt != nil {
+			continue
+		}
+		subjects = append(subjects, models.RuleSubject{
+			Type:        models.AlertTypeScanned,
+			Product:     level.Product,
+			Zone:        level.Zone,
+			RowNumber:   level.RowNumber,
+			ShelfNumber: level.ShelfNumber,
+			Quantity:    level.Quantity,
+			At:          level.ScannedAt,
+		})
+	}
+	return subjects, nil
+}
+
+// суммарный остаток по каждой ячейке товара на время последнего сканирования, nil - все товары каталога
+func productSubjects(tx *gorm.DB, productIDs []string) ([]models.RuleSubject, error) {
+	products, totals, err := productsWithStock(tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := make([]models.RuleSubject, 0, len(products))
+	for _, product := range products {
+		subjects = append(subjects, models.RuleSubject{
+			Type:     models.AlertTypeStock,
+			Product:  product,
+			Quantity: totals[product.ID].Quantity,
+			At:       totals[product.ID].ScannedAt,
+		})
+	}
+	return subjects, nil
+}
+
+// остаток товара во всех ячейках и время последнего сканирования
+type productStock struct {
+	Quantity  int
+	ScannedAt time.Time
+}
+
+func productsWithStock(tx *gorm.DB, productIDs []string) ([]models.Products, map[string]productStock, error) {
+	query := tx.Where("archived_at IS NULL")
+	totalsQuery := tx.Model(&models.StockLevel{}).
+		Select("product_id, SUM(quantity) AS quantity, MAX(scanned_at) AS scanned_at").
+		Group("product_id")
+	if productIDs != nil {
+		query = query.Where("id IN ?", productIDs)
+		totalsQuery = totalsQuery.Where("product_id IN ?", productIDs)
+	}
+
+	var products []models.Products
+	if err := query.Find(&products).Error; err != nil {
+		return nil, nil, err
+	}
+	var rows []struct {
+		ProductID string
+		Quantity  int
+		ScannedAt time.Time
+	}
+	if err := totalsQuery.Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	totals := make(map[string]productStock, len(rows))
+	for _, row := range rows {
+		totals[row.ProductID] = productStock{Quantity: row.Quantity, ScannedAt: row.ScannedAt}
+	}
+	return products, totals, nil
+}
+
+// работающие роботы реестра без служебного робота импорта, nil - все
+func robotSubjects(tx *gorm.DB, robotIDs []string) ([]models.RuleSubject, error) {
+	query := tx.Where("id <> ? AND status <> ?", models.ImportRobotID, models.RobotStatusDecommissioned)
+	if robotIDs != nil {
+		query = query.Where("id IN ?", robotIDs)
+	}
+	var robots []models.Robots
+	if err := query.Find(&robots).Error; err != nil {
+		return nil, err
+	}
+
+	subjects := make([]models.RuleSubject, 0, len(robots))
+	for _, robot := range robots {
+		subjects = append(subjects, models.RuleSubject{
+			Type:         models.AlertTypeRobot,
+			RobotID:      robot.ID,
+			Zone:         robot.CurrentZone,
+			BatteryLevel: robot.BatteryLevel,
+			At:           robot.LastUpdate,
+		})
+	}
+	return subjects, nil
+}
+
+// зоны раскладки с последним сканированием на момент проверки now,
+// до первого сканирования отсчет идет от создания зоны
+func zoneSubjects(tx *gorm.DB, zones []string, now time.Time) ([]models.RuleSubject, error) {
+	query := tx.Table("locations").
+		Select("locations.zone, locations.created_at, MAX(stock_levels.scanned_at) AS last_scan_at").
+		Joins("LEFT JOIN stock_levels ON stock_levels.zone = locations.zone").
+		Group("locations.zone, locations.created_at")
+	if zones != nil {
+		query = query.Where("locations.zone IN ?", zones)
+	}
+	var rows []struct {
+		Zone       string
+		CreatedAt  time.Time
+		LastScanAt *time.Time
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	subjects := make([]models.RuleSubject, 0, len(rows))
+	for _, row := range rows {
+		lastScan := row.CreatedAt
+		if row.LastScanAt != nil {
+			lastScan = *row.LastScanAt
+		}
+		subjects = append(subjects, models.RuleSubject{
+			Type:       models.AlertTypeZone,
+			Zone:       row.Zone,
+			LastScanAt: lastScan,
+			At:         lastScan,
+			CheckedAt:  now,
+		})
+	}
+	return subjects, nil
+}
+
+// прогнозы с текущим остатком товара
+func forecastSubjects(tx *gorm.DB, predictions []models.AiPrediction) ([]models.RuleSubject, error) {
+	if len(predictions) == 0 {
+		return nil, nil
+	}
+	productIDs := make([]string, 0, len(predictions))
+	for _, p := range predictions {
+		productIDs = append(productIDs, p.ProductID)
+	}
+	products, totals, err := productsWithStock(tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Products, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	subjects := make([]models.RuleSubject, 0, len(predictions))
+	for _, p := range predictions {
+		product, ok := byID[p.ProductID]
+		if !ok { // товар в архиве
+			continue
+		}
+		subjects = append(subjects, models.RuleSubject{
+			Type:              models.AlertTypePredicted,
+			Product:           product,
+			Quantity:          totals[p.ProductID].Quantity,
+			DaysUntilStockout: p.DaysUntilStockout,
+			RecommendedOrder:  p.RecommendedOrder,
+			ConfidenceScore:   p.ConfidenceScore,
+			At:                p.CreatedAt,
+		})
+	}
+	return subjects, nil
+}
+
+// последний прогноз по каждому товару
+func latestForecastSubjects(tx *gorm.DB) ([]models.RuleSubject, error) {
+	var predictions []models.AiPrediction
+	err := tx.Select("DISTINCT ON (product_id) *").
+		Order("product_id, created_at DESC, id DESC").
+		Find(&predictions).Error
+	if err != nil {
+		return nil, err
+	}
+	return forecastSubjects(tx, predictions)
+}
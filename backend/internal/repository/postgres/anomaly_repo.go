@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// условие для сканирований, которые учитываются в остатках и прогнозах
+const countedScan = "inventory_history.anomaly_status IN ('', 'confirmed')"
+
+// сравнение нового сканирования с недавней историей той же ячейки.
+// Если новое количество совпадает с ранее отмеченным выбросом, остаток действительно
+// сменился: выброс подтверждается, а новое сканирование считается обычным.
+func markAnomaly(tx *gorm.DB, detector models.AnomalyDetector, h *models.InventoryHistory) error {
+	if !detector.Enabled() {
+		return nil
+	}
+
+	var recent []models.InventoryHistory
+	err := tx.Select("id", "quantity", "anomaly_status").
+		Where("product_id = ? AND zone = ? AND row_number = ? AND shelf_number = ?", h.ProductID, h.Zone, h.RowNumber, h.ShelfNumber).
+		Where("scanned_at >= ? AND scanned_at < ?", h.ScannedAt.Add(-detector.Window), h.ScannedAt).
+		Where("anomaly_status <> ?", models.AnomalyRejected).
+		Order("scanned_at DESC").
+		Limit(detector.MaxSamples).
+		Find(&recent).Error
+	if err != nil {
+		return err
+	}
+
+	var baseline []int
+	var confirmed []uint
+	for _, r := range recent {
+		if r.Counted() {
+			baseline = append(baseline, r.Quantity)
+		} else if detector.Agrees(r.Quantity, h.Quantity) {
+			confirmed = append(confirmed, r.ID)
+		}
+	}
+
+	if len(confirmed) > 0 {
+		return tx.Model(&models.InventoryHistory{}).
+			Where("id IN ? AND anomaly_status = ?", confirmed, models.AnomalyPending).
+			Updates(map[string]interface{}{
+				"anomaly_status":      models.AnomalyConfirmed,
+				"anomaly_resolved_at": time.Now(),
+			}).Error
+	}
+
+	if score, reason, anomalous := detector.Check(baseline, h.Quantity); anomalous {
+		h.AnomalyStatus = models.AnomalyPending
+		h.AnomalyScore = &score
+		h.AnomalyReason = reason
+	}
+	return nil
+}
+
+// отмеченные сканирования, новые сверху
+func (r *InventoryRepo) ListAnomalies(status string, limit, offset int) ([]models.InventoryHistory, int64, error) {
+	var histories []models.InventoryHistory
+	var total int64
+
+	query := r.db.Model(&models.InventoryHistory{})
+	if status != "" {
+		query = query.Where("anomaly_status = ?", status)
+	} else {
+		query = query.Where("anomaly_status <> ''")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("Robot").Preload("Product").Limit(limit).Offset(offset).Order("scanned_at DESC").Find(&histories).Error
+	return histories, total, err
+}
+
+// решение оператора по выбросу, подтвержденное сканирование обновляет остаток и оповещения ячейки.
+// Возвращает оповещения, открытые или усиленные решением.
+func (r *InventoryRepo) ResolveAnomaly(id uint, status string, userID uint) (*models.InventoryHistory, []models.Alert, error) {
+	var history models.InventoryHistory
+	var alerts []models.Alert
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&history, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entities.ErrNotFound
+			}
+			return err
+		}
+		if history.AnomalyStatus != models.AnomalyPending {
+			return fmt.Errorf("%w: scan %d is not a pending anomaly", entities.ErrConflict, id)
+		}
+
+		now := time.Now()
+		history.AnomalyStatus = status
+		history.AnomalyResolvedAt = &now
+		history.AnomalyResolvedBy = &userID
+		err := tx.Model(&history).Updates(map[string]interface{}{
+			"anomaly_status":      status,
+			"anomaly_resolved_at": now,
+			"anomaly_resolved_by": userID,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		resolved := []models.InventoryHistory{history}
+		if err := upsertStockLevels(tx, resolved); err != nil {
+			return err
+		}
+		subjects, err := scanSubjects(tx, resolved)
+		if err != nil {
+			return err
+		}
+		alerts, err = evaluateRules(tx, subjects)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &history, alerts, nil
+}
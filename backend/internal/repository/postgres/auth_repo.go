@@ -1,6 +1,9 @@
 package postgres
 
 import (
+	"errors"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
 	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"gorm.io/gorm"
 )
@@ -29,3 +32,34 @@ func (r *AuthPostgres) GetUser(email string, passwordHash string) (*models.Users
 	result := r.db.Where("password_hash = ? and email = ?", passwordHash, email).Find(&user)
 	return &user, result.Error
 }
+
+// getting user data from the database by id
+func (r *AuthPostgres) GetUserByID(id uint) (*models.Users, error) {
+	var user models.Users
+	if err := r.db.First(&user, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// getting all users ordered by id
+func (r *AuthPostgres) ListUsers() ([]models.Users, error) {
+	var users []models.Users
+	err := r.db.Order("id").Find(&users).Error
+	return users, err
+}
+
+// changing the user's role
+func (r *AuthPostgres) UpdateUserRole(id uint, role string) error {
+	result := r.db.Model(&models.Users{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
@@ -49,21 +49,21 @@ func (d *DashPostgres) GetDashInfo(dash *entities.DashInfo) error {
 }
 
 func (d *DashPostgres) getStatistics(statistics *entities.Statistics, robots []models.Robots) error {
-	// Count active robots
+	// Count robots from the registry, excluding IMPORT_SERVICE and decommissioned ones
 	activeRobots := 0
 	totalBattery := 0
+	totalRobots := 0
 	for _, robot := range robots {
-		if robot.Status == "active" && robot.ID != "IMPORT_SERVICE" {
+		if robot.ID == models.ImportRobotID || robot.Status == models.RobotStatusDecommissioned {
+			continue
+		}
+		totalRobots++
+		if robot.Status == models.RobotStatusActive {
 			activeRobots++
 			totalBattery += robot.BatteryLevel
 		}
 	}
-	
-	totalRobots := len(robots)
-	if totalRobots > 0 && robots[0].ID == "IMPORT_SERVICE" {
-		totalRobots-- // Exclude IMPORT_SERVICE from count
-	}
-	
+
 	statistics.ActiveRobots = activeRobots
 	statistics.TotalRobots = totalRobots
 	
@@ -88,6 +88,7 @@ func (d *DashPostgres) getStatistics(statistics *entities.Statistics, robots []m
 	var criticalItems int64
 	if err := d.db.Model(&models.InventoryHistory{}).
 		Where("status IN ?", []string{"LOW_STOCK", "CRITICAL"}).
+		Where(countedScan).
 		Distinct("product_id").
 		Count(&criticalItems).Error; err != nil {
 		return fmt.Errorf("failed to count critical items: %w", err)
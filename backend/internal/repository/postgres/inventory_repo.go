@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
 	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"gorm.io/gorm"
 )
@@ -16,9 +17,15 @@ func NewInventoryRepo(db *gorm.DB) *InventoryRepo {
 	return &InventoryRepo{db: db}
 }
 
-// вставка данных в бд по 100 записей
+// вставка данных в бд по 100 записей вместе с обновлением текущих остатков,
+// оповещения по импорту откроет периодическая проверка правил
 func (r *InventoryRepo) ImportInventoryHistories(histories []models.InventoryHistory) error {
-	return r.db.CreateInBatches(histories, 100).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(histories, 100).Error; err != nil {
+			return err
+		}
+		return upsertStockLevels(tx, histories)
+	})
 }
 
 // получение данных инвентаризации по id продуктов
@@ -35,20 +42,11 @@ func (r *InventoryRepo) GetInventoryHistoryByScanIDs(scanIDs []string) ([]models
 	return histories, err
 }
 
-// получение продукта по id
-func (r *InventoryRepo) GetProductByID(productID string) error {
-	var product models.Products
-	return r.db.First(&product, "id = ?", productID).Error
-}
-
-// добавление продукта в бд
-func (r *InventoryRepo) CreateProduct(product *models.Products) error {
-	return r.db.Create(product).Error
-}
-
-// обновление данных о продукте
-func (r *InventoryRepo) UpdateProduct(product *models.Products) error {
-	return r.db.Save(product).Error
+// получение продуктов по списку id
+func (r *InventoryRepo) GetProductsByIDs(productIDs []string) ([]models.Products, error) {
+	var products []models.Products
+	err := r.db.Where("id IN ?", productIDs).Find(&products).Error
+	return products, err
 }
 
 // функция для корректного парсинга дат
@@ -125,3 +123,23 @@ func (r *InventoryRepo) GetHistory(from, to, zone, status string, limit, offset
 	err := query.Preload("Robot").Preload("Product").Limit(limit).Offset(offset).Order("scanned_at DESC").Find(&histories).Error
 	return histories, total, err
 }
+
+// последние учтенные сканирования, фильтры применяются в запросе, а не после лимита
+func (r *InventoryRepo) GetRecentScans(q entities.RecentScansQuery) ([]models.InventoryHistory, error) {
+	var histories []models.InventoryHistory
+	query := r.db.Model(&models.InventoryHistory{}).
+		Joins("JOIN products ON products.id = inventory_history.product_id").
+		Where("inventory_history.scanned_at >= ?", q.Since).
+		Where(countedScan)
+	if len(q.Zones) > 0 {
+		query = query.Where("inventory_history.zone IN ?", q.Zones)
+	}
+	if len(q.Categories) > 0 {
+		query = query.Where("products.category IN ?", q.Categories)
+	}
+	if len(q.ProductIDs) > 0 {
+		query = query.Where("inventory_history.product_id IN ?", q.ProductIDs)
+	}
+	err := query.Preload("Product").Order("inventory_history.scanned_at DESC").Limit(q.Limit).Find(&histories).Error
+	return histories, err
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+)
+
+type LocationPostgres struct {
+	db *gorm.DB
+}
+
+func NewLocationPostgres(db *gorm.DB) *LocationPostgres {
+	return &LocationPostgres{db: db}
+}
+
+// раскладка склада по зонам
+func (r *LocationPostgres) ListLocations() ([]models.Location, error) {
+	var locations []models.Location
+	err := r.db.Order("zone").Find(&locations).Error
+	return locations, err
+}
+
+func (r *LocationPostgres) GetLocation(zone string) (*models.Location, error) {
+	return getLocation(r.db, zone)
+}
+
+// добавление зоны
+func (r *LocationPostgres) CreateLocation(location *models.Location) error {
+	var count int64
+	if err := r.db.Model(&models.Location{}).Where("zone = ?", location.Zone).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: zone %s already exists", entities.ErrConflict, location.Zone)
+	}
+	return r.db.Create(location).Error
+}
+
+// изменение зоны, уменьшить ее можно только если за границами нет товара
+func (r *LocationPostgres) UpdateLocation(location *models.Location) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&models.StockLevel{}).
+			Where("zone = ? AND quantity > 0", location.Zone).
+			Where("row_number > ? OR shelf_number > ?", location.Rows, location.ShelvesPerRow).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: zone %s has stock outside of the new bounds", entities.ErrConflict, location.Zone)
+		}
+		return tx.Save(location).Error
+	})
+}
+
+// удаление пустой зоны
+func (r *LocationPostgres) DeleteLocation(zone string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.StockLevel{}).Where("zone = ? AND quantity > 0", zone).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: zone %s still has stock", entities.ErrConflict, zone)
+		}
+
+		result := tx.Delete(&models.Location{}, "zone = ?", zone)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func getLocation(db *gorm.DB, zone string) (*models.Location, error) {
+	var location models.Location
+	if err := db.First(&location, "zone = ?", zone).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+// проверка ячейки сканирования по раскладке склада
+func checkLocation(tx *gorm.DB, zone string, row, shelf int) (*models.Location, error) {
+	location, err := getLocation(tx, zone)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown zone %q", entities.ErrValidation, zone)
+		}
+		return nil, err
+	}
+	if !location.Contains(row, shelf) {
+		return nil, fmt.Errorf("%w: cell %s-%d-%d is outside of the warehouse layout", entities.ErrValidation, zone, row, shelf)
+	}
+	return location, nil
+}
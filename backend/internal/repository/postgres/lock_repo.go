@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LockPostgres struct {
+	db *gorm.DB
+}
+
+func NewLockPostgres(db *gorm.DB) *LockPostgres {
+	return &LockPostgres{db: db}
+}
+
+// аренда задачи на ttl, удается только если прошлая аренда истекла
+func (r *LockPostgres) TryLock(name, owner string, ttl time.Duration) (bool, error) {
+	result := r.db.Exec(`
+		INSERT INTO job_locks (name, owner, locked_until)
+		VALUES (?, ?, NOW() + make_interval(secs => ?))
+		ON CONFLICT (name) DO UPDATE
+		SET owner = excluded.owner, locked_until = excluded.locked_until
+		WHERE job_locks.locked_until < NOW()`,
+		name, owner, ttl.Seconds())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
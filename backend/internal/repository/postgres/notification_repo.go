@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPostgres struct {
+	db *gorm.DB
+}
+
+func NewNotificationPostgres(db *gorm.DB) *NotificationPostgres {
+	return &NotificationPostgres{db: db}
+}
+
+func (r *NotificationPostgres) ListChannels() ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	err := r.db.Order("id").Find(&channels).Error
+	return channels, err
+}
+
+func (r *NotificationPostgres) GetChannel(id uint) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	if err := r.db.First(&channel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (r *NotificationPostgres) CreateChannel(channel *models.NotificationChannel) error {
+	return r.db.Create(channel).Error
+}
+
+func (r *NotificationPostgres) UpdateChannel(channel *models.NotificationChannel) error {
+	channel.UpdatedAt = time.Now()
+	return r.db.Save(channel).Error
+}
+
+// журнал доставки удаленного канала сохраняется, ожидающие доставки не отправятся
+func (r *NotificationPostgres) DeleteChannel(id uint) error {
+	result := r.db.Delete(&models.NotificationChannel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
+
+// Журнал доставки пишется в транзакции, которая открыла, усилила или эскалировала
+// оповещения, поэтому уведомление не теряется вместе с событием хаба.
+func enqueueDeliveries(tx *gorm.DB, alerts []models.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	var channels []models.NotificationChannel
+	if err := tx.Where("enabled").Order("id").Find(&channels).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []models.NotificationDelivery
+	for _, alert := range alerts {
+		deliveries = append(deliveries, models.PendingDeliveries(channels, alert, now)...)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Omit(clause.Associations).Create(&deliveries).Error
+}
+
+// Доставки, которым пора отправляться, вместе с оповещением и каналом.
+// Следующая попытка сдвигается на lease, поэтому другая реплика не возьмет
+// доставку, пока идет отправка, а после падения реплики доставка повторится.
+func (r *NotificationPostgres) ClaimDeliveries(now time.Time, limit int, lease time.Duration) ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint64
+		err := tx.Model(&models.NotificationDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Order("next_attempt_at").Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		err = tx.Model(&models.NotificationDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Preload("Alert").Preload("Channel").Where("id IN ?", ids).Order("id").Find(&deliveries).Error
+	})
+	return deliveries, err
+}
+
+// результат попытки отправки
+func (r *NotificationPostgres) SaveDelivery(delivery *models.NotificationDelivery) error {
+	delivery.UpdatedAt = time.Now()
+	return r.db.Model(&models.NotificationDelivery{}).Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"last_error":      delivery.LastError,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"sent_at":         delivery.SentAt,
+			"updated_at":      delivery.UpdatedAt,
+		}).Error
+}
+
+// журнал доставки с фильтрами, новые первыми
+func (r *NotificationPostgres) ListDeliveries(query entities.DeliveryQuery) ([]models.NotificationDelivery, int64, error) {
+	var deliveries []models.NotificationDelivery
+	var total int64
+
+	db := r.db.Model(&models.NotificationDelivery{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.ChannelID != nil {
+		db = db.Where("channel_id = ?", *query.ChannelID)
+	}
+	if query.AlertID != nil {
+		db = db.Where("alert_id = ?", *query.AlertID)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Limit(query.Limit).Offset(query.Offset).Order("created_at DESC, id DESC").Find(&deliveries).Error
+	return deliveries, total, err
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductPostgres struct {
+	db *gorm.DB
+}
+
+func NewProductPostgres(db *gorm.DB) *ProductPostgres {
+	return &ProductPostgres{db: db}
+}
+
+// каталог товаров с поиском по id и названию
+func (r *ProductPostgres) ListProducts(query entities.ProductQuery) ([]models.Products, error) {
+	var products []models.Products
+	db := r.db.Model(&models.Products{})
+	if !query.IncludeArchived {
+		db = db.Where("archived_at IS NULL")
+	}
+	if query.Category != "" {
+		db = db.Where("category = ?", query.Category)
+	}
+	if query.Search != "" {
+		pattern := "%" + query.Search + "%"
+		db = db.Where("id ILIKE ? OR name ILIKE ?", pattern, pattern)
+	}
+	err := db.Order("id").Find(&products).Error
+	return products, err
+}
+
+// получение товара по id, включая архивные
+func (r *ProductPostgres) GetProduct(productID string) (*models.Products, error) {
+	var product models.Products
+	if err := r.db.First(&product, "id = ?", productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// добавление товара в каталог
+func (r *ProductPostgres) CreateProduct(product *models.Products) error {
+	var count int64
+	if err := r.db.Model(&models.Products{}).Where("id = ?", product.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: product %s already exists", entities.ErrConflict, product.ID)
+	}
+	return r.db.Create(product).Error
+}
+
+// обновление товара, статусы текущих остатков пересчитываются по новым порогам
+func (r *ProductPostgres) UpdateProduct(product *models.Products) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(product).Error; err != nil {
+			return err
+		}
+		return refreshStockStatus(tx, []string{product.ID})
+	})
+}
+
+// массовая загрузка каталога: новые товары создаются, существующие обновляются
+func (r *ProductPostgres) UpsertProducts(products []models.Products) error {
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "category", "min_stock", "optimal_stock", "lead_time_days"}),
+		}).Omit("archived_at").CreateInBatches(products, 100).Error
+		if err != nil {
+			return err
+		}
+		return refreshStockStatus(tx, ids)
+	})
+}
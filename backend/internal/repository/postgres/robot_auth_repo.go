@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+)
+
+type RobotAuthPostgres struct {
+	db *gorm.DB
+}
+
+func NewRobotAuthPostgres(db *gorm.DB) *RobotAuthPostgres {
+	return &RobotAuthPostgres{db: db}
+}
+
+// сохранение нового ключа робота
+func (r *RobotAuthPostgres) CreateCredential(cred *models.RobotCredential) error {
+	return r.db.Create(cred).Error
+}
+
+// сохранение нового ключа с отзывом всех предыдущих ключей робота
+func (r *RobotAuthPostgres) ReplaceCredentials(cred *models.RobotCredential) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RobotCredential{}).
+			Where("robot_id = ? AND revoked_at IS NULL", cred.RobotID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(cred).Error
+	})
+}
+
+// список ключей робота, включая отозванные
+func (r *RobotAuthPostgres) ListCredentials(robotID string) ([]models.RobotCredential, error) {
+	var creds []models.RobotCredential
+	err := r.db.Where("robot_id = ?", robotID).Order("created_at DESC").Find(&creds).Error
+	return creds, err
+}
+
+// получение действующего ключа по его идентификатору
+func (r *RobotAuthPostgres) GetActiveCredential(keyID string) (*models.RobotCredential, error) {
+	var cred models.RobotCredential
+	err := r.db.Where("key_id = ? AND revoked_at IS NULL", keyID).First(&cred).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, err
+	}
+	return &cred, nil
+}
+
+// отзыв ключа робота
+func (r *RobotAuthPostgres) RevokeCredential(robotID, keyID string) error {
+	result := r.db.Model(&models.RobotCredential{}).
+		Where("robot_id = ? AND key_id = ? AND revoked_at IS NULL", robotID, keyID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrNotFound
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -9,92 +10,242 @@ import (
 	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
 	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RobotPostgres struct {
-	db *gorm.DB
+	db       *gorm.DB
+	detector models.AnomalyDetector
 }
 
-func NewRobotPostgres(db *gorm.DB) *RobotPostgres {
-	return &RobotPostgres{db: db}
+func NewRobotPostgres(db *gorm.DB, detector models.AnomalyDetector) *RobotPostgres {
+	return &RobotPostgres{db: db, detector: detector}
 }
 
-func (r *RobotPostgres) AddData(data entities.RobotsData) error { // обработать ошибки типа неправ знач в поле
-	tx := r.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+// сохранение пачки сообщений робота в одной транзакции.
+// Ошибка отдельного сообщения откатывает только его, остальные сохраняются.
+// Повторное сообщение с тем же client message id не записывается.
+func (r *RobotPostgres) AddData(items []entities.IngestItem) ([]entities.IngestResult, error) {
+	results := make([]entities.IngestResult, len(items))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i, item := range items {
+			savepoint := fmt.Sprintf("scan_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+
+			result, err := addData(tx, r.detector, item.Data, item.MessageID)
+			if err != nil {
+				if rbErr := tx.RollbackTo(savepoint).Error; rbErr != nil {
+					return rbErr
+				}
+				results[i] = entities.IngestResult{Error: err.Error(), Err: err}
+				continue
+			}
+			results[i] = *result
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func addData(tx *gorm.DB, detector models.AnomalyDetector, data entities.RobotsData, messageID string) (*entities.IngestResult, error) { // обработать ошибки типа неправ знач в поле
+	var count int64
+	if tx.Model(&models.Robots{}).Where("id = ?", data.RobotId).Count(&count); count == 0 {
+		return nil, fmt.Errorf("%w: robot does not exist", entities.ErrValidation)
+	}
+
+	// регистрация сообщения, при конфликте возвращается ранее выданный id
+	message := models.RobotMessage{
+		ID:              messageID,
+		RobotID:         data.RobotId,
+		ClientMessageID: data.MessageID,
+		ReceivedAt:      time.Now(),
+	}
+	inserted := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "robot_id"}, {Name: "client_message_id"}},
+		DoNothing: true,
+	}).Create(&message)
+	if inserted.Error != nil {
+		return nil, inserted.Error
+	}
+	if inserted.RowsAffected == 0 {
+		var existing models.RobotMessage
+		if err := tx.Where("robot_id = ? AND client_message_id = ?", data.RobotId, data.MessageID).First(&existing).Error; err != nil {
+			return nil, err
 		}
-	}()
-	
+		return &entities.IngestResult{MessageID: existing.ID, Duplicate: true}, nil
+	}
+
+	// ячейка сканирования должна существовать в раскладке склада
+	location, err := checkLocation(tx, data.Location.Zone, data.Location.Row, data.Location.Shelf)
+	if err != nil {
+		return nil, err
+	}
+
 	// обработка результатов сканирования роботов
+	statuses := make([]string, 0, len(data.ScanResults))
+	anomalies := make([]bool, 0, len(data.ScanResults))
+	histories := make([]models.InventoryHistory, 0, len(data.ScanResults))
 	for _, scanResult := range data.ScanResults {
-		//проверка foreignkey и id_robot
-		var count int64
-		if tx.Model(&models.Products{}).Where("id = ?", scanResult.ProductId).Count(&count); count == 0 {
-			tx.Rollback()
-			return fmt.Errorf("product does not exist")
+		//проверка foreignkey, статус остатка считается по порогам товара
+		var product models.Products
+		if err := tx.Where("id = ?", scanResult.ProductId).First(&product).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: product %s does not exist", entities.ErrValidation, scanResult.ProductId)
+			}
+			return nil, err
+		}
+		// архивный товар снят с учета, его сканирования не меняют остатки
+		if product.ArchivedAt != nil {
+			return nil, fmt.Errorf("%w: product %s is archived", entities.ErrValidation, scanResult.ProductId)
 		}
-		if tx.Model(&models.Robots{}).Where("id = ?", data.RobotId).Count(&count); count == 0 {
-			tx.Rollback()
-			return fmt.Errorf("robot does not exist")
+		if !location.Fits(scanResult.Quantity) {
+			return nil, fmt.Errorf("%w: quantity %d of %s exceeds shelf capacity %d", entities.ErrValidation, scanResult.Quantity, scanResult.ProductId, location.ShelfCapacity)
 		}
+		status := product.StockStatus(scanResult.Quantity)
+		statuses = append(statuses, status)
 
 		// построение экземпляра структуры истории инвентаризации
 		var inventoryHistory models.InventoryHistory = models.InventoryHistory{
-			RobotID:     data.RobotId,
-			ProductID:   scanResult.ProductId,
-			Quantity:    scanResult.Quantity,
-			Zone:        data.Location.Zone,
-			RowNumber:   data.Location.Row,
-			ShelfNumber: data.Location.Shelf,
-			Status:      scanResult.Status,
-			ScannedAt:   data.Timestamp,
-			CreatedAt:   time.Now(),
+			RobotID:        data.RobotId,
+			ProductID:      scanResult.ProductId,
+			Quantity:       scanResult.Quantity,
+			Zone:           data.Location.Zone,
+			RowNumber:      data.Location.Row,
+			ShelfNumber:    data.Location.Shelf,
+			Status:         status,
+			ReportedStatus: scanResult.Status,
+			ScannedAt:      data.Timestamp,
+			CreatedAt:      time.Now(),
+			MessageID:      &message.ID,
 		}
+		if err := markAnomaly(tx, detector, &inventoryHistory); err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, !inventoryHistory.Counted())
 
 		if err := tx.Create(&inventoryHistory).Error; err != nil {
-			tx.Rollback()
-			return err
+			return nil, err
 		}
+		histories = append(histories, inventoryHistory)
+	}
+	if err := upsertStockLevels(tx, histories); err != nil {
+		return nil, err
+	}
+	subjects, err := scanSubjects(tx, histories)
+	if err != nil {
+		return nil, err
 	}
 
 	// парсинг информации о роботе
 	nextPoint := strings.Split(data.NextCheckpoint, "-")
+	if len(nextPoint) != 3 {
+		return nil, fmt.Errorf("%w: invalid next checkpoint %q", entities.ErrValidation, data.NextCheckpoint)
+	}
 	row, err1 := strconv.Atoi(nextPoint[1])
 	shelf, err2 := strconv.Atoi(nextPoint[2])
-	if err1 != nil {
-		tx.Rollback()
-		return err1
-	}
-	if err2 != nil {
-		tx.Rollback()
-		return err2
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("%w: invalid next checkpoint %q", entities.ErrValidation, data.NextCheckpoint)
 	}
 
-	// получение робота по id и обновление его полей 
+	// получение робота по id и обновление его полей, старые сообщения из буфера робота состояние не меняют
 	var robot models.Robots
 	if err := tx.Where("id = ?", data.RobotId).First(&robot).Error; err != nil {
-		tx.Rollback()
-		return err
+		return nil, err
 	}
-	robot.BatteryLevel = data.BatteryLevel
-	robot.CurrentZone = nextPoint[0]
-	robot.CurrentRow = row
-	robot.CurrentShelf = shelf
-	if err := tx.Save(&robot).Error; err != nil {
-		tx.Rollback()
-		return err
+	if !data.Timestamp.Before(robot.LastUpdate) {
+		robot.BatteryLevel = data.BatteryLevel
+		robot.CurrentZone = nextPoint[0]
+		robot.CurrentRow = row
+		robot.CurrentShelf = shelf
+		robot.LastUpdate = data.Timestamp
+		if err := tx.Save(&robot).Error; err != nil {
+			return nil, err
+		}
+		robots, err := robotSubjects(tx, []string{robot.ID})
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, robots...)
 	}
 
-	return tx.Commit().Error
+	alerts, err := evaluateRules(tx, subjects)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.IngestResult{MessageID: message.ID, Statuses: statuses, Anomalies: anomalies, Alerts: alerts}, nil
 }
 
+// списанные роботы не могут отправлять данные
 func (r *RobotPostgres) CheckId(robotID string) bool {
 	var count int64
-	err := r.db.Model(&models.Robots{}).Where("id = ?", robotID).Count(&count).Error
+	err := r.db.Model(&models.Robots{}).
+		Where("id = ? AND status <> ?", robotID, models.RobotStatusDecommissioned).
+		Count(&count).Error
 	if err != nil {
 		return false
 	}
 	return count > 0
 }
+
+// получение всех роботов реестра
+func (r *RobotPostgres) ListRobots() ([]models.Robots, error) {
+	var robots []models.Robots
+	err := r.db.Order("id").Find(&robots).Error
+	return robots, err
+}
+
+// получение робота по id
+func (r *RobotPostgres) GetRobot(robotID string) (*models.Robots, error) {
+	var robot models.Robots
+	if err := r.db.First(&robot, "id = ?", robotID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrNotFound
+		}
+		return nil, err
+	}
+	return &robot, nil
+}
+
+// регистрация нового робота
+func (r *RobotPostgres) CreateRobot(robot *models.Robots) error {
+	var count int64
+	if err := r.db.Model(&models.Robots{}).Where("id = ?", robot.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: robot %s already registered", entities.ErrConflict, robot.ID)
+	}
+	return r.db.Create(robot).Error
+}
+
+// обновление данных робота
+func (r *RobotPostgres) UpdateRobot(robot *models.Robots) error {
+	return r.db.Save(robot).Error
+}
+
+// удаление робота, у которого нет истории сканирований
+func (r *RobotPostgres) DeleteRobot(robotID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.InventoryHistory{}).Where("robot_id = ?", robotID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: robot %s has scan history, decommission it instead", entities.ErrConflict, robotID)
+		}
+
+		result := tx.Delete(&models.Robots{}, "id = ?", robotID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrNotFound
+		}
+		return nil
+	})
+}
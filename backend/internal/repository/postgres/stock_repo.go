@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// обновление текущих остатков по сканированиям, вызывается внутри транзакции записи истории.
+// Более старое сканирование (например, из буфера робота) не перезаписывает свежий остаток.
+func upsertStockLevels(tx *gorm.DB, histories []models.InventoryHistory) error {
+	type cell struct {
+		product, zone string
+		row, shelf    int
+	}
+
+	latest := make(map[cell]int)
+	levels := make([]models.StockLevel, 0, len(histories))
+	for _, h := range histories {
+		// выброс попадет в остатки только после подтверждения
+		if !h.Counted() {
+			continue
+		}
+		key := cell{h.ProductID, h.Zone, h.RowNumber, h.ShelfNumber}
+		level := models.StockLevel{
+			ProductID:   h.ProductID,
+			Zone:        h.Zone,
+			RowNumber:   h.RowNumber,
+			ShelfNumber: h.ShelfNumber,
+			Quantity:    h.Quantity,
+			Status:      h.Status,
+			ScannedAt:   h.ScannedAt,
+			UpdatedAt:   time.Now(),
+		}
+
+		// одна ячейка может встретиться в пачке несколько раз, postgres не обновит строку дважды за запрос
+		if i, ok := latest[key]; ok {
+			if !h.ScannedAt.Before(levels[i].ScannedAt) {
+				levels[i] = level
+			}
+			continue
+		}
+		latest[key] = len(levels)
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "zone"}, {Name: "row_number"}, {Name: "shelf_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "status", "scanned_at", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "stock_levels.scanned_at <= excluded.scanned_at"},
+		}},
+	}).CreateInBatches(levels, 100).Error
+}
+
+// текущие остатки с фильтром по товару и зоне
+func (r *InventoryRepo) GetStockLevels(productID, zone string) ([]models.StockLevel, error) {
+	var levels []models.StockLevel
+	query := r.db.Preload("Product")
+	if productID != "" {
+		query = query.Where("product_id = ?", productID)
+	}
+	if zone != "" {
+		query = query.Where("zone = ?", zone)
+	}
+	err := query.Order("product_id, zone, row_number, shelf_number").Find(&levels).Error
+	return levels, err
+}
+
+// пересчет статусов текущих остатков после изменения порогов товаров,
+// правило то же, что в models.Products.StockStatus
+func refreshStockStatus(tx *gorm.DB, productIDs []string) error {
+	return tx.Exec(`
+		UPDATE stock_levels s SET status = CASE
+			WHEN s.quantity <= p.min_stock THEN ?
+			WHEN s.quantity <= p.optimal_stock / 2 THEN ?
+			ELSE ?
+		END
+		FROM products p
+		WHERE p.id = s.product_id AND p.id IN ?`,
+		models.StockCritical, models.StockLow, models.StockOK, productIDs,
+	).Error
+}
@@ -51,6 +51,11 @@ func (r *RedisClient) Exists(key string) (bool, error) {
 	return result > 0, err
 }
 
+// установка ключа, только если его еще нет
+func (r *RedisClient) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(r.ctx, key, value, expiration).Result()
+}
+
 func (r *RedisClient) Close() error {
 	return r.client.Close()
 }
@@ -13,21 +13,37 @@ import (
 type Authorization interface {
 	CreateUser(models.Users) (uint, error)
 	GetUser(string, string) (*models.Users, error)
-}
-
-type WebsocketDashBoard interface {
-	InventoryAlertScanned(*entities.InventoryAlert, time.Time, string) error
-	InventoryAlertPredict(*entities.InventoryAlert, entities.Predictions) error
+	GetUserByID(uint) (*models.Users, error)
+	ListUsers() ([]models.Users, error)
+	UpdateUserRole(uint, string) error
 }
 
 type Inventory interface {
 	ImportInventoryHistories(histories []models.InventoryHistory) error
 	GetInventoryHistoryByProductIDs(productIDs []string) ([]models.InventoryHistory, error)
 	GetInventoryHistoryByScanIDs(scanIDs []string) ([]models.InventoryHistory, error)
-	GetProductByID(productID string) error
-	CreateProduct(product *models.Products) error
-	UpdateProduct(product *models.Products) error
+	GetProductsByIDs(productIDs []string) ([]models.Products, error)
 	GetHistory(from, to, zone, status string, limit, offset int) ([]models.InventoryHistory, int64, error)
+	GetRecentScans(entities.RecentScansQuery) ([]models.InventoryHistory, error)
+	GetStockLevels(productID, zone string) ([]models.StockLevel, error)
+	ListAnomalies(status string, limit, offset int) ([]models.InventoryHistory, int64, error)
+	ResolveAnomaly(id uint, status string, userID uint) (*models.InventoryHistory, []models.Alert, error)
+}
+
+type Product interface {
+	ListProducts(entities.ProductQuery) ([]models.Products, error)
+	GetProduct(string) (*models.Products, error)
+	CreateProduct(*models.Products) error
+	UpdateProduct(*models.Products) error
+	UpsertProducts([]models.Products) error
+}
+
+type Location interface {
+	ListLocations() ([]models.Location, error)
+	GetLocation(string) (*models.Location, error)
+	CreateLocation(*models.Location) error
+	UpdateLocation(*models.Location) error
+	DeleteLocation(string) error
 }
 
 type DashBoard interface {
@@ -35,13 +51,71 @@ type DashBoard interface {
 }
 
 type Robot interface {
-	AddData(entities.RobotsData) error
+	AddData([]entities.IngestItem) ([]entities.IngestResult, error)
 	CheckId(string) bool
+	ListRobots() ([]models.Robots, error)
+	GetRobot(string) (*models.Robots, error)
+	CreateRobot(*models.Robots) error
+	UpdateRobot(*models.Robots) error
+	DeleteRobot(string) error
+}
+
+type RobotAuth interface {
+	CreateCredential(*models.RobotCredential) error
+	ReplaceCredentials(*models.RobotCredential) error
+	ListCredentials(string) ([]models.RobotCredential, error)
+	GetActiveCredential(string) (*models.RobotCredential, error)
+	RevokeCredential(string, string) error
 }
 
 type AI interface {
-	AIRequest(entities.AIRequest) ([]models.InventoryHistory, error)
-	AIResponse(entities.AIResponse) error
+	AIRequest(entities.AIRequest, entities.HistoryWindow) ([]models.InventoryHistory, error)
+	AIResponse(entities.AIResponse) ([]models.Alert, error)
+	ListPredictions(entities.PredictionQuery) ([]models.AiPrediction, int64, error)
+	LatestPredictions(entities.PredictionQuery) ([]models.AiPrediction, error)
+}
+
+// оценка точности сохраненных прогнозов
+type Accuracy interface {
+	PendingPredictions() ([]models.AiPrediction, error)
+	FirstStockout(productID string, after time.Time) (*time.Time, error)
+	SaveOutcome(*models.AiPrediction) error
+	EvaluatedPredictions(from, to time.Time) ([]models.AiPrediction, error)
+}
+
+// оповещения об остатках
+type Alert interface {
+	ListAlerts(entities.AlertQuery) ([]models.Alert, int64, error)
+	GetAlert(id uint) (*models.Alert, error)
+	UpdateAlert(alert *models.Alert, fromStatus string) error
+	EscalateAlerts(now time.Time, after time.Duration) ([]models.Alert, error)
+}
+
+// правила оповещений и их проверка по текущему состоянию склада
+type AlertRule interface {
+	ListRules() ([]models.AlertRule, error)
+	GetRule(id uint) (*models.AlertRule, error)
+	CreateRule(*models.AlertRule) error
+	UpdateRule(*models.AlertRule) error
+	DeleteRule(id uint) error
+	EvaluateRules(now time.Time) ([]models.Alert, error)
+}
+
+// каналы уведомлений и журнал доставки
+type Notification interface {
+	ListChannels() ([]models.NotificationChannel, error)
+	GetChannel(id uint) (*models.NotificationChannel, error)
+	CreateChannel(*models.NotificationChannel) error
+	UpdateChannel(*models.NotificationChannel) error
+	DeleteChannel(id uint) error
+	ClaimDeliveries(now time.Time, limit int, lease time.Duration) ([]models.NotificationDelivery, error)
+	SaveDelivery(*models.NotificationDelivery) error
+	ListDeliveries(entities.DeliveryQuery) ([]models.NotificationDelivery, int64, error)
+}
+
+// аренда фоновых задач между репликами
+type Lock interface {
+	TryLock(name, owner string, ttl time.Duration) (bool, error)
 }
 
 // Redis интерфейс
@@ -50,6 +124,7 @@ type Redis interface {
 	Get(key string) (string, error)
 	Delete(key string) error
 	Exists(key string) (bool, error)
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
 	Publish(channel string, message interface{}) error
 	Subscribe(channel string) *redis.PubSub
 
@@ -65,23 +140,37 @@ type Redis interface {
 
 type Repository struct {
 	Robot
+	RobotAuth
 	Inventory
+	Product
+	Location
 	Authorization
-	WebsocketDashBoard
 	DashBoard
 	AI
+	Accuracy
+	Alert
+	AlertRule
+	Notification
+	Lock
 	Redis Redis
 }
 
 func NewRepository(db *gorm.DB, redisClient Redis) *Repository {
 	return &Repository{
-		Authorization:      postgres.NewAuthPostgres(db),
-		Robot:              postgres.NewRobotPostgres(db),
-		WebsocketDashBoard: postgres.NewWebsocketDashBoardPostgres(db),
-		Inventory:          postgres.NewInventoryRepo(db),
-		DashBoard:          postgres.NewDashPostgres(db),
-		AI:                 postgres.NewAIPostgres(db),
-		Redis:              redisClient,
+		Authorization: postgres.NewAuthPostgres(db),
+		Robot:         postgres.NewRobotPostgres(db, AnomalyDetectorFromEnv()),
+		RobotAuth:     postgres.NewRobotAuthPostgres(db),
+		Inventory:     postgres.NewInventoryRepo(db),
+		Product:       postgres.NewProductPostgres(db),
+		Location:      postgres.NewLocationPostgres(db),
+		DashBoard:     postgres.NewDashPostgres(db),
+		AI:            postgres.NewAIPostgres(db),
+		Accuracy:      postgres.NewAccuracyPostgres(db),
+		Alert:         postgres.NewAlertPostgres(db),
+		AlertRule:     postgres.NewAlertRulePostgres(db),
+		Notification:  postgres.NewNotificationPostgres(db),
+		Lock:          postgres.NewLockPostgres(db),
+		Redis:         redisClient,
 	}
 }
 
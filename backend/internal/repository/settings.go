@@ -2,8 +2,12 @@ package repository
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -21,3 +25,31 @@ func InitBD() (*gorm.DB, error) {
 
 	return my_db, nil
 }
+
+// поиск выбросов в сканированиях роботов, настройки из ANOMALY_Z_THRESHOLD (0 - выключен),
+// ANOMALY_WINDOW и ANOMALY_MIN_CHANGE
+func AnomalyDetectorFromEnv() models.AnomalyDetector {
+	detector := models.DefaultAnomalyDetector()
+	if value, err := config.Get("ANOMALY_Z_THRESHOLD"); err == nil {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 0 {
+			detector.Threshold = parsed
+		} else {
+			logrus.Warnf("invalid ANOMALY_Z_THRESHOLD %q, using %.1f", value, detector.Threshold)
+		}
+	}
+	if value, err := config.Get("ANOMALY_WINDOW"); err == nil {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			detector.Window = parsed
+		} else {
+			logrus.Warnf("invalid ANOMALY_WINDOW %q, using %s", value, detector.Window)
+		}
+	}
+	if value, err := config.Get("ANOMALY_MIN_CHANGE"); err == nil {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			detector.MinChange = parsed
+		} else {
+			logrus.Warnf("invalid ANOMALY_MIN_CHANGE %q, using %d", value, detector.MinChange)
+		}
+	}
+	return detector
+}
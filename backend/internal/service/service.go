@@ -1,7 +1,10 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
 	"github.com/Senpa1k/Smart_Warehouse/internal/models"
@@ -10,12 +13,15 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-var made = make(chan interface{}, 100) // до 100 запросов одновременно +- на время обработки запроса
+const dashboardEventBuffer = 100 // до 100 событий в очереди каждого дашборда
 
 type Authorization interface {
 	CreateUser(models.Users) (uint, error)
 	GetUser(string, string) (string, *models.Users, error)
 	ParseToken(string) (uint, error)
+	GetUserRole(uint) (string, error)
+	ListUsers() ([]models.Users, error)
+	UpdateUserRole(uint, string) error
 }
 
 type WebsocketDashBoard interface {
@@ -26,6 +32,27 @@ type Inventory interface {
 	ImportCSV(csvData io.Reader) (*entities.ImportResult, error)
 	ExportExcel(productIDs []string) ([]byte, error)
 	GetHistory(from, to, zone, status string, limit, offset int) (*entities.HistoryResponse, error)
+	GetStock(productID, zone string) (*entities.StockResponse, error)
+	ListAnomalies(entities.AnomalyQuery) (*entities.HistoryResponse, error)
+	ResolveAnomaly(id uint, confirm bool, userID uint) (*models.InventoryHistory, error)
+}
+
+type Product interface {
+	ListProducts(entities.ProductQuery) ([]models.Products, error)
+	GetProduct(string) (*models.Products, error)
+	CreateProduct(entities.ProductInput) (*models.Products, error)
+	UpdateProduct(string, entities.ProductUpdate) (*models.Products, error)
+	ArchiveProduct(string) (*models.Products, error)
+	UnarchiveProduct(string) (*models.Products, error)
+	ImportProductsCSV(io.Reader) (*entities.ImportResult, error)
+}
+
+type Location interface {
+	ListLocations() ([]models.Location, error)
+	GetLocation(string) (*models.Location, error)
+	CreateLocation(entities.LocationInput) (*models.Location, error)
+	UpdateLocation(string, entities.LocationUpdate) (*models.Location, error)
+	DeleteLocation(string) error
 }
 
 type DashBoard interface {
@@ -33,32 +60,141 @@ type DashBoard interface {
 }
 
 type Robot interface {
-	AddData(entities.RobotsData) error
+	AddData(entities.RobotsData) (*entities.IngestResult, error)
+	AddBatch([]entities.RobotsData) []entities.IngestResult
 	CheckId(string) bool
+	ListRobots() ([]models.Robots, error)
+	GetRobot(string) (*models.Robots, error)
+	RegisterRobot(entities.RobotRegistration) (*models.Robots, error)
+	UpdateRobot(string, entities.RobotUpdate) (*models.Robots, error)
+	DecommissionRobot(string) (*models.Robots, error)
+	DeleteRobot(string) error
+}
+
+type RobotAuth interface {
+	IssueKey(string) (*entities.RobotKey, error)
+	RotateKeys(string) (*entities.RobotKey, error)
+	ListKeys(string) ([]models.RobotCredential, error)
+	RevokeKey(string, string) error
+	VerifyRequest(entities.RobotSignedRequest) (string, error)
+	LegacyAuthAllowed() bool
 }
 
 type AI interface {
 	Predict(entities.AIRequest) (*entities.AIResponse, error)
+	ListPredictions(entities.PredictionQuery) (*entities.PredictionsResponse, error)
+	LatestPredictions(entities.PredictionQuery) ([]models.AiPrediction, error)
+}
+
+// ответы на вопросы операторов по данным склада
+type Assistant interface {
+	Ask(string) (*entities.AskResponse, error)
+}
+
+// оценка точности прогнозов по фактическим остаткам
+type Accuracy interface {
+	Run(context.Context)
+	EvaluatePredictions(time.Time) (int, error)
+	Report(entities.AccuracyQuery) (*entities.AccuracyReport, error)
+}
+
+// оповещения об остатках и работа с ними
+type Alert interface {
+	ListAlerts(entities.AlertQuery) (*entities.AlertsResponse, error)
+	GetAlert(id uint) (*models.Alert, error)
+	AcknowledgeAlert(id, userID uint, input entities.AlertAcknowledge) (*models.Alert, error)
+	AssignAlert(id uint, input entities.AlertAssign) (*models.Alert, error)
+	ResolveAlert(id, userID uint, input entities.AlertResolve) (*models.Alert, error)
+}
+
+// правила оповещений и их проверка по таймеру
+type AlertRule interface {
+	Run(context.Context)
+	EvaluateRules(time.Time) (int, error)
+	ListRules() ([]models.AlertRule, error)
+	GetRule(id uint) (*models.AlertRule, error)
+	CreateRule(entities.AlertRuleInput) (*models.AlertRule, error)
+	UpdateRule(id uint, input entities.AlertRuleUpdate) (*models.AlertRule, error)
+	DeleteRule(id uint) error
+}
+
+// эскалация неподтвержденных критических оповещений по таймеру
+type AlertEscalation interface {
+	Run(context.Context)
+	Escalate(time.Time) (int, error)
+}
+
+// уведомления об оповещениях во внешние каналы и журнал доставки
+type Notification interface {
+	Run(context.Context)
+	ListChannels() ([]models.NotificationChannel, error)
+	GetChannel(id uint) (*models.NotificationChannel, error)
+	CreateChannel(entities.NotificationChannelInput) (*models.NotificationChannel, error)
+	UpdateChannel(id uint, input entities.NotificationChannelUpdate) (*models.NotificationChannel, error)
+	DeleteChannel(id uint) error
+	TestChannel(ctx context.Context, id uint) (*entities.ChannelTestResult, error)
+	ListDeliveries(entities.DeliveryQuery) (*entities.DeliveriesResponse, error)
+}
+
+// регулярные прогнозы по расписанию
+type ForecastScheduler interface {
+	Run(context.Context)
 }
 
 type Service struct {
 	Robot
+	RobotAuth
 	Inventory
+	Product
+	Location
 	Authorization
 	WebsocketDashBoard
 	DashBoard
 	AI
+	Assistant
+	Accuracy
+	Alert
+	AlertRule
+	AlertEscalation
+	Notification
+	ForecastScheduler
 	Redis repository.Redis
 }
 
-func NewService(repos *repository.Repository) *Service {
+func NewService(repos *repository.Repository) (*Service, error) {
+	// каждый подключенный дашборд получает все события, медленные теряют лишние
+	hub := services.NewEventHub(dashboardEventBuffer, services.DropEvent)
+
+	// поставщик прогнозов выбирается конфигурацией, обработчики и репозитории от него не зависят;
+	// опечатка в AI_SERVICE, AI_FALLBACK или AI_SCHEDULE останавливает запуск, а не меняет поставщика
+	forecaster, err := services.NewForecasterFromConfig(repos.Product)
+	if err != nil {
+		return nil, fmt.Errorf("forecasting provider: %w", err)
+	}
+
+	ai := services.NewAIService(repos.AI, forecaster, hub, repos.Redis)
+	scheduler, err := services.NewForecastSchedulerFromConfig(ai, repos.Lock, repos.Redis)
+	if err != nil {
+		return nil, fmt.Errorf("forecast schedule: %w", err)
+	}
+
 	return &Service{
 		Authorization:      services.NewAuthService(repos.Authorization),
-		Robot:              services.NewRobotService(repos.Robot, made, repos.Redis),
-		WebsocketDashBoard: services.NewWebsocketDashBoard(repos.WebsocketDashBoard, made),
-		Inventory:          services.NewInventoryService(repos.Inventory, repos.Redis),
+		Robot:              services.NewRobotService(repos.Robot, hub, repos.Redis),
+		RobotAuth:          services.NewRobotAuthService(repos.RobotAuth, repos.Robot, repos.Redis),
+		WebsocketDashBoard: services.NewWebsocketDashBoard(hub, repos.Redis),
+		Inventory:          services.NewInventoryService(repos.Inventory, repos.Location, hub, repos.Redis),
+		Product:            services.NewProductService(repos.Product),
+		Location:           services.NewLocationService(repos.Location),
 		DashBoard:          services.NewDashService(repos.DashBoard, repos.Redis),
-		AI:                 services.NewAIService(repos.AI, made, repos.Redis),
+		AI:                 ai,
+		Assistant:          services.NewAskService(repos.Inventory, repos.Product, repos.Location, forecaster),
+		Accuracy:           services.NewAccuracyService(repos.Accuracy, repos.Lock, repos.Redis),
+		Alert:              services.NewAlertService(repos.Alert, repos.Authorization),
+		AlertRule:          services.NewAlertRuleService(repos.AlertRule, hub, repos.Lock, repos.Redis),
+		AlertEscalation:    services.NewAlertEscalationService(repos.Alert, hub, repos.Lock, repos.Redis),
+		Notification:       services.NewNotificationServiceFromConfig(repos.Notification, hub),
+		ForecastScheduler:  scheduler,
 		Redis:              repos.Redis,
-	}
+	}, nil
 }
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	accuracyGraceDays        = 7 // сколько ждать нехватку после прогнозного срока
+	defaultAccuracyTolerance = 2
+	defaultAccuracyPeriod    = 90 * 24 * time.Hour
+	defaultAccuracyInterval  = time.Hour
+	unknownProvider          = "unknown"
+	uncategorized            = "uncategorized"
+	accuracyLock             = "lock:ai:accuracy"
+)
+
+type AccuracyService struct {
+	repo     repository.Accuracy
+	locks    repository.Lock
+	redis    repository.Redis
+	interval time.Duration
+	owner    string
+}
+
+func NewAccuracyService(repo repository.Accuracy, locks repository.Lock, redis repository.Redis) *AccuracyService {
+	interval := defaultAccuracyInterval
+	if value, err := config.Get("AI_ACCURACY_INTERVAL"); err == nil {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			interval = parsed
+		} else {
+			logrus.Warnf("invalid AI_ACCURACY_INTERVAL %q, using %s", value, defaultAccuracyInterval)
+		}
+	}
+	return &AccuracyService{
+		repo:     repo,
+		locks:    locks,
+		redis:    redis,
+		interval: interval,
+		owner:    leaseOwner(),
+	}
+}
+
+// периодическая оценка прогнозов до отмены контекста, на каждом тике - одна реплика
+func (s *AccuracyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if acquireLease(s.redis, s.locks, accuracyLock, s.owner, s.interval*9/10) {
+			if n, err := s.EvaluatePredictions(time.Now()); err != nil {
+				logrus.Errorf("forecast accuracy: %v", err)
+			} else if n > 0 {
+				logrus.Infof("forecast accuracy: evaluated %d predictions", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// сопоставление прогнозов с фактом из истории сканирований.
+// Прогноз оценивается, как только остаток опустился до min_stock, либо
+// когда прошел прогнозный срок с запасом и нехватки так и не было.
+// Во втором случае факт неизвестен (цензурирован), actual_days остается пустым.
+func (s *AccuracyService) EvaluatePredictions(now time.Time) (int, error) {
+	pending, err := s.repo.PendingPredictions()
+	if err != nil {
+		return 0, err
+	}
+
+	evaluated := 0
+	for i := range pending {
+		prediction := &pending[i]
+
+		stockout, err := s.repo.FirstStockout(prediction.ProductID, prediction.CreatedAt)
+		if err != nil {
+			return evaluated, fmt.Errorf("stockout of %s: %w", prediction.ProductID, err)
+		}
+
+		var actual *int
+		if stockout != nil {
+			days := int(math.Round(stockout.Sub(prediction.CreatedAt).Hours() / 24))
+			actual = &days
+		} else if now.Before(prediction.CreatedAt.AddDate(0, 0, prediction.DaysUntilStockout+accuracyGraceDays)) {
+			continue // нехватка еще может наступить
+		}
+
+		evaluatedAt := now
+		prediction.ActualStockoutAt = stockout
+		prediction.ActualDays = actual
+		prediction.EvaluatedAt = &evaluatedAt
+		if err := s.repo.SaveOutcome(prediction); err != nil {
+			return evaluated, err
+		}
+		evaluated++
+	}
+
+	return evaluated, nil
+}
+
+// отчет о точности оцененных прогнозов по поставщикам, категориям и товарам
+func (s *AccuracyService) Report(query entities.AccuracyQuery) (*entities.AccuracyReport, error) {
+	to := time.Now()
+	from := to.Add(-defaultAccuracyPeriod)
+	if query.From != "" {
+		parsed, err := time.Parse("2006-01-02", query.From)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid from date %q", entities.ErrValidation, query.From)
+		}
+		from = parsed
+	}
+	if query.To != "" {
+		parsed, err := time.Parse("2006-01-02", query.To)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid to date %q", entities.ErrValidation, query.To)
+		}
+		to = parsed.AddDate(0, 0, 1) // включительно
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", entities.ErrValidation)
+	}
+
+	tolerance := defaultAccuracyTolerance
+	if query.Tolerance != nil {
+		if *query.Tolerance < 0 {
+			return nil, fmt.Errorf("%w: tolerance must not be negative", entities.ErrValidation)
+		}
+		tolerance = *query.Tolerance
+	}
+
+	predictions, err := s.repo.EvaluatedPredictions(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	overall := &accuracyGroup{}
+	providers := map[string]*accuracyGroup{}
+	categories := map[string]*accuracyGroup{}
+	products := map[string]*accuracyGroup{}
+	for _, p := range predictions {
+		provider := p.Provider
+		if provider == "" {
+			provider = unknownProvider
+		}
+		category := p.AIPredictionProduct.Category
+		if category == "" {
+			category = uncategorized
+		}
+
+		overall.add(p, tolerance)
+		groupFor(providers, provider, "").add(p, tolerance)
+		groupFor(categories, category, "").add(p, tolerance)
+		groupFor(products, p.ProductID, p.AIPredictionProduct.Name).add(p, tolerance)
+	}
+
+	return &entities.AccuracyReport{
+		From:          from,
+		To:            to,
+		ToleranceDays: tolerance,
+		Overall:       overall.metrics("all"),
+		Providers:     groupMetrics(providers),
+		Categories:    groupMetrics(categories),
+		Products:      groupMetrics(products),
+	}, nil
+}
+
+// накопитель ошибок группы прогнозов, ошибки считаются только по наблюдавшимся нехваткам
+type accuracyGroup struct {
+	name      string
+	count     int
+	observed  int
+	censored  int
+	hits      int
+	absErrors float64
+	errors    float64
+}
+
+func (g *accuracyGroup) add(p models.AiPrediction, tolerance int) {
+	g.count++
+	if p.ActualStockoutAt == nil || p.ActualDays == nil {
+		g.censored++ // нехватки не было, ошибку прогноза не измерить
+		return
+	}
+	g.observed++
+
+	diff := p.DaysUntilStockout - *p.ActualDays
+	if abs(diff) <= tolerance {
+		g.hits++
+	}
+	g.absErrors += float64(abs(diff))
+	g.errors += float64(diff)
+}
+
+func (g *accuracyGroup) metrics(key string) entities.AccuracyMetrics {
+	m := entities.AccuracyMetrics{Key: key, Name: g.name, Evaluated: g.count, Observed: g.observed, Censored: g.censored}
+	if g.observed > 0 {
+		n := float64(g.observed)
+		m.MAE = round2(g.absErrors / n)
+		m.Bias = round2(g.errors / n)
+		m.HitRate = round2(float64(g.hits) / n)
+	}
+	return m
+}
+
+func groupFor(groups map[string]*accuracyGroup, key, name string) *accuracyGroup {
+	g, ok := groups[key]
+	if !ok {
+		g = &accuracyGroup{name: name}
+		groups[key] = g
+	}
+	return g
+}
+
+func groupMetrics(groups map[string]*accuracyGroup) []entities.AccuracyMetrics {
+	result := make([]entities.AccuracyMetrics, 0, len(groups))
+	for key, g := range groups {
+		result = append(result, g.metrics(key))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
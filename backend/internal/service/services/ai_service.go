@@ -3,17 +3,12 @@ package services
 import (
 	"context"
 	"crypto/md5"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"log"
-	"net/http"
-	"strconv"
 	"time"
 
-	"github.com/Role1776/gigago"
-	"github.com/Senpa1k/Smart_Warehouse/internal/config"
 	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
 	"github.com/sirupsen/logrus"
 )
@@ -21,52 +16,24 @@ import (
 type AIService struct {
 	repo       repository.AI
 	redis      repository.Redis
-	made       chan<- interface{}
-	httpClient *http.Client
+	events     EventPublisher
+	forecaster Forecaster
+	history    HistoryConfig
 }
 
-func NewAIService(repo repository.AI, made chan<- interface{}, redis repository.Redis) *AIService {
-	// Создаем HTTP клиент с таймаутами и пропуском проверки SSL (аналогично WithCustomInsecureSkipVerify)
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
-
+func NewAIService(repo repository.AI, forecaster Forecaster, events EventPublisher, redis repository.Redis) *AIService {
 	return &AIService{
 		repo:       repo,
 		redis:      redis,
-		made:       made,
-		httpClient: httpClient,
+		events:     events,
+		forecaster: forecaster,
+		history:    HistoryConfigFromEnv(),
 	}
 }
 
-// Структуры для GigaChat API
-type GigaChatRequest struct {
-	Model       string            `json:"model"`
-	Messages    []GigaChatMessage `json:"messages"`
-	Temperature float64           `json:"temperature,omitempty"`
-	TopP        float64           `json:"top_p,omitempty"`
-	MaxTokens   int               `json:"max_tokens,omitempty"`
-}
-
-type GigaChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type GigaChatResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
-}
-
 func (ai *AIService) Predict(rq entities.AIRequest) (*entities.AIResponse, error) {
 	// 1. Создаем ключ кеша на основе входных параметров
-	cacheKey := fmt.Sprintf("ai:predict:%s:%d", generateRequestHash(rq), rq.PeriodDays)
+	cacheKey := ai.cacheKey(rq)
 
 	// 2. Пробуем получить из кеша
 	if ai.redis != nil {
@@ -79,104 +46,100 @@ func (ai *AIService) Predict(rq entities.AIRequest) (*entities.AIResponse, error
 		}
 	}
 
-	// getting the key to access the AI
-	apikey, err := config.Get("API_KEY")
+	return ai.Regenerate(rq)
+}
+
+// новый прогноз в обход кеша: сохраняется в бд и кеше и рассылается дашбордам
+func (ai *AIService) Regenerate(rq entities.AIRequest) (*entities.AIResponse, error) {
+	// getting data for analysis
+	window := ai.history.Window(rq, time.Now())
+	products, err := ai.repo.AIRequest(rq, window)
 	if err != nil {
-		log.Printf("Failed to get Giga Api key: %v", err)
 		return nil, err
 	}
+	if reduced := DownsampleHistory(products, ai.history.MaxRows); len(reduced) < len(products) {
+		logrus.Infof("AI history reduced from %d to %d rows", len(products), len(reduced))
+		products = reduced
+	}
 
-	// creating a client for communication with AI
-	ctx := context.Background()
-	client, err := gigago.NewClient(ctx, apikey, gigago.WithCustomInsecureSkipVerify(true))
+	// request to the forecasting provider
+	aiResponse, err := ai.forecaster.Forecast(context.Background(), rq, products)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s forecast failed: %w", ai.forecaster.Name(), err)
+	}
+	if aiResponse.Provider == "" {
+		aiResponse.Provider = ai.forecaster.Name()
 	}
-	defer client.Close()
 
-	// getting data for analysis
-	products, err := ai.repo.AIRequest(rq)
+	// writing the result to the database
+	alerts, err := ai.repo.AIResponse(*aiResponse)
 	if err != nil {
 		return nil, err
 	}
 
-	// converting data to json format for further analysis
-	assistantRequest, err := json.Marshal(products)
-	if err != nil {
-		return nil, err
+	// 4. Сохраняем результат в кеш на 1 час
+	if ai.redis != nil {
+		cacheKey := ai.cacheKey(rq)
+		data, _ := json.Marshal(aiResponse)
+		ai.redis.Set(cacheKey, data, time.Hour)
+		logrus.Infof("AI prediction cached for key: %s", cacheKey)
 	}
 
-	// configuring the AI model
-	model := client.GenerativeModel("GigaChat")
-	model.SystemInstruction = "Ты - AI ассистент для анализа складских запасов. Анализируй данные инвентаризации и прогнозируй остатки товаров. Отвечай ТОЛЬКО в формате JSON."
-	model.Temperature = 0.2
-	model.TopP = 0.2
-	model.MaxTokens = 3500
-	model.RepetitionPenalty = 1.2
-
-	// prompt for getting a forecast
-	messages := []gigago.Message{
-		{Role: gigago.RoleUser, Content: `Анализ складских запасов - прогноз на ` + strconv.Itoa(rq.PeriodDays) + ` дней. ДАННЫЕ ДЛЯ АНАЛИЗА:` + string(assistantRequest) +
-			`
-
-			ЗАДАЧА:
-			Проанализируй тенденции потребления для каждого товара и спрогнозируй:
-				1. Через сколько дней закончатся запасы (days_until_stockout)
-				2. Рекомендуемое количество для заказа (recommended_order_quantity)
-				3. Достоверность прогноза (confidence) от 0.0 до 1.0
-
-
-			ТРЕБОВАНИЯ К ОТВЕТУ:
-			- prediction_date должен быть: сегодняшней датой
-			- Используй product_id и product_name из предоставленных данных
-			- Ответ должен быть в точном JSON формате
-
-			ФОРМАТ ОТВЕТА:
-			{
-				"predictions": [
-					{
-						"product_id": "string",
-						"product_name: "string",
-						"prediction_date": "dd.mm.yyyy",
-						"days_until_stockout": "int",
-						"recommended_order": "int",
-						"confidence_score": "float",
-					}
-				],
-				"confidence": "float",
-			}
+	publishAlerts(ai.events, alerts)
 
-				Только JSON, без дополнительного текста.`,
-		},
-	}
+	return aiResponse, nil
+}
 
-	// request to the AI
-	resp, err := model.Generate(ctx, messages)
-	if err != nil {
+func (ai *AIService) cacheKey(rq entities.AIRequest) string {
+	return fmt.Sprintf("ai:predict:%s:%s:%d", ai.forecaster.Name(), generateRequestHash(rq), rq.PeriodDays)
+}
+
+// сохраненные прогнозы без обращения к поставщику
+func (ai *AIService) ListPredictions(query entities.PredictionQuery) (*entities.PredictionsResponse, error) {
+	if err := validatePredictionQuery(query); err != nil {
 		return nil, err
 	}
 
-	// bringing the data received from AI to a format convenient for further processing
-	var aiResponse entities.AIResponse
-	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &aiResponse); err != nil {
-		return nil, err
+	predictions, total, err := ai.repo.ListPredictions(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get predictions: %w", err)
 	}
 
-	// writing the result to the database
-	if err := ai.repo.AIResponse(aiResponse); err != nil {
+	response := &entities.PredictionsResponse{Total: total, Items: predictions}
+	response.Pagination.Limit = query.Limit
+	response.Pagination.Offset = query.Offset
+	return response, nil
+}
+
+// текущий прогноз по каждому товару
+func (ai *AIService) LatestPredictions(query entities.PredictionQuery) ([]models.AiPrediction, error) {
+	if err := validatePredictionQuery(query); err != nil {
 		return nil, err
 	}
 
-	// 4. Сохраняем результат в кеш на 1 час
-	if ai.redis != nil {
-		data, _ := json.Marshal(aiResponse)
-		ai.redis.Set(cacheKey, data, time.Hour)
-		logrus.Infof("AI prediction cached for key: %s", cacheKey)
+	predictions, err := ai.repo.LatestPredictions(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest predictions: %w", err)
 	}
+	return predictions, nil
+}
 
-	ai.made <- aiResponse
-
-	return &aiResponse, nil
+func validatePredictionQuery(query entities.PredictionQuery) error {
+	for _, date := range []string{query.From, query.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("%w: invalid date %q, expected yyyy-mm-dd", entities.ErrValidation, date)
+		}
+	}
+	if query.From != "" && query.To != "" && query.From > query.To {
+		return fmt.Errorf("%w: from must not be after to", entities.ErrValidation)
+	}
+	if c := query.MinConfidence; c != nil && (*c < 0 || *c > 1) {
+		return fmt.Errorf("%w: min_confidence must be between 0 and 1", entities.ErrValidation)
+	}
+	return nil
 }
 
 // Вспомогательная функция для создания хеша запроса
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultEscalationAfter = 15 * time.Minute
+	escalationInterval     = time.Minute
+	alertEscalationLock    = "lock:alerts:escalation"
+)
+
+// эскалация неподтвержденных критических оповещений на следующий уровень уведомлений
+type AlertEscalationService struct {
+	repo     repository.Alert
+	events   EventPublisher
+	locks    repository.Lock
+	redis    repository.Redis
+	after    time.Duration
+	interval time.Duration
+	owner    string
+}
+
+// время до эскалации из ALERT_ESCALATION_AFTER
+func NewAlertEscalationService(repo repository.Alert, events EventPublisher, locks repository.Lock, redis repository.Redis) *AlertEscalationService {
+	after := defaultEscalationAfter
+	positiveDuration("ALERT_ESCALATION_AFTER", &after)
+
+	interval := escalationInterval
+	if after < interval {
+		interval = after
+	}
+	return &AlertEscalationService{
+		repo:     repo,
+		events:   events,
+		locks:    locks,
+		redis:    redis,
+		after:    after,
+		interval: interval,
+		owner:    leaseOwner(),
+	}
+}
+
+// проверка по таймеру до отмены контекста, на каждом тике - одна реплика
+func (s *AlertEscalationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if acquireLease(s.redis, s.locks, alertEscalationLock, s.owner, s.interval*9/10) {
+			if n, err := s.Escalate(time.Now()); err != nil {
+				logrus.Errorf("alert escalation: %v", err)
+			} else if n > 0 {
+				logrus.Infof("alert escalation: escalated %d alerts", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Поднятые оповещения рассылаются как новые: дашборды видят уровень,
+// уведомления уходят в каналы нового уровня.
+func (s *AlertEscalationService) Escalate(now time.Time) (int, error) {
+	alerts, err := s.repo.EscalateAlerts(now, s.after)
+	if err != nil {
+		return 0, err
+	}
+	for _, alert := range alerts {
+		logrus.Warnf("alert %d is not acknowledged for %s, escalated to tier %d", alert.ID, s.after, alert.Tier)
+	}
+	publishAlerts(s.events, alerts)
+	return len(alerts), nil
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAlertRulesInterval = time.Minute
+	alertRulesLock            = "lock:alerts:rules"
+)
+
+var dedupPlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// правила оповещений: управление через API и периодическая проверка.
+// При приеме сканирований и прогнозов правила проверяются в репозитории, в той же транзакции.
+type AlertRuleService struct {
+	repo     repository.AlertRule
+	events   EventPublisher
+	locks    repository.Lock
+	redis    repository.Redis
+	interval time.Duration
+	owner    string
+}
+
+func NewAlertRuleService(repo repository.AlertRule, events EventPublisher, locks repository.Lock, redis repository.Redis) *AlertRuleService {
+	interval := defaultAlertRulesInterval
+	if value, err := config.Get("ALERT_RULES_INTERVAL"); err == nil {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			interval = parsed
+		} else {
+			logrus.Warnf("invalid ALERT_RULES_INTERVAL %q, using %s", value, defaultAlertRulesInterval)
+		}
+	}
+	return &AlertRuleService{
+		repo:     repo,
+		events:   events,
+		locks:    locks,
+		redis:    redis,
+		interval: interval,
+		owner:    leaseOwner(),
+	}
+}
+
+// проверка правил по таймеру до отмены контекста, на каждом тике - одна реплика
+func (s *AlertRuleService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if acquireLease(s.redis, s.locks, alertRulesLock, s.owner, s.interval*9/10) {
+			if n, err := s.EvaluateRules(time.Now()); err != nil {
+				logrus.Errorf("alert rules: %v", err)
+			} else if n > 0 {
+				logrus.Infof("alert rules: raised %d alerts", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// полная проверка правил, новые и усиленные оповещения рассылаются дашбордам
+func (s *AlertRuleService) EvaluateRules(now time.Time) (int, error) {
+	alerts, err := s.repo.EvaluateRules(now)
+	if err != nil {
+		return 0, err
+	}
+	publishAlerts(s.events, alerts)
+	return len(alerts), nil
+}
+
+func (s *AlertRuleService) ListRules() ([]models.AlertRule, error) {
+	return s.repo.ListRules()
+}
+
+func (s *AlertRuleService) GetRule(id uint) (*models.AlertRule, error) {
+	return s.repo.GetRule(id)
+}
+
+func (s *AlertRuleService) CreateRule(input entities.AlertRuleInput) (*models.AlertRule, error) {
+	rule := &models.AlertRule{
+		Name:            strings.TrimSpace(input.Name),
+		Kind:            input.Kind,
+		Threshold:       input.Threshold,
+		Severity:        input.Severity,
+		Category:        strings.TrimSpace(input.Category),
+		ProductID:       strings.TrimSpace(input.ProductID),
+		Zone:            strings.ToUpper(strings.TrimSpace(input.Zone)),
+		CooldownSeconds: input.CooldownSeconds,
+		DedupKey:        strings.TrimSpace(input.DedupKey),
+		Enabled:         true,
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	if err := validateAlertRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRule(rule); err != nil {
+		return nil, err
+	}
+	logrus.Infof("alert rule %d %q created", rule.ID, rule.Name)
+	return rule, nil
+}
+
+// вид правила не меняется: тип его оповещений уже записан в истории
+func (s *AlertRuleService) UpdateRule(id uint, input entities.AlertRuleUpdate) (*models.AlertRule, error) {
+	rule, err := s.repo.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		rule.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Threshold != nil {
+		rule.Threshold = *input.Threshold
+	}
+	if input.Severity != nil {
+		rule.Severity = *input.Severity
+	}
+	if input.Category != nil {
+		rule.Category = strings.TrimSpace(*input.Category)
+	}
+	if input.ProductID != nil {
+		rule.ProductID = strings.TrimSpace(*input.ProductID)
+	}
+	if input.Zone != nil {
+		rule.Zone = strings.ToUpper(strings.TrimSpace(*input.Zone))
+	}
+	if input.CooldownSeconds != nil {
+		rule.CooldownSeconds = *input.CooldownSeconds
+	}
+	if input.DedupKey != nil {
+		rule.DedupKey = strings.TrimSpace(*input.DedupKey)
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	if err := validateAlertRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *AlertRuleService) DeleteRule(id uint) error {
+	return s.repo.DeleteRule(id)
+}
+
+// порог в единицах вида правила и фильтры, которые к нему применимы
+func validateAlertRule(rule *models.AlertRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("%w: rule name is required", entities.ErrValidation)
+	}
+	if !models.KnownRuleKind(rule.Kind) {
+		return fmt.Errorf("%w: unknown rule kind %q", entities.ErrValidation, rule.Kind)
+	}
+	if rule.Severity != models.SeverityCritical && rule.Severity != models.SeverityWarning {
+		return fmt.Errorf("%w: unknown severity %q", entities.ErrValidation, rule.Severity)
+	}
+	if rule.CooldownSeconds < 0 {
+		return fmt.Errorf("%w: cooldown_seconds must not be negative", entities.ErrValidation)
+	}
+
+	switch rule.Kind {
+	case models.RuleCellBelowOptimal, models.RuleProductBelowOptimal, models.RuleRobotBattery:
+		if rule.Threshold <= 0 || rule.Threshold > 100 {
+			return fmt.Errorf("%w: %s threshold is a percentage in (0, 100]", entities.ErrValidation, rule.Kind)
+		}
+	case models.RuleZoneNotScanned:
+		if rule.Threshold <= 0 {
+			return fmt.Errorf("%w: %s threshold is a positive number of hours", entities.ErrValidation, rule.Kind)
+		}
+	case models.RuleForecastStockout, models.RuleForecastBeforeLeadTime:
+		if rule.Threshold < 0 {
+			return fmt.Errorf("%w: %s threshold is a non-negative number of days", entities.ErrValidation, rule.Kind)
+		}
+	}
+
+	alertType := rule.AlertType()
+	productScoped := alertType == models.AlertTypeScanned || alertType == models.AlertTypeStock || alertType == models.AlertTypePredicted
+	zoneScoped := alertType == models.AlertTypeScanned || alertType == models.AlertTypeRobot || alertType == models.AlertTypeZone
+	if !productScoped && (rule.Category != "" || rule.ProductID != "") {
+		return fmt.Errorf("%w: %s rules cannot filter by category or product", entities.ErrValidation, rule.Kind)
+	}
+	if !zoneScoped && rule.Zone != "" {
+		return fmt.Errorf("%w: %s rules cannot filter by zone", entities.ErrValidation, rule.Kind)
+	}
+
+	for _, placeholder := range dedupPlaceholder.FindAllString(rule.DedupKey, -1) {
+		if !knownPlaceholder(placeholder) {
+			return fmt.Errorf("%w: unknown dedup_key placeholder %s", entities.ErrValidation, placeholder)
+		}
+	}
+	return nil
+}
+
+func knownPlaceholder(placeholder string) bool {
+	for _, known := range models.DedupPlaceholders {
+		if placeholder == known {
+			return true
+		}
+	}
+	return false
+}
+
+// оповещения рассылаются дашбордам по одному
+func publishAlerts(events EventPublisher, alerts []models.Alert) {
+	for _, alert := range alerts {
+		events.Publish(alert)
+	}
+}
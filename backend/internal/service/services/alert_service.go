@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// оповещения создаются и закрываются при записи сканирований и прогнозов,
+// сервис ведет их по жизненному циклу по действиям операторов
+type AlertService struct {
+	repo  repository.Alert
+	users repository.Authorization
+}
+
+func NewAlertService(repo repository.Alert, users repository.Authorization) *AlertService {
+	return &AlertService{repo: repo, users: users}
+}
+
+func (s *AlertService) ListAlerts(query entities.AlertQuery) (*entities.AlertsResponse, error) {
+	if err := oneOf("status", query.Status, models.AlertOpen, models.AlertAcknowledged, models.AlertResolved); err != nil {
+		return nil, err
+	}
+	if err := oneOf("severity", query.Severity, models.SeverityCritical, models.SeverityWarning); err != nil {
+		return nil, err
+	}
+	if err := oneOf("type", query.Type, models.AlertTypeScanned, models.AlertTypeStock,
+		models.AlertTypeRobot, models.AlertTypeZone, models.AlertTypePredicted); err != nil {
+		return nil, err
+	}
+
+	alerts, total, err := s.repo.ListAlerts(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alerts: %w", err)
+	}
+
+	response := &entities.AlertsResponse{Total: total, Items: alerts}
+	response.Pagination.Limit = query.Limit
+	response.Pagination.Offset = query.Offset
+	return response, nil
+}
+
+func (s *AlertService) GetAlert(id uint) (*models.Alert, error) {
+	return s.repo.GetAlert(id)
+}
+
+// оператор взял оповещение в работу
+func (s *AlertService) AcknowledgeAlert(id, userID uint, input entities.AlertAcknowledge) (*models.Alert, error) {
+	alert, err := s.repo.GetAlert(id)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Status != models.AlertOpen {
+		return nil, fmt.Errorf("%w: alert %d is %s", entities.ErrConflict, id, alert.Status)
+	}
+
+	switch {
+	case input.AssigneeID != nil:
+		if err := s.checkAssignee(*input.AssigneeID); err != nil {
+			return nil, err
+		}
+		alert.AssigneeID = input.AssigneeID
+	case alert.AssigneeID == nil:
+		alert.AssigneeID = &userID
+	}
+
+	now := time.Now()
+	alert.Status = models.AlertAcknowledged
+	alert.AcknowledgedAt = &now
+	alert.AcknowledgedBy = &userID
+	if err := s.repo.UpdateAlert(alert, models.AlertOpen); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("alert %d acknowledged by user %d", id, userID)
+	return alert, nil
+}
+
+// назначение исполнителя активного оповещения
+func (s *AlertService) AssignAlert(id uint, input entities.AlertAssign) (*models.Alert, error) {
+	alert, err := s.repo.GetAlert(id)
+	if err != nil {
+		return nil, err
+	}
+	if !alert.Active() {
+		return nil, fmt.Errorf("%w: alert %d is %s", entities.ErrConflict, id, alert.Status)
+	}
+	if err := s.checkAssignee(input.AssigneeID); err != nil {
+		return nil, err
+	}
+
+	alert.AssigneeID = &input.AssigneeID
+	if err := s.repo.UpdateAlert(alert, alert.Status); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// закрытие оповещения вручную с описанием принятых мер
+func (s *AlertService) ResolveAlert(id, userID uint, input entities.AlertResolve) (*models.Alert, error) {
+	alert, err := s.repo.GetAlert(id)
+	if err != nil {
+		return nil, err
+	}
+	if !alert.Active() {
+		return nil, fmt.Errorf("%w: alert %d is already %s", entities.ErrConflict, id, alert.Status)
+	}
+
+	from := alert.Status
+	now := time.Now()
+	alert.Status = models.AlertResolved
+	alert.ResolvedAt = &now
+	alert.ResolvedBy = &userID
+	alert.ResolutionNote = strings.TrimSpace(input.Note)
+	if err := s.repo.UpdateAlert(alert, from); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("alert %d resolved by user %d", id, userID)
+	return alert, nil
+}
+
+// исполнитель должен существовать и иметь право работать с остатками
+func (s *AlertService) checkAssignee(userID uint) error {
+	user, err := s.users.GetUserByID(userID)
+	if errors.Is(err, entities.ErrNotFound) {
+		return fmt.Errorf("%w: user %d does not exist", entities.ErrValidation, userID)
+	}
+	if err != nil {
+		return err
+	}
+	if user.Role == models.RoleViewer {
+		return fmt.Errorf("%w: viewer %d cannot be assigned to alerts", entities.ErrValidation, userID)
+	}
+	return nil
+}
+
+func oneOf(field, value string, allowed ...string) error {
+	if value == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown %s %q", entities.ErrValidation, field, value)
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+)
+
+const (
+	factSourceStock   = "stock_levels"
+	factSourceHistory = "inventory_history"
+	maxStockFacts     = 200
+	maxHistoryFacts   = 50
+	askHistoryWindow  = 24 * time.Hour
+)
+
+// поставщик, умеющий отвечать на вопросы по переданным строкам данных
+type Answerer interface {
+	Answer(ctx context.Context, question string, facts []entities.AskFact) (*entities.AskAnswer, error)
+}
+
+// "зона B", "зоне В", "zone b"; слово после "зона" - зона, только если она есть в раскладке
+var zonePattern = regexp.MustCompile(`(?i)(?:зон[а-я]*|zone)\s+([0-9a-zа-яё]+)`)
+
+// кириллические буквы, которые операторы пишут вместо латинских в названиях зон
+var zoneLookalikes = strings.NewReplacer("А", "A", "В", "B", "С", "C", "Е", "E", "К", "K", "М", "M", "Н", "H", "О", "O", "Р", "P", "Т", "T", "Х", "X")
+
+type AskService struct {
+	inventory repository.Inventory
+	products  repository.Product
+	locations repository.Location
+	provider  Forecaster
+}
+
+func NewAskService(inventory repository.Inventory, products repository.Product, locations repository.Location, provider Forecaster) *AskService {
+	return &AskService{inventory: inventory, products: products, locations: locations, provider: provider}
+}
+
+// ответ на вопрос оператора по текущим остаткам и последним сканированиям
+func (s *AskService) Ask(question string) (*entities.AskResponse, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, fmt.Errorf("%w: question is required", entities.ErrValidation)
+	}
+
+	answerer, ok := s.provider.(Answerer)
+	if !ok {
+		return nil, fmt.Errorf("%w: provider %s does not answer questions", entities.ErrNotSupported, s.provider.Name())
+	}
+
+	facts, err := s.facts(question)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := answerer.Answer(context.Background(), question, facts)
+	if err != nil {
+		return nil, fmt.Errorf("%s answer failed: %w", s.provider.Name(), err)
+	}
+
+	byRef := make(map[string]entities.AskFact, len(facts))
+	for _, f := range facts {
+		byRef[f.Ref] = f
+	}
+	response := &entities.AskResponse{
+		Question:  question,
+		Answer:    answer.Answer,
+		Citations: []entities.AskFact{},
+		Provider:  s.provider.Name(),
+	}
+	seen := make(map[string]bool)
+	for _, ref := range answer.Refs {
+		if f, ok := byRef[ref]; ok && !seen[ref] {
+			seen[ref] = true
+			response.Citations = append(response.Citations, f)
+		}
+	}
+	return response, nil
+}
+
+// строки данных для ответа, сужаются по зонам, категориям и товарам из вопроса
+func (s *AskService) facts(question string) ([]entities.AskFact, error) {
+	catalog, err := s.products.ListProducts(entities.ProductQuery{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalog: %w", err)
+	}
+	layout, err := s.locations.ListLocations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warehouse layout: %w", err)
+	}
+	filter, err := parseAskFilter(question, catalog, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := s.inventory.GetStockLevels("", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock levels: %w", err)
+	}
+	facts := stockFacts(levels, filter)
+
+	scans, err := s.inventory.GetRecentScans(entities.RecentScansQuery{
+		Since:      time.Now().Add(-askHistoryWindow),
+		Zones:      slices.Sorted(maps.Keys(filter.zones)),
+		Categories: slices.Sorted(maps.Keys(filter.categories)),
+		ProductIDs: slices.Sorted(maps.Keys(filter.products)),
+		Limit:      maxHistoryFacts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	for i, h := range scans {
+		facts = append(facts, entities.AskFact{
+			Ref:          fmt.Sprintf("H%d", i+1),
+			Source:       factSourceHistory,
+			ProductID:    h.ProductID,
+			ProductName:  h.Product.Name,
+			Category:     h.Product.Category,
+			Zone:         h.Zone,
+			Row:          h.RowNumber,
+			Shelf:        h.ShelfNumber,
+			Quantity:     h.Quantity,
+			MinStock:     h.Product.MinStock,
+			OptimalStock: h.Product.OptimalStock,
+			Status:       h.Status,
+			ScannedAt:    h.ScannedAt,
+		})
+	}
+	return facts, nil
+}
+
+// остатки товаров по зонам, сначала критичные
+func stockFacts(levels []models.StockLevel, filter askFilter) []entities.AskFact {
+	index := make(map[string]int)
+	var facts []entities.AskFact
+	for _, level := range levels {
+		if !filter.matches(level.Product, level.Zone) {
+			continue
+		}
+		key := level.ProductID + "/" + level.Zone
+		i, ok := index[key]
+		if !ok {
+			i = len(facts)
+			index[key] = i
+			facts = append(facts, entities.AskFact{
+				Source:       factSourceStock,
+				ProductID:    level.ProductID,
+				ProductName:  level.Product.Name,
+				Category:     level.Product.Category,
+				Zone:         level.Zone,
+				MinStock:     level.Product.MinStock,
+				OptimalStock: level.Product.OptimalStock,
+			})
+		}
+		fact := &facts[i]
+		fact.Cells++
+		fact.Quantity += level.Quantity
+		if level.ScannedAt.After(fact.ScannedAt) {
+			fact.ScannedAt = level.ScannedAt
+		}
+		fact.Status = level.Product.StockStatus(fact.Quantity)
+	}
+
+	severity := map[string]int{models.StockCritical: 0, models.StockLow: 1, models.StockOK: 2}
+	sort.SliceStable(facts, func(i, j int) bool {
+		if severity[facts[i].Status] != severity[facts[j].Status] {
+			return severity[facts[i].Status] < severity[facts[j].Status]
+		}
+		if facts[i].ProductID != facts[j].ProductID {
+			return facts[i].ProductID < facts[j].ProductID
+		}
+		return facts[i].Zone < facts[j].Zone
+	})
+	if len(facts) > maxStockFacts {
+		facts = facts[:maxStockFacts]
+	}
+	for i := range facts {
+		facts[i].Ref = fmt.Sprintf("S%d", i+1)
+	}
+	return facts
+}
+
+// зоны, категории и товары, упомянутые в вопросе; пустой фильтр пропускает все
+type askFilter struct {
+	zones      map[string]bool
+	categories map[string]bool
+	products   map[string]bool
+}
+
+// Зоны сверяются с раскладкой склада, пустая зона тоже подходит.
+// Неизвестное слово после "зона" - обычная речь ("в зоне и"), а написанное
+// заглавными ("в зоне Z") - код зоны, которой нет: отвечать по всему складу нельзя.
+func parseAskFilter(question string, catalog []models.Products, layout []models.Location) (askFilter, error) {
+	filter := askFilter{zones: map[string]bool{}, categories: map[string]bool{}, products: map[string]bool{}}
+
+	defined := make(map[string]string, len(layout))
+	for _, location := range layout {
+		defined[zoneLookalikes.Replace(strings.ToUpper(location.Zone))] = location.Zone
+	}
+	for _, m := range zonePattern.FindAllStringSubmatch(question, -1) {
+		code := zoneLookalikes.Replace(strings.ToUpper(m[1]))
+		if zone, ok := defined[code]; ok {
+			filter.zones[zone] = true
+		} else if m[1] == strings.ToUpper(m[1]) {
+			return filter, fmt.Errorf("%w: unknown zone %s", entities.ErrValidation, m[1])
+		}
+	}
+
+	lower := strings.ToLower(question)
+	for _, p := range catalog {
+		if p.Category != "" && strings.Contains(lower, strings.ToLower(p.Category)) {
+			filter.categories[p.Category] = true
+		}
+		if strings.Contains(lower, strings.ToLower(p.ID)) {
+			filter.products[p.ID] = true
+		}
+	}
+	return filter, nil
+}
+
+func (f askFilter) matches(product models.Products, zone string) bool {
+	if len(f.zones) > 0 && !f.zones[zone] {
+		return false
+	}
+	if len(f.categories) > 0 && !f.categories[product.Category] {
+		return false
+	}
+	if len(f.products) > 0 && !f.products[product.ID] {
+		return false
+	}
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
 	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
 	"github.com/dgrijalva/jwt-go"
@@ -67,6 +68,29 @@ func (s *AuthService) ParseToken(accessToken string) (uint, error) {
 	return claims.UserId, nil
 }
 
+// получение роли пользователя для проверки прав доступа
+func (s *AuthService) GetUserRole(userID uint) (string, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
+func (s *AuthService) ListUsers() ([]models.Users, error) {
+	return s.repo.ListUsers()
+}
+
+// смена роли пользователя администратором
+func (s *AuthService) UpdateUserRole(userID uint, role string) error {
+	switch role {
+	case models.RoleAdmin, models.RoleOperator, models.RoleViewer:
+	default:
+		return fmt.Errorf("%w: unknown role %s", entities.ErrValidation, role)
+	}
+	return s.repo.UpdateUserRole(userID, role)
+}
+
 func generateHashPassword(password string) string {
 	hash := sha1.New()
 	hash.Write([]byte(password))
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// политика обработки медленных подписчиков
+type SlowSubscriberPolicy int
+
+const (
+	DropEvent      SlowSubscriberPolicy = iota // событие отбрасывается, подписчик остается
+	DropSubscriber                             // подписчик отключается
+)
+
+const defaultSubscriberBuffer = 64
+
+// источник событий для дашбордов
+type EventPublisher interface {
+	Publish(event interface{})
+}
+
+// подписка на события хаба
+type Subscription struct {
+	id     uint64
+	events chan interface{}
+}
+
+// канал событий подписчика, закрывается при отписке
+func (s *Subscription) Events() <-chan interface{} {
+	return s.events
+}
+
+// хаб событий, рассылающий каждое событие всем подписчикам
+type EventHub struct {
+	mu          sync.RWMutex
+	subscribers map[uint64]*Subscription
+	nextID      uint64
+	buffer      int
+	policy      SlowSubscriberPolicy
+}
+
+func NewEventHub(buffer int, policy SlowSubscriberPolicy) *EventHub {
+	if buffer <= 0 {
+		buffer = defaultSubscriberBuffer
+	}
+	return &EventHub{
+		subscribers: make(map[uint64]*Subscription),
+		buffer:      buffer,
+		policy:      policy,
+	}
+}
+
+// регистрация нового подписчика
+func (h *EventHub) Subscribe() *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	sub := &Subscription{id: h.nextID, events: make(chan interface{}, h.buffer)}
+	h.subscribers[sub.id] = sub
+	return sub
+}
+
+// удаление подписчика, повторный вызов безопасен
+func (h *EventHub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sub.id]; ok {
+		delete(h.subscribers, sub.id)
+		close(sub.events)
+	}
+}
+
+// рассылка события всем подписчикам, никогда не блокирует отправителя
+func (h *EventHub) Publish(event interface{}) {
+	var slow []*Subscription
+
+	h.mu.RLock()
+	for _, sub := range h.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		switch h.policy {
+		case DropSubscriber:
+			logrus.Warnf("event hub: subscriber %d is too slow, disconnecting", sub.id)
+			h.Unsubscribe(sub)
+		default:
+			logrus.Warnf("event hub: subscriber %d buffer is full, event dropped", sub.id)
+		}
+	}
+}
+
+// количество активных подписчиков
+func (h *EventHub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+)
+
+// допустимые значения прогноза
+const (
+	maxRecommendedOrder = 100000
+)
+
+// ответ модели в свободной форме: числа могут прийти строками,
+// общая уверенность - под ключом из промпта или из AIResponse
+type rawForecast struct {
+	Predictions []rawPrediction `json:"predictions"`
+	Confidence  *flexFloat      `json:"confidence"`
+	Confidience *flexFloat      `json:"confidience"`
+}
+
+type rawPrediction struct {
+	ProductID         string    `json:"product_id"`
+	ProductName       string    `json:"product_name"`
+	PredictionDate    string    `json:"prediction_date"`
+	DaysUntilStockout flexFloat `json:"days_until_stockout"`
+	RecommendedOrder  flexFloat `json:"recommended_order"`
+	ConfidenceScore   flexFloat `json:"confidence_score"`
+}
+
+// число, которое модель может вернуть строкой: 12, "12", "0.8", "0,8", "1,200"
+type flexFloat float64
+
+// запятые как разделители тысяч: 1,200 и 12,345,678.5
+var thousandsGrouping = regexp.MustCompile(`^[-+]?\d{1,3}(,\d{3})+(\.\d+)?$`)
+
+func (f *flexFloat) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "null" {
+		return nil
+	}
+	text = strings.TrimSpace(strings.Trim(text, `"`))
+	switch {
+	case thousandsGrouping.MatchString(text):
+		text = strings.ReplaceAll(text, ",", "")
+	case strings.Count(text, ",") == 1 && !strings.Contains(text, "."):
+		text = strings.Replace(text, ",", ".", 1) // десятичная запятая: 0,8
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fmt.Errorf("not a number: %s", data)
+	}
+	*f = flexFloat(v)
+	return nil
+}
+
+// ParseForecastResponse разбирает ответ модели: снимает markdown-обертку,
+// вырезает JSON из окружающего текста и исправляет висящие запятые.
+func ParseForecastResponse(content string) (*entities.AIResponse, error) {
+	body := extractJSONObject(content)
+	if body == "" {
+		return nil, errors.New("response contains no JSON object")
+	}
+
+	var raw rawForecast
+	decoder := json.NewDecoder(bytes.NewReader(removeTrailingCommas(body)))
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if raw.Predictions == nil {
+		return nil, errors.New(`response has no "predictions" field`)
+	}
+
+	response := &entities.AIResponse{Predictions: make([]entities.Predictions, 0, len(raw.Predictions))}
+	switch {
+	case raw.Confidence != nil:
+		response.Confidence = float64(*raw.Confidence)
+	case raw.Confidience != nil:
+		response.Confidence = float64(*raw.Confidience)
+	}
+	for _, p := range raw.Predictions {
+		response.Predictions = append(response.Predictions, entities.Predictions{
+			ProductID:         strings.TrimSpace(p.ProductID),
+			ProductName:       strings.TrimSpace(p.ProductName),
+			PredictionDate:    strings.TrimSpace(p.PredictionDate),
+			DaysUntilStockout: int(math.Round(float64(p.DaysUntilStockout))),
+			RecommendedOrder:  int(math.Round(float64(p.RecommendedOrder))),
+			ConfidenceScore:   float64(p.ConfidenceScore),
+		})
+	}
+	return response, nil
+}
+
+// ParseAnswerResponse разбирает ответ модели на вопрос: {"answer": "...", "refs": ["S1", "H2"]}.
+// Ссылки должны указывать на переданные строки, без ссылок ответ принимается только при пустых данных.
+func ParseAnswerResponse(content string, facts []entities.AskFact) (*entities.AskAnswer, error) {
+	body := extractJSONObject(content)
+	if body == "" {
+		return nil, errors.New("response contains no JSON object")
+	}
+
+	var raw struct {
+		Answer string   `json:"answer"`
+		Refs   []string `json:"refs"`
+	}
+	if err := json.Unmarshal(removeTrailingCommas(body), &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	answer := &entities.AskAnswer{Answer: strings.TrimSpace(raw.Answer)}
+	if answer.Answer == "" {
+		return nil, errors.New(`response has empty "answer"`)
+	}
+
+	known := make(map[string]bool, len(facts))
+	for _, f := range facts {
+		known[f.Ref] = true
+	}
+	var unknown []string
+	for _, ref := range raw.Refs {
+		ref = strings.ToUpper(strings.TrimSpace(ref))
+		if !known[ref] {
+			unknown = append(unknown, ref)
+			continue
+		}
+		answer.Refs = append(answer.Refs, ref)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown refs %s", strings.Join(unknown, ", "))
+	}
+	if len(answer.Refs) == 0 && len(facts) > 0 {
+		return nil, errors.New(`answer must cite the rows it is based on in "refs"`)
+	}
+	return answer, nil
+}
+
+// ValidateForecast оставляет прогнозы по товарам каталога с допустимыми значениями,
+// отброшенные попадают в Dropped с причиной. Каталог - товары по id, включая архивные.
+func ValidateForecast(response *entities.AIResponse, catalog map[string]models.Products) {
+	today := time.Now().Format("2006-01-02")
+	seen := make(map[string]bool)
+	valid := make([]entities.Predictions, 0, len(response.Predictions))
+	drop := func(p entities.Predictions, reason string) {
+		response.Dropped = append(response.Dropped, entities.DroppedPrediction{ProductID: p.ProductID, Reason: reason})
+	}
+
+	for _, p := range response.Predictions {
+		product, known := catalog[p.ProductID]
+		confidence := p.ConfidenceScore
+		if confidence > 1 && confidence <= 100 {
+			confidence /= 100 // модель ответила в процентах
+		}
+
+		switch {
+		case !known:
+			drop(p, "unknown product")
+			continue
+		case product.ArchivedAt != nil:
+			drop(p, "product is archived")
+			continue
+		case seen[p.ProductID]:
+			drop(p, "duplicate prediction")
+			continue
+		case p.DaysUntilStockout < 0 || p.DaysUntilStockout > maxForecastDays:
+			drop(p, fmt.Sprintf("days_until_stockout %d is out of range 0..%d", p.DaysUntilStockout, maxForecastDays))
+			continue
+		case p.RecommendedOrder < 0 || p.RecommendedOrder > maxRecommendedOrder:
+			drop(p, fmt.Sprintf("recommended_order %d is out of range 0..%d", p.RecommendedOrder, maxRecommendedOrder))
+			continue
+		case confidence < 0 || confidence > 1:
+			drop(p, fmt.Sprintf("confidence_score %v is out of range 0..1", p.ConfidenceScore))
+			continue
+		}
+
+		seen[p.ProductID] = true
+		p.ConfidenceScore = confidence
+		if p.ProductName == "" {
+			p.ProductName = product.Name
+		}
+		p.PredictionDate = normalizePredictionDate(p.PredictionDate, today)
+		valid = append(valid, p)
+	}
+
+	response.Predictions = valid
+	if response.Confidence > 1 && response.Confidence <= 100 {
+		response.Confidence /= 100
+	}
+	response.Confidence = clamp(response.Confidence, 0, 1)
+}
+
+// дата прогноза в формате yyyy-mm-dd, неразборчивая заменяется сегодняшней
+func normalizePredictionDate(date, today string) string {
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return today
+}
+
+// JSON объект внутри ответа: ```json ... ```, пояснения до и после
+func extractJSONObject(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start == -1 || end < start {
+		return ""
+	}
+	return content[start : end+1]
+}
+
+// удаление запятых перед } и ], строки не затрагиваются
+func removeTrailingCommas(body string) []byte {
+	out := make([]byte, 0, len(body))
+	inString, escaped := false, false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if inString {
+			out = append(out, c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			j := i + 1
+			for j < len(body) && strings.ContainsRune(" \t\r\n", rune(body[j])) {
+				j++
+			}
+			if j < len(body) && (body[j] == '}' || body[j] == ']') {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	allCategories      = "*"
+	forecastLockPrefix = "lock:ai:schedule:"
+	minForecastEvery   = time.Minute
+)
+
+// построение прогноза в обход кеша
+type ForecastRunner interface {
+	Regenerate(entities.AIRequest) (*entities.AIResponse, error)
+}
+
+// задача регулярного прогноза для категорий товаров
+type ForecastJob struct {
+	Categories []string // пусто - все категории
+	PeriodDays int
+	Every      time.Duration
+}
+
+// имя задачи, одинаковое на всех репликах
+func (j ForecastJob) Name() string {
+	categories := allCategories
+	if len(j.Categories) > 0 {
+		categories = strings.Join(j.Categories, ",")
+	}
+	return fmt.Sprintf("%s:%d", categories, j.PeriodDays)
+}
+
+// ParseForecastSchedule разбирает расписание вида
+// "*:7:6h;Сетевое оборудование,Кабели:14:24h" - категории, период прогноза в днях и интервал.
+func ParseForecastSchedule(schedule string) ([]ForecastJob, error) {
+	var jobs []ForecastJob
+	for _, entry := range strings.Split(schedule, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("schedule entry %q: expected categories:period_days:interval", entry)
+		}
+		period, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || period <= 0 || period > maxForecastDays {
+			return nil, fmt.Errorf("schedule entry %q: invalid period %q", entry, parts[1])
+		}
+		every, err := time.ParseDuration(strings.TrimSpace(parts[2]))
+		if err != nil || every < minForecastEvery {
+			return nil, fmt.Errorf("schedule entry %q: interval must be a duration of at least %s", entry, minForecastEvery)
+		}
+
+		job := ForecastJob{PeriodDays: period, Every: every}
+		for _, category := range strings.Split(parts[0], ",") {
+			category = strings.TrimSpace(category)
+			if category == allCategories {
+				job.Categories = nil
+				break
+			}
+			if category != "" {
+				job.Categories = append(job.Categories, category)
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// планировщик прогнозов, на каждой реплике задачу выполняет только владелец аренды
+type ForecastScheduler struct {
+	runner ForecastRunner
+	locks  repository.Lock
+	redis  repository.Redis
+	jobs   []ForecastJob
+	owner  string
+}
+
+func NewForecastScheduler(runner ForecastRunner, locks repository.Lock, redis repository.Redis, jobs []ForecastJob) *ForecastScheduler {
+	return &ForecastScheduler{
+		runner: runner,
+		locks:  locks,
+		redis:  redis,
+		jobs:   jobs,
+		owner:  leaseOwner(),
+	}
+}
+
+// расписание из AI_SCHEDULE, без него планировщик ничего не делает;
+// ошибка в расписании останавливает запуск, а не отключает прогнозы молча
+func NewForecastSchedulerFromConfig(runner ForecastRunner, locks repository.Lock, redis repository.Redis) (*ForecastScheduler, error) {
+	schedule, _ := config.Get("AI_SCHEDULE")
+	jobs, err := ParseForecastSchedule(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_SCHEDULE: %w", err)
+	}
+	return NewForecastScheduler(runner, locks, redis, jobs), nil
+}
+
+// запуск всех задач до отмены контекста
+func (s *ForecastScheduler) Run(ctx context.Context) {
+	if len(s.jobs) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job ForecastJob) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (s *ForecastScheduler) loop(ctx context.Context, job ForecastJob) {
+	logrus.Infof("forecast schedule %s: every %s", job.Name(), job.Every)
+
+	ticker := time.NewTicker(job.Every)
+	defer ticker.Stop()
+
+	for {
+		s.RunJob(job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// однократный запуск задачи, если эта реплика получила аренду.
+// Аренда чуть короче интервала, чтобы следующий тик любой реплики ее уже застал свободной.
+func (s *ForecastScheduler) RunJob(job ForecastJob) bool {
+	if !acquireLease(s.redis, s.locks, forecastLockPrefix+job.Name(), s.owner, job.Every*9/10) {
+		return false
+	}
+
+	rq := entities.AIRequest{PeriodDays: job.PeriodDays, Categories: job.Categories}
+	response, err := s.runner.Regenerate(rq)
+	if err != nil {
+		logrus.Errorf("forecast schedule %s: %v", job.Name(), err)
+		return true
+	}
+	logrus.Infof("forecast schedule %s: %d predictions from %s", job.Name(), len(response.Predictions), response.Provider)
+	return true
+}
+
+// владелец аренды фоновых задач: реплика и процесс
+func leaseOwner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// аренда в Redis, при его недоступности - в Postgres
+func acquireLease(redis repository.Redis, locks repository.Lock, name, owner string, ttl time.Duration) bool {
+	if redis != nil {
+		ok, err := redis.SetNX(name, owner, ttl)
+		if err == nil {
+			return ok
+		}
+		logrus.Warnf("lease %s: redis lock failed, using postgres: %v", name, err)
+	}
+
+	ok, err := locks.TryLock(name, owner, ttl)
+	if err != nil {
+		logrus.Errorf("lease %s: lock failed: %v", name, err)
+		return false
+	}
+	return ok
+}
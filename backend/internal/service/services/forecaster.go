@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Role1776/gigago"
+	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/fakegigachat"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// поставщик прогнозов остатков, AIService не зависит от конкретной модели
+type Forecaster interface {
+	// имя поставщика, попадает в ключ кеша и логи
+	Name() string
+	// прогноз по истории инвентаризации за период запроса
+	Forecast(ctx context.Context, rq entities.AIRequest, history []models.InventoryHistory) (*entities.AIResponse, error)
+}
+
+const (
+	ProviderGigaChat = "gigachat"
+	providerNone     = "none"
+)
+
+// выбор поставщика по AI_SERVICE (по умолчанию GigaChat) и резервного по AI_FALLBACK
+// (по умолчанию статистический, "none" отключает резерв)
+func NewForecasterFromConfig(products repository.Product) (Forecaster, error) {
+	provider, _ := config.Get("AI_SERVICE")
+	primary, err := NewForecaster(provider, products)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackName, _ := config.Get("AI_FALLBACK")
+	if fallbackName == "" {
+		fallbackName = ProviderStatistical
+	}
+	if strings.EqualFold(fallbackName, providerNone) || strings.EqualFold(fallbackName, primary.Name()) {
+		return primary, nil
+	}
+	fallback, err := NewForecaster(fallbackName, products)
+	if err != nil {
+		return nil, err
+	}
+	return NewFallbackForecaster(primary, fallback), nil
+}
+
+// каталог товаров нужен поставщикам, ответы которых проверяются по нему
+func NewForecaster(provider string, products repository.Product) (Forecaster, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "", ProviderGigaChat, "sber":
+		return newGigaChatFromConfig(products), nil
+	case ProviderStatistical:
+		return NewStatisticalForecaster(), nil
+	default:
+		return nil, fmt.Errorf("unknown forecasting provider %q", provider)
+	}
+}
+
+// основной поставщик с резервным на случай его ошибки
+type FallbackForecaster struct {
+	primary  Forecaster
+	fallback Forecaster
+}
+
+func NewFallbackForecaster(primary, fallback Forecaster) *FallbackForecaster {
+	return &FallbackForecaster{primary: primary, fallback: fallback}
+}
+
+func (f *FallbackForecaster) Name() string {
+	return f.primary.Name() + "+" + f.fallback.Name()
+}
+
+func (f *FallbackForecaster) Forecast(ctx context.Context, rq entities.AIRequest, history []models.InventoryHistory) (*entities.AIResponse, error) {
+	response, err := f.primary.Forecast(ctx, rq, history)
+	if err == nil {
+		if response.Provider == "" {
+			response.Provider = f.primary.Name()
+		}
+		return response, nil
+	}
+
+	logrus.Warnf("%s forecast failed, falling back to %s: %v", f.primary.Name(), f.fallback.Name(), err)
+	response, fallbackErr := f.fallback.Forecast(ctx, rq, history)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("%v; fallback %s: %w", err, f.fallback.Name(), fallbackErr)
+	}
+	if response.Provider == "" {
+		response.Provider = f.fallback.Name()
+	}
+	return response, nil
+}
+
+// ответ основного поставщика, если он умеет отвечать на вопросы, иначе или при ошибке - резервного
+func (f *FallbackForecaster) Answer(ctx context.Context, question string, facts []entities.AskFact) (*entities.AskAnswer, error) {
+	primary, primaryOK := f.primary.(Answerer)
+	fallback, fallbackOK := f.fallback.(Answerer)
+
+	if primaryOK {
+		answer, err := primary.Answer(ctx, question, facts)
+		if err == nil || !fallbackOK {
+			return answer, err
+		}
+		logrus.Warnf("%s answer failed, falling back to %s: %v", f.primary.Name(), f.fallback.Name(), err)
+	}
+	if !fallbackOK {
+		return nil, fmt.Errorf("%w: provider %s does not answer questions", entities.ErrNotSupported, f.Name())
+	}
+	return fallback.Answer(ctx, question, facts)
+}
+
+// ключ и адрес GigaChat из окружения, адрес переопределяется для локальной замены
+func newGigaChatFromConfig(products repository.Product) *GigaChatForecaster {
+	apiKey, _ := config.Get("API_KEY")
+
+	var options []gigago.Option
+	// пути у замены (cmd/fake_gigachat) те же, что у API Сбера, меняется только базовый адрес
+	if base, err := config.Get("GIGACHAT_BASE_URL"); err == nil {
+		base = strings.TrimRight(base, "/")
+		options = append(options,
+			gigago.WithCustomURLAI(base+fakegigachat.ChatPath),
+			gigago.WithCustomURLOauth(base+fakegigachat.OAuthPath),
+		)
+	}
+	if scope, err := config.Get("GIGACHAT_SCOPE"); err == nil {
+		options = append(options, gigago.WithCustomScope(scope))
+	}
+	return NewGigaChatForecaster(apiKey, products, options...)
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Role1776/gigago"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Структуры для GigaChat API
+type GigaChatRequest struct {
+	Model       string            `json:"model"`
+	Messages    []GigaChatMessage `json:"messages"`
+	Temperature float64           `json:"temperature,omitempty"`
+	TopP        float64           `json:"top_p,omitempty"`
+	MaxTokens   int               `json:"max_tokens,omitempty"`
+}
+
+type GigaChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type GigaChatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+const gigaChatAttempts = 2 // первый запрос и один исправляющий
+
+const (
+	forecastInstruction = "Ты - AI ассистент для анализа складских запасов. Анализируй данные инвентаризации и прогнозируй остатки товаров. Отвечай ТОЛЬКО в формате JSON."
+	forecastCorrection  = "Исправь ответ: верни только JSON по указанному формату, без markdown и пояснений, " +
+		"числа - без кавычек, только product_id из данных, значения не отрицательные."
+
+	askInstruction = "Ты - AI ассистент оператора склада. Отвечай на вопросы по предоставленным данным инвентаризации. Отвечай ТОЛЬКО в формате JSON."
+	askCorrection  = "Исправь ответ: верни только JSON {\"answer\": ..., \"refs\": [...]}, в refs - только ref из данных."
+)
+
+// прогноз через GigaChat, ключ читается один раз при создании.
+// Ответы модели проверяются по каталогу товаров.
+type GigaChatForecaster struct {
+	apiKey   string
+	products repository.Product
+	options  []gigago.Option
+}
+
+func NewGigaChatForecaster(apiKey string, products repository.Product, options ...gigago.Option) *GigaChatForecaster {
+	return &GigaChatForecaster{apiKey: apiKey, products: products, options: options}
+}
+
+func (f *GigaChatForecaster) Name() string {
+	return ProviderGigaChat
+}
+
+func (f *GigaChatForecaster) Forecast(ctx context.Context, rq entities.AIRequest, history []models.InventoryHistory) (*entities.AIResponse, error) {
+	catalog, err := f.catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	// converting data to json format for further analysis
+	assistantRequest, err := json.Marshal(history)
+	if err != nil {
+		return nil, err
+	}
+
+	// prompt for getting a forecast
+	prompt := `Анализ складских запасов - прогноз на ` + strconv.Itoa(rq.PeriodDays) + ` дней. ДАННЫЕ ДЛЯ АНАЛИЗА:` + string(assistantRequest) +
+		`
+
+			ЗАДАЧА:
+			Проанализируй тенденции потребления для каждого товара и спрогнозируй:
+				1. Через сколько дней закончатся запасы (days_until_stockout)
+				2. Рекомендуемое количество для заказа (recommended_order)
+				3. Достоверность прогноза (confidence) от 0.0 до 1.0
+
+
+			ТРЕБОВАНИЯ К ОТВЕТУ:
+			- prediction_date должен быть: сегодняшней датой
+			- Используй product_id и product_name из предоставленных данных
+			- Ответ должен быть в точном JSON формате
+
+			ФОРМАТ ОТВЕТА:
+			{
+				"predictions": [
+					{
+						"product_id": "string",
+						"product_name": "string",
+						"prediction_date": "dd.mm.yyyy",
+						"days_until_stockout": int,
+						"recommended_order": int,
+						"confidence_score": float
+					}
+				],
+				"confidence": float
+			}
+
+				Только JSON, без дополнительного текста.`
+
+	var aiResponse *entities.AIResponse
+	err = f.chat(ctx, forecastInstruction, prompt, forecastCorrection, func(content string) error {
+		response, err := ParseForecastResponse(content)
+		if err != nil {
+			return err
+		}
+		ValidateForecast(response, catalog)
+		if len(response.Predictions) == 0 && len(history) > 0 {
+			return fmt.Errorf("no valid predictions: %s", droppedReasons(response.Dropped))
+		}
+		aiResponse = response
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range aiResponse.Dropped {
+		logrus.Warnf("gigachat prediction for %s dropped: %s", d.ProductID, d.Reason)
+	}
+	aiResponse.Provider = ProviderGigaChat
+	return aiResponse, nil
+}
+
+// каталог товаров по id, архивные нужны для причины отказа
+func (f *GigaChatForecaster) catalog() (map[string]models.Products, error) {
+	if f.products == nil {
+		return nil, errors.New("gigachat: product catalog is not configured")
+	}
+	products, err := f.products.ListProducts(entities.ProductQuery{IncludeArchived: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load product catalog: %w", err)
+	}
+	catalog := make(map[string]models.Products, len(products))
+	for _, p := range products {
+		catalog[p.ID] = p
+	}
+	return catalog, nil
+}
+
+// ответ на вопрос оператора только по переданным строкам данных со ссылками на них
+func (f *GigaChatForecaster) Answer(ctx context.Context, question string, facts []entities.AskFact) (*entities.AskAnswer, error) {
+	data, err := json.Marshal(facts)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := `ВОПРОС ОПЕРАТОРА: ` + question + `
+
+			ДАННЫЕ СКЛАДА (ref - идентификатор строки, source - stock_levels для остатка товара в зоне
+			или inventory_history для сканирования за последние сутки):
+			` + string(data) + `
+
+			ТРЕБОВАНИЯ К ОТВЕТУ:
+			- Отвечай только по этим данным, ничего не придумывай
+			- Если данных недостаточно, так и напиши
+			- В refs перечисли ref всех строк, на которых основан ответ
+
+			ФОРМАТ ОТВЕТА:
+			{"answer": "string", "refs": ["S1", "H2"]}
+
+			Только JSON, без дополнительного текста.`
+
+	var answer *entities.AskAnswer
+	err = f.chat(ctx, askInstruction, prompt, askCorrection, func(content string) error {
+		parsed, err := ParseAnswerResponse(content, facts)
+		if err != nil {
+			return err
+		}
+		answer = parsed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// диалог с моделью: ответ, не прошедший accept, возвращается ей с описанием ошибки
+// и просьбой исправить, всего gigaChatAttempts попыток
+func (f *GigaChatForecaster) chat(ctx context.Context, instruction, prompt, correction string, accept func(content string) error) error {
+	if f.apiKey == "" {
+		return errors.New("gigachat: API_KEY is not configured")
+	}
+
+	// creating a client for communication with AI
+	options := append([]gigago.Option{gigago.WithCustomInsecureSkipVerify(true)}, f.options...)
+	client, err := gigago.NewClient(ctx, f.apiKey, options...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// configuring the AI model
+	model := client.GenerativeModel("GigaChat")
+	model.SystemInstruction = instruction
+	model.Temperature = 0.2
+	model.TopP = 0.2
+	model.MaxTokens = 3500
+	model.RepetitionPenalty = 1.2
+
+	messages := []gigago.Message{{Role: gigago.RoleUser, Content: prompt}}
+
+	var lastErr error
+	for attempt := 1; attempt <= gigaChatAttempts; attempt++ {
+		resp, err := model.Generate(ctx, messages)
+		if err != nil {
+			return err
+		}
+		if len(resp.Choices) == 0 {
+			lastErr = errors.New("empty response")
+			continue
+		}
+		content := resp.Choices[0].Message.Content
+
+		err = accept(content)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		logrus.Warnf("gigachat response rejected (attempt %d/%d): %v", attempt, gigaChatAttempts, err)
+		messages = append(messages,
+			gigago.Message{Role: gigago.RoleAssistant, Content: content},
+			gigago.Message{Role: gigago.RoleUser, Content: "Ответ не принят: " + err.Error() + ". " + correction},
+		)
+	}
+
+	return fmt.Errorf("gigachat: invalid response after %d attempts: %w", gigaChatAttempts, lastErr)
+}
+
+func droppedReasons(dropped []entities.DroppedPrediction) string {
+	reasons := make([]string, 0, len(dropped))
+	for _, d := range dropped {
+		reasons = append(reasons, d.ProductID+" - "+d.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
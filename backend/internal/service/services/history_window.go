@@ -0,0 +1,195 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// настройки истории, которая отправляется поставщику прогнозов
+type HistoryConfig struct {
+	LookbackFactor  int           // глубина истории в периодах прогноза
+	MinLookbackDays int           // не меньше, даже для прогноза на 1 день
+	MaxLookbackDays int           // не больше, чтобы не читать всю историю
+	DetailWindow    time.Duration // последние данные идут по слотам, старые сводятся по дням
+	Slot            time.Duration
+	MaxRows         int // предел строк истории в запросе к модели
+}
+
+func DefaultHistoryConfig() HistoryConfig {
+	return HistoryConfig{
+		LookbackFactor:  3,
+		MinLookbackDays: 3,
+		MaxLookbackDays: 90,
+		DetailWindow:    72 * time.Hour,
+		Slot:            10 * time.Minute,
+		MaxRows:         500,
+	}
+}
+
+// настройки из AI_LOOKBACK_FACTOR, AI_MAX_LOOKBACK_DAYS, AI_DETAIL_HOURS, AI_SLOT_MINUTES и AI_MAX_HISTORY_ROWS
+func HistoryConfigFromEnv() HistoryConfig {
+	cfg := DefaultHistoryConfig()
+	positiveInt("AI_LOOKBACK_FACTOR", &cfg.LookbackFactor)
+	positiveInt("AI_MAX_LOOKBACK_DAYS", &cfg.MaxLookbackDays)
+	positiveInt("AI_MAX_HISTORY_ROWS", &cfg.MaxRows)
+
+	hours := int(cfg.DetailWindow.Hours())
+	positiveInt("AI_DETAIL_HOURS", &hours)
+	cfg.DetailWindow = time.Duration(hours) * time.Hour
+
+	minutes := int(cfg.Slot.Minutes())
+	positiveInt("AI_SLOT_MINUTES", &minutes)
+	cfg.Slot = time.Duration(minutes) * time.Minute
+
+	if cfg.MaxLookbackDays < cfg.MinLookbackDays {
+		cfg.MaxLookbackDays = cfg.MinLookbackDays
+	}
+	return cfg
+}
+
+func positiveInt(key string, target *int) {
+	value, err := config.Get(key)
+	if err != nil {
+		return
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		logrus.Warnf("invalid %s %q, using %d", key, value, *target)
+		return
+	}
+	*target = parsed
+}
+
+// окно истории для запроса: глубина из запроса или от периода прогноза
+func (c HistoryConfig) Window(rq entities.AIRequest, now time.Time) entities.HistoryWindow {
+	days := rq.LookbackDays
+	if days <= 0 {
+		days = rq.PeriodDays * c.LookbackFactor
+	}
+	if days < c.MinLookbackDays {
+		days = c.MinLookbackDays
+	}
+	if days > c.MaxLookbackDays {
+		days = c.MaxLookbackDays
+	}
+
+	from := now.AddDate(0, 0, -days)
+	detailFrom := now.Add(-c.DetailWindow)
+	if detailFrom.Before(from) {
+		detailFrom = from
+	}
+	return entities.HistoryWindow{From: from, DetailFrom: detailFrom, Slot: c.Slot}
+}
+
+// DownsampleHistory сокращает историю до maxRows строк.
+// Ряд - сканирования товара в одной ячейке. Каждому ряду достается равная доля,
+// из длинных рядов точки берутся равномерно, первая и последняя сохраняются.
+// Если по две точки на каждый ряд не помещаются, остаются товары, ближе всего
+// к нехватке. Порядок строк не меняется.
+func DownsampleHistory(history []models.InventoryHistory, maxRows int) []models.InventoryHistory {
+	if maxRows <= 0 || len(history) <= maxRows {
+		return history
+	}
+
+	series := make(map[historySeries][]int)
+	var order []historySeries
+	for i, h := range history {
+		key := historySeries{h.ProductID, h.Zone, h.RowNumber, h.ShelfNumber}
+		if _, ok := series[key]; !ok {
+			order = append(order, key)
+		}
+		series[key] = append(series[key], i)
+	}
+
+	// без двух точек на ряд не посчитать расход
+	if len(order)*2 > maxRows {
+		order = urgentSeries(history, series, order, maxRows/2)
+		if len(order) == 0 {
+			return history[:0]
+		}
+	}
+	budget := maxRows / len(order)
+
+	keep := make([]bool, len(history))
+	for _, key := range order {
+		indexes := series[key]
+		if len(indexes) <= budget {
+			for _, i := range indexes {
+				keep[i] = true
+			}
+			continue
+		}
+		last := len(indexes) - 1
+		for k := 0; k < budget; k++ {
+			keep[indexes[k*last/(budget-1)]] = true
+		}
+	}
+
+	reduced := make([]models.InventoryHistory, 0, maxRows)
+	for i, h := range history {
+		if keep[i] {
+			reduced = append(reduced, h)
+		}
+	}
+	return reduced
+}
+
+type historySeries struct {
+	productID  string
+	zone       string
+	row, shelf int
+}
+
+// не больше limit рядов: товары по возрастанию запаса над min_stock
+// (сумма последних сканирований ячеек), ряды товара берутся только целиком
+func urgentSeries(history []models.InventoryHistory, series map[historySeries][]int, order []historySeries, limit int) []historySeries {
+	type productReserve struct {
+		id      string
+		reserve int
+		series  int
+	}
+	reserves := make(map[string]*productReserve)
+	var products []*productReserve
+	for _, key := range order {
+		latest := history[series[key][0]]
+		for _, i := range series[key] {
+			if history[i].ScannedAt.After(latest.ScannedAt) {
+				latest = history[i]
+			}
+		}
+		p, ok := reserves[key.productID]
+		if !ok {
+			p = &productReserve{id: key.productID, reserve: -latest.Product.MinStock}
+			reserves[key.productID] = p
+			products = append(products, p)
+		}
+		p.reserve += latest.Quantity
+		p.series++
+	}
+	sort.SliceStable(products, func(i, j int) bool { return products[i].reserve < products[j].reserve })
+
+	kept := make(map[string]bool)
+	total := 0
+	for _, p := range products {
+		if total+p.series > limit {
+			continue
+		}
+		kept[p.id] = true
+		total += p.series
+	}
+	logrus.Warnf("AI history: %d of %d products do not fit into %d rows and are left out", len(products)-len(kept), len(products), limit*2)
+
+	urgent := make([]historySeries, 0, total)
+	for _, key := range order {
+		if kept[key.productID] {
+			urgent = append(urgent, key)
+		}
+	}
+	return urgent
+}
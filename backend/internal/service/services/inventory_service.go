@@ -16,19 +16,19 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-const (
-	robotIdForImport = "IMPORT_SERVICE"
-)
-
 type InventoryService struct {
-	repo  repository.Inventory
-	redis repository.Redis
+	repo      repository.Inventory
+	locations repository.Location
+	events    EventPublisher
+	redis     repository.Redis
 }
 
-func NewInventoryService(repo repository.Inventory, redis repository.Redis) *InventoryService {
+func NewInventoryService(repo repository.Inventory, locations repository.Location, events EventPublisher, redis repository.Redis) *InventoryService {
 	return &InventoryService{
-		repo:  repo,
-		redis: redis,
+		repo:      repo,
+		locations: locations,
+		events:    events,
+		redis:     redis,
 	}
 }
 
@@ -90,18 +90,53 @@ func (s *InventoryService) ImportCSV(csvData io.Reader) (*entities.ImportResult,
 
 		// формирование модели для бд
 		history := models.InventoryHistory{
-			RobotID:     robotIdForImport,
+			RobotID:     models.ImportRobotID,
 			ProductID:   productID,
 			Quantity:    quantity,
 			Zone:        strings.TrimSpace(record[3]),
 			RowNumber:   row,
 			ShelfNumber: shelf,
-			Status:      "imported",
 			ScannedAt:   scannedAt,
 		}
 
 		histories = append(histories, history)
-		successCount++
+	}
+
+	// статус остатка считается по порогам товара, строки с неизвестным или архивным
+	// товаром или ячейкой вне раскладки склада отклоняются
+	if len(histories) > 0 {
+		products, err := s.productsByID(histories)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get products: %w", err)
+		}
+		layout, err := s.layout()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get warehouse layout: %w", err)
+		}
+
+		valid := histories[:0]
+		for _, history := range histories {
+			product, ok := products[history.ProductID]
+			if !ok {
+				failedCount++
+				errors = append(errors, fmt.Sprintf("Unknown product %s", history.ProductID))
+				continue
+			}
+			if product.ArchivedAt != nil {
+				failedCount++
+				errors = append(errors, fmt.Sprintf("Product %s is archived", history.ProductID))
+				continue
+			}
+			if err := checkCell(layout, history); err != nil {
+				failedCount++
+				errors = append(errors, fmt.Sprintf("Invalid location for product %s: %v", history.ProductID, err))
+				continue
+			}
+			history.Status = product.StockStatus(history.Quantity)
+			valid = append(valid, history)
+		}
+		histories = valid
+		successCount = len(histories)
 	}
 
 	// вставка данных в бд
@@ -118,6 +153,53 @@ func (s *InventoryService) ImportCSV(csvData io.Reader) (*entities.ImportResult,
 	}, nil
 }
 
+func (s *InventoryService) productsByID(histories []models.InventoryHistory) (map[string]models.Products, error) {
+	ids := make([]string, 0, len(histories))
+	seen := make(map[string]bool)
+	for _, history := range histories {
+		if !seen[history.ProductID] {
+			seen[history.ProductID] = true
+			ids = append(ids, history.ProductID)
+		}
+	}
+
+	products, err := s.repo.GetProductsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Products, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	return byID, nil
+}
+
+func (s *InventoryService) layout() (map[string]models.Location, error) {
+	locations, err := s.locations.ListLocations()
+	if err != nil {
+		return nil, err
+	}
+	layout := make(map[string]models.Location, len(locations))
+	for _, location := range locations {
+		layout[location.Zone] = location
+	}
+	return layout, nil
+}
+
+// проверка ячейки и вместимости полки по раскладке склада
+func checkCell(layout map[string]models.Location, history models.InventoryHistory) error {
+	location, ok := layout[history.Zone]
+	switch {
+	case !ok:
+		return fmt.Errorf("unknown zone %q", history.Zone)
+	case !location.Contains(history.RowNumber, history.ShelfNumber):
+		return fmt.Errorf("cell %s-%d-%d is outside of the warehouse layout", history.Zone, history.RowNumber, history.ShelfNumber)
+	case !location.Fits(history.Quantity):
+		return fmt.Errorf("quantity %d exceeds shelf capacity %d", history.Quantity, location.ShelfCapacity)
+	}
+	return nil
+}
+
 // экспорт данных их приложения в формате Excel таблицы
 func (s *InventoryService) ExportExcel(scanIDs []string) ([]byte, error) {
 	// получение данных для экспорта
@@ -186,3 +268,88 @@ func (s *InventoryService) GetHistory(from, to, zone, status string, limit, offs
 
 	return response, nil
 }
+
+// текущие остатки: итог по товару и разбивка по ячейкам
+func (s *InventoryService) GetStock(productID, zone string) (*entities.StockResponse, error) {
+	levels, err := s.repo.GetStockLevels(productID, zone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock levels: %w", err)
+	}
+
+	response := &entities.StockResponse{Products: []entities.ProductStock{}}
+	index := make(map[string]int)
+	thresholds := make(map[string]models.Products)
+	for _, level := range levels {
+		i, ok := index[level.ProductID]
+		if !ok {
+			i = len(response.Products)
+			index[level.ProductID] = i
+			thresholds[level.ProductID] = level.Product
+			response.Products = append(response.Products, entities.ProductStock{
+				ProductID:   level.ProductID,
+				ProductName: level.Product.Name,
+			})
+		}
+
+		stock := &response.Products[i]
+		stock.Total += level.Quantity
+		stock.Locations = append(stock.Locations, entities.StockLocation{
+			Zone:      level.Zone,
+			Row:       level.RowNumber,
+			Shelf:     level.ShelfNumber,
+			Quantity:  level.Quantity,
+			Status:    level.Status,
+			ScannedAt: level.ScannedAt,
+		})
+	}
+
+	for i := range response.Products {
+		stock := &response.Products[i]
+		stock.Status = thresholds[stock.ProductID].StockStatus(stock.Total)
+	}
+
+	return response, nil
+}
+
+// отмеченные выбросы в сканированиях, по умолчанию ожидающие решения
+func (s *InventoryService) ListAnomalies(query entities.AnomalyQuery) (*entities.HistoryResponse, error) {
+	switch query.Status {
+	case "":
+		query.Status = models.AnomalyPending
+	case "all":
+		query.Status = ""
+	case models.AnomalyPending, models.AnomalyConfirmed, models.AnomalyRejected:
+	default:
+		return nil, fmt.Errorf("%w: unknown anomaly status %q", entities.ErrValidation, query.Status)
+	}
+
+	histories, total, err := s.repo.ListAnomalies(query.Status, query.Limit, query.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anomalies: %w", err)
+	}
+
+	response := &entities.HistoryResponse{Total: total, Items: histories}
+	response.Pagination.Limit = query.Limit
+	response.Pagination.Offset = query.Offset
+	return response, nil
+}
+
+// подтверждение выброса возвращает сканирование в остатки и прогнозы, отклонение оставляет его только в истории
+func (s *InventoryService) ResolveAnomaly(id uint, confirm bool, userID uint) (*models.InventoryHistory, error) {
+	status := models.AnomalyRejected
+	if confirm {
+		status = models.AnomalyConfirmed
+	}
+
+	history, alerts, err := s.repo.ResolveAnomaly(id, status, userID)
+	if err != nil {
+		return nil, err
+	}
+	publishAlerts(s.events, alerts)
+
+	if s.redis != nil {
+		s.redis.Delete("dashboard:current")
+	}
+	logrus.Infof("anomaly in scan %d %s by user %d", id, status, userID)
+	return history, nil
+}
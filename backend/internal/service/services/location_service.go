@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type LocationService struct {
+	repo repository.Location
+}
+
+func NewLocationService(repo repository.Location) *LocationService {
+	return &LocationService{repo: repo}
+}
+
+func (s *LocationService) ListLocations() ([]models.Location, error) {
+	return s.repo.ListLocations()
+}
+
+func (s *LocationService) GetLocation(zone string) (*models.Location, error) {
+	return s.repo.GetLocation(strings.ToUpper(zone))
+}
+
+// добавление зоны в раскладку склада
+func (s *LocationService) CreateLocation(input entities.LocationInput) (*models.Location, error) {
+	zone := strings.ToUpper(strings.TrimSpace(input.Zone))
+	if zone == "" || strings.Contains(zone, "-") {
+		return nil, fmt.Errorf("%w: invalid zone %q", entities.ErrValidation, input.Zone)
+	}
+
+	location := &models.Location{
+		Zone:          zone,
+		Name:          strings.TrimSpace(input.Name),
+		Rows:          input.Rows,
+		ShelvesPerRow: input.ShelvesPerRow,
+		ShelfCapacity: input.ShelfCapacity,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := validateLocation(location); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateLocation(location); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("zone %s added to warehouse layout", location.Zone)
+	return location, nil
+}
+
+func (s *LocationService) UpdateLocation(zone string, input entities.LocationUpdate) (*models.Location, error) {
+	location, err := s.GetLocation(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		location.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Rows != nil {
+		location.Rows = *input.Rows
+	}
+	if input.ShelvesPerRow != nil {
+		location.ShelvesPerRow = *input.ShelvesPerRow
+	}
+	if input.ShelfCapacity != nil {
+		location.ShelfCapacity = *input.ShelfCapacity
+	}
+	if err := validateLocation(location); err != nil {
+		return nil, err
+	}
+	location.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateLocation(location); err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+func (s *LocationService) DeleteLocation(zone string) error {
+	return s.repo.DeleteLocation(strings.ToUpper(zone))
+}
+
+func validateLocation(location *models.Location) error {
+	switch {
+	case location.Rows < 1:
+		return fmt.Errorf("%w: zone must have at least one row", entities.ErrValidation)
+	case location.ShelvesPerRow < 1:
+		return fmt.Errorf("%w: row must have at least one shelf", entities.ErrValidation)
+	case location.ShelfCapacity < 0:
+		return fmt.Errorf("%w: shelf capacity must not be negative", entities.ErrValidation)
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	notifyPollInterval = 5 * time.Second
+	notifyBatch        = 50
+	deliveryLease      = 2 * time.Minute // больше таймаута отправки
+)
+
+// повторы неудачной доставки с экспоненциальной задержкой
+type RetryPolicy struct {
+	MaxAttempts int
+	Base        time.Duration // задержка после первой неудачи, дальше удваивается
+	Max         time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 5, Base: 30 * time.Second, Max: time.Hour}
+}
+
+// настройки из NOTIFY_MAX_ATTEMPTS, NOTIFY_RETRY_BASE и NOTIFY_RETRY_MAX
+func RetryPolicyFromEnv() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	positiveInt("NOTIFY_MAX_ATTEMPTS", &policy.MaxAttempts)
+	positiveDuration("NOTIFY_RETRY_BASE", &policy.Base)
+	positiveDuration("NOTIFY_RETRY_MAX", &policy.Max)
+	return policy
+}
+
+// задержка перед следующей попыткой после attempts неудачных
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.Base
+	for i := 1; i < attempts && delay < p.Max; i++ {
+		delay *= 2
+	}
+	if delay > p.Max {
+		return p.Max
+	}
+	return delay
+}
+
+// Уведомления об оповещениях во внешние каналы. Журнал доставки пишет репозиторий
+// в транзакции, которая открыла, усилила или эскалировала оповещение; отправка идет
+// из журнала с повторами.
+type NotificationService struct {
+	repo      repository.Notification
+	hub       *EventHub
+	notifiers map[string]Notifier
+	retry     RetryPolicy
+	wake      chan struct{}
+}
+
+func NewNotificationService(repo repository.Notification, hub *EventHub, retry RetryPolicy, notifiers ...Notifier) *NotificationService {
+	byKind := make(map[string]Notifier, len(notifiers))
+	for _, n := range notifiers {
+		byKind[n.Kind()] = n
+	}
+	return &NotificationService{
+		repo:      repo,
+		hub:       hub,
+		notifiers: byKind,
+		retry:     retry,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+func NewNotificationServiceFromConfig(repo repository.Notification, hub *EventHub) *NotificationService {
+	return NewNotificationService(repo, hub, RetryPolicyFromEnv(), NewNotifiersFromConfig()...)
+}
+
+// Отправка журнала до отмены контекста. Оповещения из хаба только будят отправку:
+// пропущенное событие задержит доставку до следующего опроса, но не потеряет ее.
+func (s *NotificationService) Run(ctx context.Context) {
+	sub := s.hub.Subscribe()
+	defer s.hub.Unsubscribe(sub)
+
+	go s.dispatchLoop(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if _, isAlert := event.(models.Alert); !isAlert {
+				continue
+			}
+			select {
+			case s.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (s *NotificationService) dispatchLoop(ctx context.Context) {
+	ticker := time.NewTicker(notifyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.Dispatch(ctx, time.Now())
+		if err != nil {
+			logrus.Errorf("notifications: %v", err)
+		}
+		if n == notifyBatch {
+			continue // в очереди могут быть еще доставки
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// отправка доставок, которым пора, возвращает число взятых из очереди
+func (s *NotificationService) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := s.repo.ClaimDeliveries(now, notifyBatch, deliveryLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		s.deliver(ctx, delivery, now)
+		if err := s.repo.SaveDelivery(delivery); err != nil {
+			return i, fmt.Errorf("delivery %d: %w", delivery.ID, err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// одна попытка отправки, после последней неудачной доставка помечается failed
+func (s *NotificationService) deliver(ctx context.Context, delivery *models.NotificationDelivery, now time.Time) {
+	err := s.send(ctx, delivery)
+	delivery.Attempts++
+	if err == nil {
+		delivery.Status = models.DeliverySent
+		delivery.SentAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= s.retry.MaxAttempts || delivery.Channel == nil || !delivery.Channel.Enabled {
+		delivery.Status = models.DeliveryFailed
+		logrus.Warnf("notifications: delivery %d of alert %d failed: %v", delivery.ID, delivery.AlertID, err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(s.retry.Delay(delivery.Attempts))
+}
+
+func (s *NotificationService) send(ctx context.Context, delivery *models.NotificationDelivery) error {
+	if delivery.Channel == nil {
+		return fmt.Errorf("channel was deleted")
+	}
+	if !delivery.Channel.Enabled {
+		return fmt.Errorf("channel is disabled")
+	}
+	if delivery.Alert == nil {
+		return fmt.Errorf("alert was deleted")
+	}
+	notifier, ok := s.notifiers[delivery.Channel.Kind]
+	if !ok {
+		return fmt.Errorf("%s channels are not configured", delivery.Channel.Kind)
+	}
+	return notifier.Send(ctx, Notification{DeliveryID: delivery.ID, Channel: *delivery.Channel, Alert: *delivery.Alert})
+}
+
+func (s *NotificationService) ListChannels() ([]models.NotificationChannel, error) {
+	return s.repo.ListChannels()
+}
+
+func (s *NotificationService) GetChannel(id uint) (*models.NotificationChannel, error) {
+	return s.repo.GetChannel(id)
+}
+
+func (s *NotificationService) CreateChannel(input entities.NotificationChannelInput) (*models.NotificationChannel, error) {
+	channel := &models.NotificationChannel{
+		Name:       strings.TrimSpace(input.Name),
+		Kind:       input.Kind,
+		Target:     channelTarget(input.Kind, input.Target),
+		Secret:     input.Secret,
+		Severities: normalizeList(input.Severities, false),
+		Zones:      normalizeList(input.Zones, true),
+		Tier:       input.Tier,
+		Enabled:    true,
+	}
+	if channel.Tier == 0 {
+		channel.Tier = 1
+	}
+	if input.Enabled != nil {
+		channel.Enabled = *input.Enabled
+	}
+	if err := s.validateChannel(channel); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateChannel(channel); err != nil {
+		return nil, err
+	}
+	logrus.Infof("notification channel %d %q (%s) created", channel.ID, channel.Name, channel.Kind)
+	return channel, nil
+}
+
+// вид канала не меняется: по нему записан журнал доставки
+func (s *NotificationService) UpdateChannel(id uint, input entities.NotificationChannelUpdate) (*models.NotificationChannel, error) {
+	channel, err := s.repo.GetChannel(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		channel.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Target != nil {
+		channel.Target = channelTarget(channel.Kind, *input.Target)
+	}
+	if input.Secret != nil {
+		channel.Secret = *input.Secret
+	}
+	if input.Severities != nil {
+		channel.Severities = normalizeList(*input.Severities, false)
+	}
+	if input.Zones != nil {
+		channel.Zones = normalizeList(*input.Zones, true)
+	}
+	if input.Tier != nil {
+		channel.Tier = *input.Tier
+	}
+	if input.Enabled != nil {
+		channel.Enabled = *input.Enabled
+	}
+	if err := s.validateChannel(channel); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateChannel(channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (s *NotificationService) DeleteChannel(id uint) error {
+	return s.repo.DeleteChannel(id)
+}
+
+// проверочное уведомление отправляется сразу и в журнал не пишется
+func (s *NotificationService) TestChannel(ctx context.Context, id uint) (*entities.ChannelTestResult, error) {
+	channel, err := s.repo.GetChannel(id)
+	if err != nil {
+		return nil, err
+	}
+	notifier, ok := s.notifiers[channel.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s channels are not configured", entities.ErrNotSupported, channel.Kind)
+	}
+
+	now := time.Now()
+	alert := models.Alert{
+		Severity:  models.SeverityWarning,
+		Status:    models.AlertOpen,
+		Message:   fmt.Sprintf("Проверка канала уведомлений «%s»", channel.Name),
+		ScannedAt: now,
+		CreatedAt: now,
+	}
+	if err := notifier.Send(ctx, Notification{Channel: *channel, Alert: alert}); err != nil {
+		return &entities.ChannelTestResult{Status: models.DeliveryFailed, Error: err.Error()}, nil
+	}
+	return &entities.ChannelTestResult{Status: models.DeliverySent}, nil
+}
+
+func (s *NotificationService) ListDeliveries(query entities.DeliveryQuery) (*entities.DeliveriesResponse, error) {
+	if err := oneOf("status", query.Status, models.DeliveryPending, models.DeliverySent, models.DeliveryFailed); err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := s.repo.ListDeliveries(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+
+	response := &entities.DeliveriesResponse{Total: total, Items: deliveries}
+	response.Pagination.Limit = query.Limit
+	response.Pagination.Offset = query.Offset
+	return response, nil
+}
+
+// адрес назначения зависит от вида канала, подпись обязательна для вебхука
+func (s *NotificationService) validateChannel(channel *models.NotificationChannel) error {
+	if channel.Name == "" {
+		return fmt.Errorf("%w: channel name is required", entities.ErrValidation)
+	}
+	if err := oneOf("channel kind", channel.Kind, models.ChannelWebhook, models.ChannelEmail, models.ChannelTelegram); err != nil {
+		return err
+	}
+	if _, ok := s.notifiers[channel.Kind]; !ok {
+		return fmt.Errorf("%w: %s channels are not configured on the server", entities.ErrValidation, channel.Kind)
+	}
+	if channel.Tier < 1 {
+		return fmt.Errorf("%w: channel tier must be at least 1", entities.ErrValidation)
+	}
+
+	recipients := channel.Recipients()
+	if channel.Target == "" || len(recipients) == 0 {
+		return fmt.Errorf("%w: channel target is required", entities.ErrValidation)
+	}
+	switch channel.Kind {
+	case models.ChannelWebhook:
+		target, err := url.Parse(channel.Target)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("%w: webhook target must be an http(s) url", entities.ErrValidation)
+		}
+		if len(channel.Secret) < 16 {
+			return fmt.Errorf("%w: webhook secret must be at least 16 characters", entities.ErrValidation)
+		}
+	case models.ChannelEmail:
+		for _, address := range recipients {
+			if _, err := mail.ParseAddress(address); err != nil {
+				return fmt.Errorf("%w: invalid email %q", entities.ErrValidation, address)
+			}
+		}
+	}
+	if channel.Kind != models.ChannelWebhook && channel.Secret != "" {
+		return fmt.Errorf("%w: secret is only used by webhook channels", entities.ErrValidation)
+	}
+
+	for _, severity := range models.SplitList(channel.Severities) {
+		if err := oneOf("severity", severity, models.SeverityCritical, models.SeverityWarning); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// url вебхука как есть, адреса и chat_id - списком
+func channelTarget(kind, target string) string {
+	if kind == models.ChannelWebhook {
+		return strings.TrimSpace(target)
+	}
+	return normalizeList(target, false)
+}
+
+// список через запятую без пробелов, зоны в верхнем регистре
+func normalizeList(list string, upper bool) string {
+	values := models.SplitList(list)
+	if upper {
+		for i := range values {
+			values[i] = strings.ToUpper(values[i])
+		}
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultNotifyTimeout = 10 * time.Second
+
+// уведомление об оповещении для одного канала
+type Notification struct {
+	DeliveryID uint64 // 0 у проверочного уведомления
+	Channel    models.NotificationChannel
+	Alert      models.Alert
+}
+
+// способ доставки уведомлений, один на вид канала
+type Notifier interface {
+	Kind() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// Доставка по конфигурации: вебхуки доступны всегда, почта - с SMTP_ADDR,
+// бот - с TELEGRAM_BOT_TOKEN. Адреса SMTP и API бота можно направить на локальные заглушки.
+func NewNotifiersFromConfig() []Notifier {
+	timeout := defaultNotifyTimeout
+	positiveDuration("NOTIFY_TIMEOUT", &timeout)
+	notifiers := []Notifier{NewWebhookNotifier(timeout)}
+
+	if addr, err := config.Get("SMTP_ADDR"); err == nil {
+		username, _ := config.Get("SMTP_USERNAME")
+		password, _ := config.Get("SMTP_PASSWORD")
+		from, _ := config.Get("SMTP_FROM")
+		notifiers = append(notifiers, NewEmailNotifier(addr, username, password, from, timeout))
+	}
+	if token, err := config.Get("TELEGRAM_BOT_TOKEN"); err == nil {
+		apiURL, _ := config.Get("TELEGRAM_API_URL")
+		notifiers = append(notifiers, NewTelegramNotifier(apiURL, token, timeout))
+	}
+	return notifiers
+}
+
+func positiveDuration(key string, target *time.Duration) {
+	value, err := config.Get(key)
+	if err != nil {
+		return
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		logrus.Warnf("invalid %s %q, using %s", key, value, *target)
+		return
+	}
+	*target = parsed
+}
+
+// тема и текст уведомления для почты и бота
+func notificationText(alert models.Alert) (string, string) {
+	subject := fmt.Sprintf("[%s] %s", strings.ToUpper(alert.Severity), alertTitle(alert))
+	if alert.Tier > 1 {
+		subject = fmt.Sprintf("[ESCALATED] %s", subject)
+	}
+
+	lines := []string{alert.Message}
+	if alert.ProductID != "" {
+		lines = append(lines, fmt.Sprintf("Товар: %s (%s)", alert.ProductName, alert.ProductID))
+	}
+	if alert.RobotID != "" {
+		lines = append(lines, "Робот: "+alert.RobotID)
+	}
+	if alert.RowNumber > 0 {
+		lines = append(lines, fmt.Sprintf("Ячейка: %s-%d-%d", alert.Zone, alert.RowNumber, alert.ShelfNumber))
+	} else if alert.Zone != "" {
+		lines = append(lines, "Зона: "+alert.Zone)
+	}
+	if alert.Occurrences > 1 {
+		lines = append(lines, fmt.Sprintf("Повторов: %d", alert.Occurrences))
+	}
+	if alert.Tier > 1 {
+		lines = append(lines, fmt.Sprintf("Эскалация: уровень %d, не подтверждено с %s", alert.Tier, alert.CreatedAt.Format(time.RFC3339)))
+	}
+	lines = append(lines,
+		"Статус: "+alert.Status,
+		"Время: "+alert.ScannedAt.Format(time.RFC3339),
+	)
+	if alert.ID != 0 {
+		lines = append(lines, fmt.Sprintf("Оповещение #%d", alert.ID))
+	}
+	return subject, strings.Join(lines, "\n")
+}
+
+func alertTitle(alert models.Alert) string {
+	switch alert.Type {
+	case models.AlertTypeScanned:
+		return fmt.Sprintf("Остаток %s в зоне %s", alert.ProductID, alert.Zone)
+	case models.AlertTypeStock:
+		return "Остаток товара " + alert.ProductID
+	case models.AlertTypeRobot:
+		return "Робот " + alert.RobotID
+	case models.AlertTypeZone:
+		return "Зона " + alert.Zone
+	case models.AlertTypePredicted:
+		return "Прогноз нехватки " + alert.ProductID
+	default:
+		return "Проверка канала уведомлений"
+	}
+}
+
+// ошибка http клиента без url запроса: в url бота есть токен
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
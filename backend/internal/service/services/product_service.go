@@ -0,0 +1,257 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// пороги по умолчанию, как в таблице products
+const (
+	defaultMinStock     = 10
+	defaultOptimalStock = 100
+	defaultLeadTimeDays = 7
+)
+
+type ProductService struct {
+	repo repository.Product
+}
+
+func NewProductService(repo repository.Product) *ProductService {
+	return &ProductService{repo: repo}
+}
+
+func (s *ProductService) ListProducts(query entities.ProductQuery) ([]models.Products, error) {
+	query.Search = strings.TrimSpace(query.Search)
+	return s.repo.ListProducts(query)
+}
+
+func (s *ProductService) GetProduct(productID string) (*models.Products, error) {
+	return s.repo.GetProduct(productID)
+}
+
+// добавление нового товара в каталог
+func (s *ProductService) CreateProduct(input entities.ProductInput) (*models.Products, error) {
+	product := &models.Products{
+		ID:           strings.TrimSpace(input.ID),
+		Name:         strings.TrimSpace(input.Name),
+		Category:     strings.TrimSpace(input.Category),
+		MinStock:     defaultMinStock,
+		OptimalStock: defaultOptimalStock,
+		LeadTimeDays: defaultLeadTimeDays,
+	}
+	if input.MinStock != nil {
+		product.MinStock = *input.MinStock
+	}
+	if input.OptimalStock != nil {
+		product.OptimalStock = *input.OptimalStock
+	}
+	if input.LeadTimeDays != nil {
+		product.LeadTimeDays = *input.LeadTimeDays
+	}
+	if err := validateProduct(product); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateProduct(product); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("product %s created", product.ID)
+	return product, nil
+}
+
+func (s *ProductService) UpdateProduct(productID string, input entities.ProductUpdate) (*models.Products, error) {
+	product, err := s.repo.GetProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		product.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Category != nil {
+		product.Category = strings.TrimSpace(*input.Category)
+	}
+	if input.MinStock != nil {
+		product.MinStock = *input.MinStock
+	}
+	if input.OptimalStock != nil {
+		product.OptimalStock = *input.OptimalStock
+	}
+	if input.LeadTimeDays != nil {
+		product.LeadTimeDays = *input.LeadTimeDays
+	}
+	if err := validateProduct(product); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateProduct(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// архивирование товара, история сканирований и остатки сохраняются
+func (s *ProductService) ArchiveProduct(productID string) (*models.Products, error) {
+	product, err := s.repo.GetProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.ArchivedAt != nil {
+		return product, nil
+	}
+
+	now := time.Now()
+	product.ArchivedAt = &now
+	if err := s.repo.UpdateProduct(product); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("product %s archived", product.ID)
+	return product, nil
+}
+
+// возврат товара из архива в каталог
+func (s *ProductService) UnarchiveProduct(productID string) (*models.Products, error) {
+	product, err := s.repo.GetProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.ArchivedAt == nil {
+		return product, nil
+	}
+
+	product.ArchivedAt = nil
+	if err := s.repo.UpdateProduct(product); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("product %s restored from archive", product.ID)
+	return product, nil
+}
+
+// загрузка каталога из csv: id;name;category;min_stock;optimal_stock;lead_time_days
+func (s *ProductService) ImportProductsCSV(csvData io.Reader) (*entities.ImportResult, error) {
+	reader := csv.NewReader(csvData)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = -1
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	var products []models.Products
+	index := make(map[string]int)
+	errors := []string{}
+	failedCount := 0
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			failedCount++
+			errors = append(errors, fmt.Sprintf("CSV read error: %v", err))
+			continue
+		}
+
+		product, err := parseProductRecord(record)
+		if err != nil {
+			failedCount++
+			errors = append(errors, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+
+		// повтор товара в файле перезаписывает предыдущую строку
+		if i, ok := index[product.ID]; ok {
+			products[i] = *product
+			continue
+		}
+		index[product.ID] = len(products)
+		products = append(products, *product)
+	}
+
+	if len(products) > 0 {
+		if err := s.repo.UpsertProducts(products); err != nil {
+			return nil, fmt.Errorf("failed to import products: %w", err)
+		}
+	}
+
+	return &entities.ImportResult{
+		SuccessCount: len(products),
+		FailedCount:  failedCount,
+		Errors:       errors,
+	}, nil
+}
+
+func parseProductRecord(record []string) (*models.Products, error) {
+	if len(record) < 2 {
+		return nil, fmt.Errorf("insufficient fields in record")
+	}
+	field := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	product := &models.Products{
+		ID:           field(0),
+		Name:         field(1),
+		Category:     field(2),
+		MinStock:     defaultMinStock,
+		OptimalStock: defaultOptimalStock,
+		LeadTimeDays: defaultLeadTimeDays,
+	}
+	if v := field(3); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_stock %q", v)
+		}
+		product.MinStock = n
+	}
+	if v := field(4); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid optimal_stock %q", v)
+		}
+		product.OptimalStock = n
+	}
+	if v := field(5); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lead_time_days %q", v)
+		}
+		product.LeadTimeDays = n
+	}
+
+	if err := validateProduct(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func validateProduct(product *models.Products) error {
+	switch {
+	case product.ID == "" || len(product.ID) > 50:
+		return fmt.Errorf("%w: invalid product id %q", entities.ErrValidation, product.ID)
+	case product.Name == "":
+		return fmt.Errorf("%w: product name is required", entities.ErrValidation)
+	case product.MinStock < 0:
+		return fmt.Errorf("%w: min_stock must not be negative", entities.ErrValidation)
+	case product.MinStock > product.OptimalStock:
+		return fmt.Errorf("%w: min_stock %d exceeds optimal_stock %d", entities.ErrValidation, product.MinStock, product.OptimalStock)
+	case product.LeadTimeDays < 0:
+		return fmt.Errorf("%w: lead_time_days must not be negative", entities.ErrValidation)
+	}
+	return nil
+}
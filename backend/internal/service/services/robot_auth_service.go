@@ -0,0 +1,244 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/config"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	robotKeyPrefix      = "rk_"
+	robotSignatureSkew  = 5 * time.Minute // допустимое расхождение часов робота и сервера
+	robotSignatureCache = "robot:signature:"
+)
+
+type RobotAuthService struct {
+	repo   repository.RobotAuth
+	robots repository.Robot
+	redis  repository.Redis
+	legacy bool
+	secret cipher.AEAD // шифрование секретов ключей в бд, nil - ROBOT_SECRET_KEY не задан
+
+	// использованные подписи, если Redis недоступен
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func NewRobotAuthService(repo repository.RobotAuth, robots repository.Robot, redis repository.Redis) *RobotAuthService {
+	// старая схема "Bearer token_<robotId>" остается только для эмулятора в разработке
+	legacy, _ := config.Get("ROBOT_LEGACY_AUTH")
+
+	var aead cipher.AEAD
+	if key, err := config.Get("ROBOT_SECRET_KEY"); err == nil {
+		aead = newSecretCipher(key)
+	} else {
+		logrus.Warn("ROBOT_SECRET_KEY is not set, robot keys cannot be issued or verified")
+	}
+
+	return &RobotAuthService{
+		repo:   repo,
+		robots: robots,
+		redis:  redis,
+		legacy: legacy == "true",
+		secret: aead,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// AES-256-GCM с ключом sha256 от ROBOT_SECRET_KEY
+func newSecretCipher(key string) cipher.AEAD {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		panic(err) // ключ всегда 32 байта
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+// шифротекст привязан к id ключа, строку одного ключа нельзя подставить другому
+func (s *RobotAuthService) sealSecret(keyID, secret string) (string, error) {
+	if s.secret == nil {
+		return "", errors.New("ROBOT_SECRET_KEY is not set")
+	}
+	nonce := make([]byte, s.secret.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.secret.Seal(nonce, nonce, []byte(secret), []byte(keyID))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *RobotAuthService) openSecret(cred *models.RobotCredential) ([]byte, error) {
+	if s.secret == nil {
+		return nil, errors.New("ROBOT_SECRET_KEY is not set")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(cred.SecretEncrypted)
+	if err != nil || len(sealed) < s.secret.NonceSize() {
+		return nil, fmt.Errorf("corrupted secret of key %s", cred.KeyID)
+	}
+	nonce, data := sealed[:s.secret.NonceSize()], sealed[s.secret.NonceSize():]
+	secret, err := s.secret.Open(nil, nonce, data, []byte(cred.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret of key %s: %w", cred.KeyID, err)
+	}
+	return secret, nil
+}
+
+// выпуск нового ключа робота, секрет возвращается только один раз
+func (s *RobotAuthService) IssueKey(robotID string) (*entities.RobotKey, error) {
+	return s.issue(robotID, s.repo.CreateCredential)
+}
+
+// ротация: новый ключ выпускается, все старые отзываются
+func (s *RobotAuthService) RotateKeys(robotID string) (*entities.RobotKey, error) {
+	return s.issue(robotID, s.repo.ReplaceCredentials)
+}
+
+func (s *RobotAuthService) ListKeys(robotID string) ([]models.RobotCredential, error) {
+	if _, err := s.robots.GetRobot(robotID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCredentials(robotID)
+}
+
+func (s *RobotAuthService) RevokeKey(robotID, keyID string) error {
+	return s.repo.RevokeCredential(robotID, keyID)
+}
+
+// проверка подписи запроса робота, возвращает id робота-владельца ключа
+func (s *RobotAuthService) VerifyRequest(req entities.RobotSignedRequest) (string, error) {
+	ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid timestamp", entities.ErrUnauthorized)
+	}
+	signedAt := time.Unix(ts, 0)
+	if skew := time.Since(signedAt); skew > robotSignatureSkew || skew < -robotSignatureSkew {
+		return "", fmt.Errorf("%w: timestamp is outside of the allowed window", entities.ErrUnauthorized)
+	}
+
+	cred, err := s.repo.GetActiveCredential(req.KeyID)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return "", fmt.Errorf("%w: unknown or revoked key", entities.ErrUnauthorized)
+		}
+		return "", err
+	}
+
+	secret, err := s.openSecret(cred)
+	if err != nil {
+		return "", err
+	}
+	expected := SignRobotRequest(secret, req.Timestamp, req.Method, req.Path, req.Body)
+	given, err := hex.DecodeString(req.Signature)
+	if err != nil || !hmac.Equal(expected, given) {
+		return "", fmt.Errorf("%w: signature mismatch", entities.ErrUnauthorized)
+	}
+
+	if !s.markSignatureUsed(req.Signature) {
+		return "", fmt.Errorf("%w: request replay detected", entities.ErrUnauthorized)
+	}
+
+	return cred.RobotID, nil
+}
+
+func (s *RobotAuthService) LegacyAuthAllowed() bool {
+	return s.legacy
+}
+
+// SignRobotRequest считает подпись запроса робота, ключ подписи - секрет робота.
+// В бд секрет хранится только в зашифрованном виде.
+func SignRobotRequest(secret []byte, timestamp, method, path string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + path + "\n" + hex.EncodeToString(bodyHash[:])))
+	return mac.Sum(nil)
+}
+
+func (s *RobotAuthService) issue(robotID string, save func(*models.RobotCredential) error) (*entities.RobotKey, error) {
+	robot, err := s.robots.GetRobot(robotID)
+	if err != nil {
+		return nil, err
+	}
+	if robot.Status == models.RobotStatusDecommissioned {
+		return nil, fmt.Errorf("%w: robot %s is decommissioned", entities.ErrValidation, robotID)
+	}
+
+	keyID, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	cred := &models.RobotCredential{
+		RobotID:   robotID,
+		KeyID:     robotKeyPrefix + keyID,
+		CreatedAt: time.Now(),
+	}
+	if cred.SecretEncrypted, err = s.sealSecret(cred.KeyID, secret); err != nil {
+		return nil, err
+	}
+	if err := save(cred); err != nil {
+		return nil, err
+	}
+
+	return &entities.RobotKey{
+		RobotID:   robotID,
+		KeyID:     cred.KeyID,
+		Secret:    secret,
+		CreatedAt: cred.CreatedAt,
+	}, nil
+}
+
+// подпись принимается один раз в пределах окна проверки времени
+func (s *RobotAuthService) markSignatureUsed(signature string) bool {
+	if s.redis != nil {
+		if ok, err := s.redis.SetNX(robotSignatureCache+signature, "1", 2*robotSignatureSkew); err == nil {
+			return ok
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for sig, at := range s.seen {
+		if now.Sub(at) > 2*robotSignatureSkew {
+			delete(s.seen, sig)
+		}
+	}
+	if _, ok := s.seen[signature]; ok {
+		return false
+	}
+	s.seen[signature] = now
+	return true
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -3,37 +3,109 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
 	"github.com/sirupsen/logrus"
 )
 
 type RobotService struct {
-	repo  repository.Robot
-	made  chan<- interface{}
-	redis repository.Redis
+	repo   repository.Robot
+	events EventPublisher
+	redis  repository.Redis
 }
 
-func NewRobotService(repo repository.Robot, made chan<- interface{}, redis repository.Redis) *RobotService {
+func NewRobotService(repo repository.Robot, events EventPublisher, redis repository.Redis) *RobotService {
 	return &RobotService{
-		repo:  repo,
-		made:  made,
-		redis: redis,
+		repo:   repo,
+		events: events,
+		redis:  redis,
 	}
 }
 
-// добавление данных о сканировании
-func (r *RobotService) AddData(data entities.RobotsData) error {
-	// Проверка валидности данных
-	if !r.repo.CheckId(data.RobotId) {
-		return fmt.Errorf("invalid robot id: %s", data.RobotId)
+const robotBatchChunk = 100 // сообщений в одной транзакции
+
+// добавление данных о сканировании, повтор сообщения не записывается повторно
+func (r *RobotService) AddData(data entities.RobotsData) (*entities.IngestResult, error) {
+	result := r.AddBatch([]entities.RobotsData{data})[0]
+	if result.Err != nil {
+		return nil, result.Err
 	}
-	if err := r.repo.AddData(data); err != nil {
-		return err
+	return &result, nil
+}
+
+// пакетная загрузка сообщений из буфера робота, результаты идут в порядке входных данных
+func (r *RobotService) AddBatch(batch []entities.RobotsData) []entities.IngestResult {
+	results := make([]entities.IngestResult, 0, len(batch))
+	known := make(map[string]bool)
+
+	for start := 0; start < len(batch); start += robotBatchChunk {
+		end := min(start+robotBatchChunk, len(batch))
+
+		chunk := make([]entities.IngestResult, end-start)
+		items := make([]entities.IngestItem, 0, end-start)
+		positions := make([]int, 0, end-start)
+		for i, data := range batch[start:end] {
+			// Проверка валидности данных
+			valid, ok := known[data.RobotId]
+			if !ok {
+				valid = r.repo.CheckId(data.RobotId)
+				known[data.RobotId] = valid
+			}
+			if !valid {
+				chunk[i] = rejected(fmt.Errorf("%w: invalid robot id: %s", entities.ErrValidation, data.RobotId))
+				continue
+			}
+
+			// без message_id от робота повтор определяется по роботу и времени сканирования
+			if data.MessageID == "" {
+				data.MessageID = data.RobotId + "@" + data.Timestamp.UTC().Format(time.RFC3339Nano)
+			}
+			messageID, err := newMessageID()
+			if err != nil {
+				chunk[i] = rejected(err)
+				continue
+			}
+			items = append(items, entities.IngestItem{Data: data, MessageID: messageID})
+			positions = append(positions, i)
+		}
+
+		if len(items) > 0 {
+			saved, err := r.repo.AddData(items)
+			for j, item := range items {
+				pos := positions[j]
+				switch {
+				case err != nil: // транзакция пачки не прошла, робот может повторить эти сообщения
+					chunk[pos] = rejected(err)
+				case saved[j].Duplicate:
+					logrus.Infof("duplicate message %s from robot %s, already stored as %s", item.Data.MessageID, item.Data.RobotId, saved[j].MessageID)
+					chunk[pos] = saved[j]
+				default:
+					chunk[pos] = saved[j]
+					if saved[j].Error == "" {
+						data := withComputedStatuses(item.Data, saved[j].Statuses)
+						r.notify(withoutAnomalies(data, saved[j].Anomalies))
+						publishAlerts(r.events, saved[j].Alerts)
+					}
+				}
+			}
+		}
+
+		results = append(results, chunk...)
 	}
 
+	return results
+}
+
+func rejected(err error) entities.IngestResult {
+	return entities.IngestResult{Error: err.Error(), Err: err}
+}
+
+// обновление статуса робота и рассылка события дашбордам
+func (r *RobotService) notify(data entities.RobotsData) {
 	if r.redis != nil {
 		r.redis.SetRobotOnline(data.RobotId)
 		r.redis.SetRobotBattery(data.RobotId, data.BatteryLevel, 30*time.Second)
@@ -50,6 +122,7 @@ func (r *RobotService) AddData(data entities.RobotsData) error {
 		event := map[string]interface{}{
 			"type":      "robot_data",
 			"robot_id":  data.RobotId,
+			"zone":      data.Location.Zone,
 			"battery":   data.BatteryLevel,
 			"status":    "active",
 			"online":    true,
@@ -60,10 +133,166 @@ func (r *RobotService) AddData(data entities.RobotsData) error {
 		r.redis.Publish("robot_updates", string(eventJSON))
 	}
 
-	r.made <- data
-	return nil
+	r.events.Publish(data)
+}
+
+// дашборды получают статусы, вычисленные сервером, расхождения с роботом логируются
+func withComputedStatuses(data entities.RobotsData, statuses []string) entities.RobotsData {
+	if len(statuses) != len(data.ScanResults) {
+		return data
+	}
+	scans := make([]entities.ScanResults, len(data.ScanResults))
+	for i, scan := range data.ScanResults {
+		if scan.Status != "" && scan.Status != statuses[i] {
+			logrus.Warnf("robot %s reported %s for product %s, computed %s", data.RobotId, scan.Status, scan.ProductId, statuses[i])
+		}
+		scan.Status = statuses[i]
+		scans[i] = scan
+	}
+	data.ScanResults = scans
+	return data
+}
+
+// выбросы остаются в истории, но не рассылаются дашбордам и не вызывают оповещений
+func withoutAnomalies(data entities.RobotsData, anomalies []bool) entities.RobotsData {
+	if len(anomalies) != len(data.ScanResults) {
+		return data
+	}
+	scans := make([]entities.ScanResults, 0, len(data.ScanResults))
+	for i, scan := range data.ScanResults {
+		if anomalies[i] {
+			logrus.Warnf("robot %s scan of %s at %s: quantity %d flagged as anomaly", data.RobotId, scan.ProductId, data.Location.Zone, scan.Quantity)
+			continue
+		}
+		scans = append(scans, scan)
+	}
+	data.ScanResults = scans
+	return data
+}
+
+// серверный id сообщения, сохраняется с каждым сканированием
+func newMessageID() (string, error) {
+	id, err := randomHex(12)
+	if err != nil {
+		return "", err
+	}
+	return "msg_" + id, nil
 }
 
 func (r *RobotService) CheckId(robotID string) bool {
 	return r.repo.CheckId(robotID)
 }
+
+// список роботов реестра без служебного робота импорта
+func (r *RobotService) ListRobots() ([]models.Robots, error) {
+	robots, err := r.repo.ListRobots()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Robots, 0, len(robots))
+	for _, robot := range robots {
+		if robot.ID != models.ImportRobotID {
+			result = append(result, robot)
+		}
+	}
+	return result, nil
+}
+
+func (r *RobotService) GetRobot(robotID string) (*models.Robots, error) {
+	if robotID == models.ImportRobotID {
+		return nil, entities.ErrNotFound
+	}
+	return r.repo.GetRobot(robotID)
+}
+
+// регистрация робота в реестре
+func (r *RobotService) RegisterRobot(input entities.RobotRegistration) (*models.Robots, error) {
+	robotID := strings.TrimSpace(input.ID)
+	if robotID == "" || robotID == models.ImportRobotID {
+		return nil, fmt.Errorf("%w: invalid robot id %q", entities.ErrValidation, input.ID)
+	}
+
+	status := input.Status
+	if status == "" {
+		status = models.RobotStatusActive
+	}
+	if err := validateRobotStatus(status); err != nil {
+		return nil, err
+	}
+
+	robot := &models.Robots{
+		ID:           robotID,
+		Status:       status,
+		LastUpdate:   time.Now(),
+		CurrentZone:  input.CurrentZone,
+		CurrentRow:   input.CurrentRow,
+		CurrentShelf: input.CurrentShelf,
+	}
+	if err := r.repo.CreateRobot(robot); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("robot %s registered", robot.ID)
+	return robot, nil
+}
+
+// обновление статуса и позиции робота
+func (r *RobotService) UpdateRobot(robotID string, input entities.RobotUpdate) (*models.Robots, error) {
+	robot, err := r.GetRobot(robotID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Status != nil {
+		if err := validateRobotStatus(*input.Status); err != nil {
+			return nil, err
+		}
+		robot.Status = *input.Status
+	}
+	if input.CurrentZone != nil {
+		robot.CurrentZone = *input.CurrentZone
+	}
+	if input.CurrentRow != nil {
+		robot.CurrentRow = *input.CurrentRow
+	}
+	if input.CurrentShelf != nil {
+		robot.CurrentShelf = *input.CurrentShelf
+	}
+	robot.LastUpdate = time.Now()
+
+	if err := r.repo.UpdateRobot(robot); err != nil {
+		return nil, err
+	}
+	return robot, nil
+}
+
+// списание робота: история сохраняется, прием данных прекращается
+func (r *RobotService) DecommissionRobot(robotID string) (*models.Robots, error) {
+	status := models.RobotStatusDecommissioned
+	robot, err := r.UpdateRobot(robotID, entities.RobotUpdate{Status: &status})
+	if err != nil {
+		return nil, err
+	}
+
+	if r.redis != nil {
+		r.redis.SetRobotStatus(robotID, status, 0)
+	}
+	return robot, nil
+}
+
+func (r *RobotService) DeleteRobot(robotID string) error {
+	if robotID == models.ImportRobotID {
+		return entities.ErrNotFound
+	}
+	return r.repo.DeleteRobot(robotID)
+}
+
+func validateRobotStatus(status string) error {
+	switch status {
+	case models.RobotStatusActive, models.RobotStatusMaintenance,
+		models.RobotStatusOffline, models.RobotStatusDecommissioned:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown robot status %q", entities.ErrValidation, status)
+}
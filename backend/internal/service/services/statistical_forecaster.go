@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+)
+
+const (
+	ProviderStatistical = "statistical"
+
+	smoothingAlpha  = 0.3   // вес последнего интервала в экспоненциальном сглаживании расхода
+	maxForecastDays = 365   // горизонт, если расход не наблюдается
+	minSamples      = 2     // точек на товар для расчета расхода
+	fullSamples     = 10    // с этого числа точек уверенность не штрафуется за размер выборки
+	minConfidence   = 0.1   // нижняя граница уверенности прогноза
+	maxConfidence   = 0.95  // верхняя граница, статистика не бывает полностью уверенной
+	minInterval     = 0.001 // интервалы короче ~1.5 минут не учитываются (в днях)
+)
+
+// локальный прогноз по временному ряду остатков, работает без внешних сервисов.
+// Расход считается по снижениям остатка (пополнения не уменьшают расход),
+// сглаживается экспоненциально, уверенность падает с ростом разброса расхода.
+type StatisticalForecaster struct{}
+
+func NewStatisticalForecaster() *StatisticalForecaster {
+	return &StatisticalForecaster{}
+}
+
+func (f *StatisticalForecaster) Name() string {
+	return ProviderStatistical
+}
+
+func (f *StatisticalForecaster) Forecast(_ context.Context, rq entities.AIRequest, history []models.InventoryHistory) (*entities.AIResponse, error) {
+	series := make(map[string][]models.InventoryHistory)
+	var order []string
+	for _, h := range history {
+		if _, ok := series[h.ProductID]; !ok {
+			order = append(order, h.ProductID)
+		}
+		series[h.ProductID] = append(series[h.ProductID], h)
+	}
+	sort.Strings(order)
+
+	response := &entities.AIResponse{Predictions: []entities.Predictions{}, Provider: ProviderStatistical}
+	today := time.Now().Format("2006-01-02")
+	total := 0.0
+	for _, productID := range order {
+		points := series[productID]
+		sort.Slice(points, func(i, j int) bool { return points[i].ScannedAt.Before(points[j].ScannedAt) })
+
+		prediction := forecastProduct(points, rq.PeriodDays)
+		prediction.PredictionDate = today
+		response.Predictions = append(response.Predictions, prediction)
+		total += prediction.ConfidenceScore
+	}
+	if len(response.Predictions) > 0 {
+		response.Confidence = round2(total / float64(len(response.Predictions)))
+	}
+
+	return response, nil
+}
+
+// прогноз по отсортированному по времени ряду одного товара.
+// Товар может лежать в нескольких ячейках: расход считается по ряду каждой ячейки
+// и складывается, текущий остаток - сумма последних сканирований ячеек.
+func forecastProduct(points []models.InventoryHistory, periodDays int) entities.Predictions {
+	last := points[len(points)-1]
+	product := last.Product
+	prediction := entities.Predictions{
+		ProductID:   last.ProductID,
+		ProductName: product.Name,
+	}
+
+	current, rate, confidence := 0.0, 0.0, 0.0
+	intervals := 0
+	for _, cell := range splitByLocation(points) {
+		current += float64(cell[len(cell)-1].Quantity)
+
+		rates := consumptionRates(cell)
+		cellRate, cellConfidence := smoothedRate(rates)
+		rate += cellRate
+		// уверенность ячеек взвешивается числом интервалов в их рядах
+		confidence += cellConfidence * float64(len(rates))
+		intervals += len(rates)
+	}
+	if intervals > 0 {
+		confidence /= float64(intervals)
+	} else {
+		confidence = minConfidence
+	}
+
+	reserve := math.Max(0, current-float64(product.MinStock))
+	switch {
+	case rate <= 0:
+		prediction.DaysUntilStockout = maxForecastDays
+	default:
+		prediction.DaysUntilStockout = int(math.Min(maxForecastDays, math.Floor(reserve/rate)))
+	}
+
+	// заказ покрывает расход за период и возвращает остаток к оптимальному
+	need := float64(product.OptimalStock) + rate*float64(periodDays) - current
+	prediction.RecommendedOrder = int(math.Max(0, math.Ceil(need)))
+
+	// маленькая выборка снижает уверенность
+	samples := math.Min(1, float64(len(points))/fullSamples)
+	prediction.ConfidenceScore = round2(clamp(confidence*samples, minConfidence, maxConfidence))
+	return prediction
+}
+
+// ряды сканирований по ячейкам, порядок по времени сохраняется
+func splitByLocation(points []models.InventoryHistory) [][]models.InventoryHistory {
+	type cell struct {
+		zone       string
+		row, shelf int
+	}
+	index := make(map[cell]int)
+	var cells [][]models.InventoryHistory
+	for _, p := range points {
+		key := cell{p.Zone, p.RowNumber, p.ShelfNumber}
+		i, ok := index[key]
+		if !ok {
+			i = len(cells)
+			index[key] = i
+			cells = append(cells, nil)
+		}
+		cells[i] = append(cells[i], p)
+	}
+	return cells
+}
+
+// расход в единицах в день на каждом интервале между сканированиями
+func consumptionRates(points []models.InventoryHistory) []float64 {
+	if len(points) < minSamples {
+		return nil
+	}
+	rates := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		days := points[i].ScannedAt.Sub(points[i-1].ScannedAt).Hours() / 24
+		if days < minInterval {
+			continue
+		}
+		used := math.Max(0, float64(points[i-1].Quantity-points[i].Quantity))
+		rates = append(rates, used/days)
+	}
+	return rates
+}
+
+// экспоненциально сглаженный расход и уверенность по коэффициенту вариации
+func smoothedRate(rates []float64) (float64, float64) {
+	if len(rates) == 0 {
+		return 0, minConfidence
+	}
+
+	smoothed := rates[0]
+	mean := 0.0
+	for i, r := range rates {
+		if i > 0 {
+			smoothed = smoothingAlpha*r + (1-smoothingAlpha)*smoothed
+		}
+		mean += r
+	}
+	mean /= float64(len(rates))
+	if mean == 0 {
+		// расхода нет, но ряд стабилен
+		return 0, maxConfidence
+	}
+
+	variance := 0.0
+	for _, r := range rates {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(rates))
+	cv := math.Sqrt(variance) / mean
+
+	return smoothed, 1 / (1 + cv)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+)
+
+// типы событий дашборда
+const (
+	StreamRobotUpdate    = "robot_update"    // сканирование робота
+	StreamRobotData      = "robot_data"      // статус робота из Redis
+	StreamInventoryAlert = "inventory_alert" // новое или усиленное оповещение
+)
+
+const maxStreamSubscriptions = 20
+
+// поля события, по которым фильтруются подписки
+type StreamEvent struct {
+	Type     string
+	Zone     string
+	RobotID  string
+	Category string
+	Severity string
+}
+
+// поля события хаба, ok - событие отправляется дашбордам
+func DescribeEvent(event interface{}) (StreamEvent, bool) {
+	switch event := event.(type) {
+	case entities.RobotsData:
+		return StreamEvent{Type: StreamRobotUpdate, Zone: event.Location.Zone, RobotID: event.RobotId}, true
+	case models.Alert:
+		return StreamEvent{
+			Type:     StreamInventoryAlert,
+			Zone:     event.Zone,
+			RobotID:  event.RobotID,
+			Category: event.Category,
+			Severity: event.Severity,
+		}, true
+	}
+	return StreamEvent{}, false
+}
+
+// поля статуса робота из Redis, он публикуется уже в формате дашборда
+func DescribeRobotData(payload string) StreamEvent {
+	var data struct {
+		RobotID string `json:"robot_id"`
+		Zone    string `json:"zone"`
+	}
+	_ = json.Unmarshal([]byte(payload), &data)
+	return StreamEvent{Type: StreamRobotData, Zone: data.Zone, RobotID: data.RobotID}
+}
+
+// Подписки одного соединения. Пока клиент не подписался, он получает все события,
+// после первой подписки - только подходящие хотя бы под одну.
+// Не потокобезопасны: ими владеет горутина, которая пишет в соединение.
+type StreamSubscriptions struct {
+	filters  map[string]entities.StreamFilter
+	order    []string
+	filtered bool
+	nextID   int
+}
+
+func NewStreamSubscriptions() *StreamSubscriptions {
+	return &StreamSubscriptions{filters: make(map[string]entities.StreamFilter)}
+}
+
+func (s *StreamSubscriptions) Allows(event StreamEvent) bool {
+	if !s.filtered {
+		return true
+	}
+	for _, id := range s.order {
+		if filterMatches(s.filters[id], event) {
+			return true
+		}
+	}
+	return false
+}
+
+// выполнение команды клиента, ответ отправляется в то же соединение
+func (s *StreamSubscriptions) Apply(message []byte) interface{} {
+	var command entities.StreamCommand
+	if err := json.Unmarshal(message, &command); err != nil {
+		return streamError("invalid message: " + err.Error())
+	}
+
+	switch command.Action {
+	case "subscribe":
+		filter, err := normalizeStreamFilter(command.Filter)
+		if err != nil {
+			return streamError(err.Error())
+		}
+		id := command.ID
+		if _, exists := s.filters[id]; !exists {
+			if len(s.order) >= maxStreamSubscriptions {
+				return streamError(fmt.Sprintf("too many subscriptions, limit is %d", maxStreamSubscriptions))
+			}
+			if id == "" {
+				s.nextID++
+				id = fmt.Sprintf("sub-%d", s.nextID)
+			}
+			s.order = append(s.order, id)
+		}
+		s.filters[id] = filter // подписка с тем же id заменяется
+		s.filtered = true
+		return streamReply("subscribed", map[string]interface{}{"id": id, "filter": filter})
+
+	case "unsubscribe":
+		if command.ID == "" {
+			s.filters = make(map[string]entities.StreamFilter)
+			s.order = nil
+		} else {
+			if _, exists := s.filters[command.ID]; !exists {
+				return streamError(fmt.Sprintf("unknown subscription %q", command.ID))
+			}
+			delete(s.filters, command.ID)
+			for i, id := range s.order {
+				if id == command.ID {
+					s.order = append(s.order[:i], s.order[i+1:]...)
+					break
+				}
+			}
+		}
+		s.filtered = true // без подписок события не приходят, все события - подписка с пустым фильтром
+		return streamReply("unsubscribed", map[string]interface{}{"id": command.ID, "remaining": len(s.order)})
+
+	case "list":
+		return streamReply("subscriptions", s.list())
+
+	default:
+		return streamError(fmt.Sprintf("unknown action %q, expected subscribe, unsubscribe or list", command.Action))
+	}
+}
+
+func (s *StreamSubscriptions) list() []map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(s.order))
+	for _, id := range s.order {
+		list = append(list, map[string]interface{}{"id": id, "filter": s.filters[id]})
+	}
+	return list
+}
+
+func filterMatches(f entities.StreamFilter, event StreamEvent) bool {
+	return valueAllowed(f.Types, event.Type) &&
+		valueAllowed(f.Zones, event.Zone) &&
+		valueAllowed(f.Robots, event.RobotID) &&
+		valueAllowed(f.Categories, event.Category) &&
+		valueAllowed(f.Severities, event.Severity)
+}
+
+func valueAllowed(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
+
+// известные типы и важности, зоны и роботы в верхнем регистре
+func normalizeStreamFilter(f entities.StreamFilter) (entities.StreamFilter, error) {
+	for _, t := range f.Types {
+		if err := oneOf("event type", t, StreamRobotUpdate, StreamRobotData, StreamInventoryAlert); err != nil {
+			return f, err
+		}
+	}
+	for _, severity := range f.Severities {
+		if err := oneOf("severity", severity, models.SeverityCritical, models.SeverityWarning); err != nil {
+			return f, err
+		}
+	}
+	f.Zones = upperAll(f.Zones)
+	f.Robots = upperAll(f.Robots)
+	return f, nil
+}
+
+func upperAll(values []string) []string {
+	for i := range values {
+		values[i] = strings.ToUpper(strings.TrimSpace(values[i]))
+	}
+	return values
+}
+
+func streamReply(kind string, data interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": kind, "data": data}
+}
+
+func streamError(message string) map[string]interface{} {
+	return streamReply("error", map[string]interface{}{"message": message})
+}
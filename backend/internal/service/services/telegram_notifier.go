@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+)
+
+const defaultTelegramAPI = "https://api.telegram.org"
+
+// сообщение через HTTP API бота в стиле Telegram: POST {api}/bot{token}/sendMessage
+type TelegramNotifier struct {
+	client *http.Client
+	apiURL string
+	token  string
+}
+
+func NewTelegramNotifier(apiURL, token string, timeout time.Duration) *TelegramNotifier {
+	if apiURL == "" {
+		apiURL = defaultTelegramAPI
+	}
+	return &TelegramNotifier{
+		client: &http.Client{Timeout: timeout},
+		apiURL: strings.TrimRight(apiURL, "/"),
+		token:  token,
+	}
+}
+
+func (n *TelegramNotifier) Kind() string {
+	return models.ChannelTelegram
+}
+
+func (n *TelegramNotifier) Send(ctx context.Context, notification Notification) error {
+	subject, body := notificationText(notification.Alert)
+	for _, chatID := range notification.Channel.Recipients() {
+		if err := n.sendMessage(ctx, chatID, subject+"\n\n"+body); err != nil {
+			return fmt.Errorf("chat %s: %w", chatID, err)
+		}
+	}
+	return nil
+}
+
+func (n *TelegramNotifier) sendMessage(ctx context.Context, chatID, text string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.apiURL+"/bot"+n.token+"/sendMessage", bytes.NewReader(payload))
+	if err != nil {
+		return stripURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return stripURL(err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("bot api responded %d: %w", resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("bot api responded %d: %s", resp.StatusCode, result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+)
+
+const (
+	WebhookEventHeader     = "X-Warehouse-Event"
+	WebhookDeliveryHeader  = "X-Warehouse-Delivery"
+	WebhookTimestampHeader = "X-Warehouse-Timestamp"
+	WebhookSignatureHeader = "X-Warehouse-Signature"
+)
+
+// тело запроса вебхука
+type WebhookPayload struct {
+	Event      string       `json:"event"`
+	DeliveryID uint64       `json:"delivery_id"`
+	Alert      models.Alert `json:"alert"`
+	SentAt     time.Time    `json:"sent_at"`
+}
+
+// POST на url канала, тело подписано секретом канала
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{client: &http.Client{Timeout: timeout}}
+}
+
+func (n *WebhookNotifier) Kind() string {
+	return models.ChannelWebhook
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(WebhookPayload{
+		Event:      "alert",
+		DeliveryID: notification.DeliveryID,
+		Alert:      notification.Alert,
+		SentAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notification.Channel.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, "alert")
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(notification.DeliveryID, 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(notification.Channel.Secret, timestamp, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook responded %d: %s", resp.StatusCode, bytes.TrimSpace(text))
+	}
+	return nil
+}
+
+// SignWebhookPayload считает подпись вебхука: hex от HMAC-SHA256 секрета канала
+// по строке "timestamp.body". Получатель сверяет ее с заголовком X-Warehouse-Signature.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -7,47 +7,95 @@ import (
 	"time"
 
 	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
 	"github.com/Senpa1k/Smart_Warehouse/internal/repository"
 	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	robotUpdatesChannel = "robot_updates"
+	streamReadLimit     = 4096 // команды подписки небольшие
+)
+
 type WebsocketDashBoardService struct {
-	repo repository.WebsocketDashBoard
-	made <-chan interface{}
+	hub   *EventHub
+	redis repository.Redis
 }
 
-func NewWebsocketDashBoard(repo repository.WebsocketDashBoard, made <-chan interface{}) *WebsocketDashBoardService {
-	return &WebsocketDashBoardService{repo: repo, made: made}
+func NewWebsocketDashBoard(hub *EventHub, redis repository.Redis) *WebsocketDashBoardService {
+	return &WebsocketDashBoardService{hub: hub, redis: redis}
 }
 
-// управление соединением с dashboard
+// Управление соединением с dashboard. В соединение пишет одна горутина:
+// события хаба, статусы роботов из Redis и ответы на команды подписки.
 func (r *WebsocketDashBoardService) RunStream(conn *websocket.Conn) {
+	sub := r.hub.Subscribe()
+	defer r.hub.Unsubscribe(sub)
+
+	var relay <-chan *redis.Message // без Redis канал остается nil и не выбирается
+	if r.redis != nil {
+		pubsub := r.redis.Subscribe(robotUpdatesChannel)
+		defer pubsub.Close()
+		relay = pubsub.Channel()
+	}
+
+	commands := make(chan []byte)
 	done := make(chan struct{})
 	var wg sync.WaitGroup
 
-	wg.Add(2)
+	wg.Add(3)
 
 	go func() {
 		defer wg.Done()
-	out:
+		subscriptions := NewStreamSubscriptions()
 		for {
+			var err error
 			select {
-			case who := <-r.made: // либо робот либо аи преддикты
-				if scan, ok := who.(entities.RobotsData); ok { // пришли данные от робота
-					if err := r.ScannedRobotSend(conn, scan); err != nil {
-						logrus.Print("Websocket was closed")
-						break
-					}
-				} else if scan, ok := who.(entities.AIResponse); ok { // аи предикт AIResponse
-					if err := r.ScannedAiSend(conn, scan); err != nil {
-						logrus.Print("Websocket was closed")
-						break
-					}
+			case who, ok := <-sub.Events(): // либо робот либо оповещение
+				if !ok { // хаб отключил медленного клиента
+					conn.Close()
+					return
+				}
+				if event, known := DescribeEvent(who); known && subscriptions.Allows(event) {
+					err = r.send(conn, who)
 				}
+			case msg, ok := <-relay:
+				if !ok {
+					relay = nil
+					continue
+				}
+				if subscriptions.Allows(DescribeRobotData(msg.Payload)) {
+					err = conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload))
+				}
+			case command := <-commands:
+				err = conn.WriteJSON(subscriptions.Apply(command))
+			case <-done:
+				return
+			}
+			if err != nil {
+				logrus.Print("Websocket was closed")
+				conn.Close()
+				return
+			}
+		}
+	}()
 
+	// команды подписки от клиента, чтение также обрабатывает pong и close
+	go func() {
+		defer wg.Done()
+		conn.SetReadLimit(streamReadLimit)
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				conn.Close()
+				return
+			}
+			select {
+			case commands <- message:
 			case <-done:
-				break out
+				return
 			}
 		}
 	}()
@@ -57,11 +105,9 @@ func (r *WebsocketDashBoardService) RunStream(conn *websocket.Conn) {
 		defer wg.Done()
 		defer close(done)
 		for {
-			conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
-			err := conn.WriteMessage(websocket.PingMessage, nil)
-			if err != nil {
-				done <- struct{}{}
-				break
+			// WriteControl можно вызывать параллельно с отправкой событий
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(2*time.Second)); err != nil {
+				return
 			}
 
 			time.Sleep(1 * time.Second)
@@ -71,57 +117,57 @@ func (r *WebsocketDashBoardService) RunStream(conn *websocket.Conn) {
 	wg.Wait()
 }
 
+// отправка события в соединение в зависимости от его типа
+func (r *WebsocketDashBoardService) send(conn *websocket.Conn, event interface{}) error {
+	switch event := event.(type) {
+	case entities.RobotsData: // пришли данные от робота
+		return r.ScannedRobotSend(conn, event)
+	case models.Alert: // правило открыло оповещение или повысило его важность
+		return r.AlertSend(conn, event)
+	}
+	return nil
+}
+
 // отправка данных о сканировании роботом
 func (r *WebsocketDashBoardService) ScannedRobotSend(conn *websocket.Conn, scan entities.RobotsData) error {
 	result := entities.UpdateRobot{}
 	updateRobot(&result, &scan)
-	err := conn.WriteJSON(map[string]interface{}{
+	return conn.WriteJSON(map[string]interface{}{
 		"type": "robot_update",
 		"data": result,
 	})
-	if err != nil {
-		return err
-	}
+}
 
-	// обработка результатов сканирования
-	result2 := entities.InventoryAlert{}
-	for _, scanResult := range scan.ScanResults {
-		if scanResult.Status != "OK" {
-			if status := r.repo.InventoryAlertScanned(&result2, scan.Timestamp, scanResult.ProductId); status == nil {
-				err := conn.WriteJSON(map[string]interface{}{
-					"type": "inventory_alert",
-					"data": result2,
-				})
-				if err != nil {
-					return err
-				}
-			} else {
-				logrus.Print(status)
-			}
-		}
-	}
-	return nil
+// отправка оповещения в прежнем формате inventory_alert
+func (r *WebsocketDashBoardService) AlertSend(conn *websocket.Conn, alert models.Alert) error {
+	return conn.WriteJSON(map[string]interface{}{
+		"type": "inventory_alert",
+		"data": inventoryAlert(alert),
+	})
 }
 
-// отправка данных о ии прогнозах
-func (r *WebsocketDashBoardService) ScannedAiSend(conn *websocket.Conn, scan entities.AIResponse) error {
-	result := entities.InventoryAlert{}
-
-	// обработка прогноза
-	for _, predict := range scan.Predictions {
-		if err := r.repo.InventoryAlertPredict(&result, predict); err == nil {
-			err_req := conn.WriteJSON(map[string]interface{}{
-				"type": "inventory_alert",
-				"data": result,
-			})
-			if err_req != nil {
-				return err_req
-			}
-		} else {
-			logrus.Print(err)
-		}
+func inventoryAlert(alert models.Alert) entities.InventoryAlert {
+	result := entities.InventoryAlert{Type: "inventory_alert"}
+	result.Data.AlertID = alert.ID
+	result.Data.Severity = alert.Severity
+	result.Data.ProductId = alert.ProductID
+	result.Data.ProductName = alert.ProductName
+	result.Data.Category = alert.Category
+	result.Data.RobotID = alert.RobotID
+	result.Data.CurrentQuantity = alert.Quantity
+	result.Data.Zone = alert.Zone
+	result.Data.Row = alert.RowNumber
+	result.Data.Shelf = alert.ShelfNumber
+	result.Data.Status = models.StockLow
+	if alert.Severity == models.SeverityCritical {
+		result.Data.Status = models.StockCritical
 	}
-	return nil
+	result.Data.AlterType = alert.Type
+	result.Data.Timestamp = alert.ScannedAt
+	result.Data.Message = alert.Message
+	result.Data.Occurrences = alert.Occurrences
+	result.Data.Tier = alert.Tier
+	return result
 }
 
 // функция для приведения данных о роботе в удобный для обработки вид
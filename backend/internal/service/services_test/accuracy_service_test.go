@@ -0,0 +1,140 @@
+package test_services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEvaluatePredictions(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	observedAt := now.AddDate(0, 0, -20)
+	recentAt := now.AddDate(0, 0, -3)
+	expiredAt := now.AddDate(0, 0, -30)
+
+	repo := new(MockAccuracyRepo)
+	repo.On("PendingPredictions").Return([]models.AiPrediction{
+		{ID: 1, ProductID: "TEL-4567", DaysUntilStockout: 7, CreatedAt: observedAt},
+		{ID: 2, ProductID: "TEL-8901", DaysUntilStockout: 10, CreatedAt: recentAt},
+		{ID: 3, ProductID: "TEL-2345", DaysUntilStockout: 5, CreatedAt: expiredAt},
+	}, nil)
+	stockout := observedAt.Add(4*24*time.Hour + time.Hour)
+	repo.On("FirstStockout", "TEL-4567", observedAt).Return(&stockout, nil)
+	repo.On("FirstStockout", "TEL-8901", recentAt).Return(nil, nil)
+	repo.On("FirstStockout", "TEL-2345", expiredAt).Return(nil, nil)
+
+	var saved []models.AiPrediction
+	repo.On("SaveOutcome", mock.AnythingOfType("*models.AiPrediction")).
+		Run(func(args mock.Arguments) { saved = append(saved, *args.Get(0).(*models.AiPrediction)) }).
+		Return(nil)
+
+	service := services.NewAccuracyService(repo, nil, nil)
+	n, err := service.EvaluatePredictions(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	if assert.Len(t, saved, 2) {
+		// нехватка наступила на 4-й день
+		assert.Equal(t, uint(1), saved[0].ID)
+		assert.Equal(t, 4, *saved[0].ActualDays)
+		assert.Equal(t, &stockout, saved[0].ActualStockoutAt)
+		assert.Equal(t, now, *saved[0].EvaluatedAt)
+
+		// нехватки не было, прогноз оценен без факта
+		assert.Equal(t, uint(3), saved[1].ID)
+		assert.Nil(t, saved[1].ActualDays)
+		assert.Nil(t, saved[1].ActualStockoutAt)
+		assert.Equal(t, now, *saved[1].EvaluatedAt)
+	}
+}
+
+func TestEvaluatePredictionsRepoError(t *testing.T) {
+	repo := new(MockAccuracyRepo)
+	repo.On("PendingPredictions").Return(nil, errors.New("db down"))
+
+	_, err := services.NewAccuracyService(repo, nil, nil).EvaluatePredictions(time.Now())
+	assert.Error(t, err)
+}
+
+func TestAccuracyRunTakesLease(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // одна итерация
+
+	t.Run("another replica holds the lease", func(t *testing.T) {
+		repo := new(MockAccuracyRepo)
+		locks := new(MockLockRepo)
+		locks.On("TryLock", "lock:ai:accuracy", mock.Anything, 54*time.Minute).Return(false, nil).Once()
+
+		services.NewAccuracyService(repo, locks, nil).Run(ctx)
+		repo.AssertNotCalled(t, "PendingPredictions")
+		locks.AssertExpectations(t)
+	})
+
+	t.Run("lease holder evaluates", func(t *testing.T) {
+		repo := new(MockAccuracyRepo)
+		locks := new(MockLockRepo)
+		locks.On("TryLock", "lock:ai:accuracy", mock.Anything, 54*time.Minute).Return(true, nil).Once()
+		repo.On("PendingPredictions").Return([]models.AiPrediction{}, nil).Once()
+
+		services.NewAccuracyService(repo, locks, nil).Run(ctx)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestAccuracyReport(t *testing.T) {
+	days := func(v int) *int { return &v }
+	stockout := time.Now()
+	routers := models.Products{ID: "TEL-4567", Name: "Роутер", Category: "Сетевое оборудование"}
+	modems := models.Products{ID: "TEL-8901", Name: "Модем", Category: "Сетевое оборудование"}
+	cables := models.Products{ID: "CAB-0001", Name: "Кабель"}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	repo := new(MockAccuracyRepo)
+	repo.On("EvaluatedPredictions", from, to).Return([]models.AiPrediction{
+		{ProductID: "TEL-4567", Provider: "gigachat", DaysUntilStockout: 7, ActualDays: days(4), ActualStockoutAt: &stockout, AIPredictionProduct: routers},
+		{ProductID: "TEL-4567", Provider: "statistical", DaysUntilStockout: 5, ActualDays: days(4), ActualStockoutAt: &stockout, AIPredictionProduct: routers},
+		{ProductID: "TEL-8901", Provider: "statistical", DaysUntilStockout: 3, AIPredictionProduct: modems},
+		{ProductID: "CAB-0001", DaysUntilStockout: 6, ActualDays: days(6), ActualStockoutAt: &stockout, AIPredictionProduct: cables},
+	}, nil)
+
+	report, err := services.NewAccuracyService(repo, nil, nil).Report(entities.AccuracyQuery{From: "2025-01-01", To: "2025-01-31"})
+	assert.NoError(t, err)
+
+	// ошибки: +3, +1, 0, прогноз по модему цензурирован и в ошибки не входит
+	assert.Equal(t, 2, report.ToleranceDays)
+	assert.Equal(t, entities.AccuracyMetrics{Key: "all", Evaluated: 4, Observed: 3, Censored: 1, MAE: 1.33, Bias: 1.33, HitRate: 0.67}, report.Overall)
+	assert.Equal(t, []entities.AccuracyMetrics{
+		{Key: "gigachat", Evaluated: 1, Observed: 1, MAE: 3, Bias: 3, HitRate: 0},
+		{Key: "statistical", Evaluated: 2, Observed: 1, Censored: 1, MAE: 1, Bias: 1, HitRate: 1},
+		{Key: "unknown", Evaluated: 1, Observed: 1, MAE: 0, Bias: 0, HitRate: 1},
+	}, report.Providers)
+	assert.Equal(t, []entities.AccuracyMetrics{
+		{Key: "uncategorized", Evaluated: 1, Observed: 1, HitRate: 1},
+		{Key: "Сетевое оборудование", Evaluated: 3, Observed: 2, Censored: 1, MAE: 2, Bias: 2, HitRate: 0.5},
+	}, report.Categories)
+	assert.Equal(t, "CAB-0001", report.Products[0].Key)
+	assert.Equal(t, entities.AccuracyMetrics{Key: "TEL-4567", Name: "Роутер", Evaluated: 2, Observed: 2, MAE: 2, Bias: 2, HitRate: 0.5}, report.Products[1])
+}
+
+func TestAccuracyReportValidation(t *testing.T) {
+	service := services.NewAccuracyService(new(MockAccuracyRepo), nil, nil)
+	negative := -1
+
+	for _, query := range []entities.AccuracyQuery{
+		{From: "01.01.2025"},
+		{To: "2025-13-01"},
+		{From: "2025-02-01", To: "2025-01-01"},
+		{Tolerance: &negative},
+	} {
+		_, err := service.Report(query)
+		assert.ErrorIs(t, err, entities.ErrValidation)
+	}
+}
@@ -0,0 +1,131 @@
+package test_services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fixedForecaster всегда возвращает заданный прогноз
+type fixedForecaster struct {
+	response *entities.AIResponse
+	err      error
+	history  []models.InventoryHistory
+}
+
+func (f *fixedForecaster) Name() string { return "fixed" }
+
+func (f *fixedForecaster) Forecast(_ context.Context, _ entities.AIRequest, history []models.InventoryHistory) (*entities.AIResponse, error) {
+	f.history = history
+	return f.response, f.err
+}
+
+func TestPredictUsesForecaster(t *testing.T) {
+	repo := new(MockAIRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+
+	response := &entities.AIResponse{
+		Predictions: []entities.Predictions{{ProductID: "TEL-4567", DaysUntilStockout: 3}},
+		Confidence:  0.8,
+		Provider:    "fixed",
+	}
+	forecaster := &fixedForecaster{response: response}
+	s := services.NewAIService(repo, forecaster, hub, nil)
+
+	rq := entities.AIRequest{PeriodDays: 7, Categories: []string{"network"}}
+	history := []models.InventoryHistory{{ProductID: "TEL-4567", Quantity: 40}}
+	repo.On("AIRequest", rq, mock.AnythingOfType("entities.HistoryWindow")).Return(history, nil).Once()
+	repo.On("AIResponse", *response).Return(nil, nil).Once()
+
+	got, err := s.Predict(rq)
+	assert.NoError(t, err)
+	assert.Equal(t, response, got)
+	assert.Equal(t, history, forecaster.history)
+	// без новых оповещений в поток ничего не уходит
+	assert.Empty(t, sub.Events())
+	repo.AssertExpectations(t)
+}
+
+func TestPredictForecasterError(t *testing.T) {
+	repo := new(MockAIRepo)
+	s := services.NewAIService(repo, &fixedForecaster{err: errors.New("offline")}, services.NewEventHub(1, services.DropEvent), nil)
+
+	repo.On("AIRequest", mock.Anything, mock.Anything).Return([]models.InventoryHistory{}, nil).Once()
+
+	_, err := s.Predict(entities.AIRequest{PeriodDays: 7})
+	assert.ErrorContains(t, err, "fixed forecast failed: offline")
+	repo.AssertNotCalled(t, "AIResponse", mock.Anything)
+}
+
+func TestNewForecaster(t *testing.T) {
+	f, err := services.NewForecaster("sber", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, services.ProviderGigaChat, f.Name())
+
+	_, err = services.NewForecaster("oracle", nil)
+	assert.Error(t, err)
+
+	// опечатка в конфигурации не подменяется GigaChat
+	t.Setenv("AI_SERVICE", "gigachta")
+	_, err = services.NewForecasterFromConfig(nil)
+	assert.ErrorContains(t, err, "gigachta")
+
+	t.Setenv("AI_SERVICE", "statistical")
+	t.Setenv("AI_FALLBACK", "statstical")
+	_, err = services.NewForecasterFromConfig(nil)
+	assert.ErrorContains(t, err, "statstical")
+}
+
+func TestListPredictions(t *testing.T) {
+	repo := new(MockAIRepo)
+	s := services.NewAIService(repo, &fixedForecaster{}, services.NewEventHub(1, services.DropEvent), nil)
+
+	confidence := 0.7
+	query := entities.PredictionQuery{Category: "network", From: "2025-01-01", To: "2025-01-31", MinConfidence: &confidence, Limit: 50}
+	stored := []models.AiPrediction{{ID: 2, ProductID: "TEL-4567", ConfidenceScore: 0.8}}
+	repo.On("ListPredictions", query).Return(stored, int64(12), nil).Once()
+
+	got, err := s.ListPredictions(query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), got.Total)
+	assert.Equal(t, stored, got.Items)
+	assert.Equal(t, 50, got.Pagination.Limit)
+}
+
+func TestLatestPredictions(t *testing.T) {
+	repo := new(MockAIRepo)
+	s := services.NewAIService(repo, &fixedForecaster{}, services.NewEventHub(1, services.DropEvent), nil)
+
+	query := entities.PredictionQuery{ProductID: "TEL-4567"}
+	stored := []models.AiPrediction{{ID: 7, ProductID: "TEL-4567"}}
+	repo.On("LatestPredictions", query).Return(stored, nil).Once()
+
+	got, err := s.LatestPredictions(query)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, got)
+}
+
+func TestPredictionQueryValidation(t *testing.T) {
+	repo := new(MockAIRepo)
+	s := services.NewAIService(repo, &fixedForecaster{}, services.NewEventHub(1, services.DropEvent), nil)
+	high := 1.5
+
+	for _, query := range []entities.PredictionQuery{
+		{From: "01.01.2025"},
+		{From: "2025-02-01", To: "2025-01-01"},
+		{MinConfidence: &high},
+	} {
+		_, err := s.ListPredictions(query)
+		assert.ErrorIs(t, err, entities.ErrValidation)
+		_, err = s.LatestPredictions(query)
+		assert.ErrorIs(t, err, entities.ErrValidation)
+	}
+	repo.AssertNotCalled(t, "ListPredictions", mock.Anything)
+}
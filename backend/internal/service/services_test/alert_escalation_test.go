@@ -0,0 +1,75 @@
+package test_services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscalatePublishesEscalatedAlerts(t *testing.T) {
+	t.Setenv("ALERT_ESCALATION_AFTER", "5m")
+	repo := new(MockAlertRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+	s := services.NewAlertEscalationService(repo, hub, new(MockLockRepo), nil)
+
+	now := time.Now()
+	escalated := models.Alert{ID: 12, Severity: models.SeverityCritical, Status: models.AlertOpen, Tier: 2, TierSince: now}
+	repo.On("EscalateAlerts", now, 5*time.Minute).Return([]models.Alert{escalated}, nil).Once()
+	repo.On("EscalateAlerts", now.Add(time.Minute), 5*time.Minute).Return(nil, nil).Once()
+
+	n, err := s.Escalate(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	ev, _ := receive(t, sub)
+	assert.Equal(t, escalated, ev)
+
+	n, err = s.Escalate(now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	repo.AssertExpectations(t)
+}
+
+func TestEscalatedAlertGoesToNextTierOnly(t *testing.T) {
+	channels := []models.NotificationChannel{
+		{ID: 1, Kind: models.ChannelTelegram, Enabled: true, Tier: 1},
+		{ID: 2, Kind: models.ChannelEmail, Enabled: true, Tier: 2},
+		{ID: 3, Kind: models.ChannelWebhook, Enabled: true, Tier: 3},
+	}
+
+	alert := lowStockAlert
+	d := models.PendingDeliveries(channels, alert, time.Now())
+	if assert.Len(t, d, 1) {
+		assert.Equal(t, uint(1), *d[0].ChannelID)
+		assert.Equal(t, 1, d[0].Tier)
+	}
+
+	alert.Tier = 2
+	d = models.PendingDeliveries(channels, alert, time.Now())
+	if assert.Len(t, d, 1) {
+		assert.Equal(t, uint(2), *d[0].ChannelID)
+		assert.Equal(t, 2, d[0].Tier)
+	}
+}
+
+func TestNotificationTextShowsRepeatsAndEscalation(t *testing.T) {
+	addr, mails := smtpStub(t)
+
+	alert := lowStockAlert
+	alert.Occurrences = 31
+	alert.Tier = 2
+	notifier := services.NewEmailNotifier(addr, "", "", "warehouse@example.com", time.Second)
+	channel := models.NotificationChannel{Kind: models.ChannelEmail, Target: "head@example.com", Tier: 2}
+	assert.NoError(t, notifier.Send(context.Background(), services.Notification{Channel: channel, Alert: alert}))
+
+	message := (<-mails)[2]
+	assert.Contains(t, message, "Повторов: 31")
+	assert.Contains(t, message, "Эскалация: уровень 2")
+	subject := strings.SplitN(strings.SplitN(message, "Subject: ", 2)[1], "\n", 2)[0]
+	assert.Contains(t, subject, "ESCALATED")
+}
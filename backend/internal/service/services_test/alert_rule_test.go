@@ -0,0 +1,190 @@
+package test_services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAlertRuleFires(t *testing.T) {
+	now := time.Now()
+	cables := models.Products{ID: "CAB-001", Category: "Кабели", MinStock: 10, OptimalStock: 100, LeadTimeDays: 5}
+	cell := func(quantity int) models.RuleSubject {
+		return models.RuleSubject{Type: models.AlertTypeScanned, Product: cables, Zone: "A", RowNumber: 1, ShelfNumber: 2, Quantity: quantity, At: now}
+	}
+
+	tests := []struct {
+		name    string
+		rule    models.AlertRule
+		subject models.RuleSubject
+		fires   bool
+	}{
+		{"cell at min stock", models.AlertRule{Kind: models.RuleCellBelowMin}, cell(10), true},
+		{"cell above min stock", models.AlertRule{Kind: models.RuleCellBelowMin}, cell(11), false},
+		{"cell at half of optimal", models.AlertRule{Kind: models.RuleCellBelowOptimal, Threshold: 50}, cell(50), true},
+		{"cell above half of optimal", models.AlertRule{Kind: models.RuleCellBelowOptimal, Threshold: 50}, cell(51), false},
+		{"category below 30%", models.AlertRule{Kind: models.RuleProductBelowOptimal, Threshold: 30, Category: "Кабели"},
+			models.RuleSubject{Type: models.AlertTypeStock, Product: cables, Quantity: 25}, true},
+		{"other category", models.AlertRule{Kind: models.RuleProductBelowOptimal, Threshold: 30, Category: "Роутеры"},
+			models.RuleSubject{Type: models.AlertTypeStock, Product: cables, Quantity: 25}, false},
+		{"robot battery below 15%", models.AlertRule{Kind: models.RuleRobotBattery, Threshold: 15},
+			models.RuleSubject{Type: models.AlertTypeRobot, RobotID: "RB-001", BatteryLevel: 14}, true},
+		{"robot battery at 15%", models.AlertRule{Kind: models.RuleRobotBattery, Threshold: 15},
+			models.RuleSubject{Type: models.AlertTypeRobot, RobotID: "RB-001", BatteryLevel: 15}, false},
+		{"zone not scanned for 4 hours", models.AlertRule{Kind: models.RuleZoneNotScanned, Threshold: 4},
+			models.RuleSubject{Type: models.AlertTypeZone, Zone: "B", LastScanAt: now.Add(-5 * time.Hour), At: now.Add(-5 * time.Hour), CheckedAt: now}, true},
+		{"zone scanned recently", models.AlertRule{Kind: models.RuleZoneNotScanned, Threshold: 4},
+			models.RuleSubject{Type: models.AlertTypeZone, Zone: "B", LastScanAt: now.Add(-time.Hour), At: now.Add(-time.Hour), CheckedAt: now}, false},
+		{"stockout before lead time", models.AlertRule{Kind: models.RuleForecastBeforeLeadTime},
+			models.RuleSubject{Type: models.AlertTypePredicted, Product: cables, DaysUntilStockout: 4}, true},
+		{"stockout after lead time", models.AlertRule{Kind: models.RuleForecastBeforeLeadTime},
+			models.RuleSubject{Type: models.AlertTypePredicted, Product: cables, DaysUntilStockout: 5}, false},
+		{"zone filter", models.AlertRule{Kind: models.RuleCellBelowMin, Zone: "B"}, cell(0), false},
+		{"wrong subject type", models.AlertRule{Kind: models.RuleRobotBattery, Threshold: 15}, cell(0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Enabled = true
+			assert.Equal(t, tt.fires, tt.rule.Fires(tt.subject))
+		})
+	}
+
+	disabled := models.AlertRule{Kind: models.RuleCellBelowMin}
+	assert.False(t, disabled.Fires(cell(0)))
+}
+
+func TestAlertRuleKey(t *testing.T) {
+	subject := models.RuleSubject{
+		Type:    models.AlertTypeScanned,
+		Product: models.Products{ID: "TEL-4567", Category: "Роутеры"},
+		Zone:    "A", RowNumber: 12, ShelfNumber: 3,
+	}
+
+	critical := models.AlertRule{ID: 1, Kind: models.RuleCellBelowMin}
+	warning := models.AlertRule{ID: 2, Kind: models.RuleCellBelowOptimal, Threshold: 50}
+	assert.Equal(t, "cell:TEL-4567:A:12:3", critical.Key(subject))
+	assert.Equal(t, critical.Key(subject), warning.Key(subject), "rules of one cell share an alert")
+
+	perCategory := models.AlertRule{ID: 7, Kind: models.RuleCellBelowMin, DedupKey: "rule{rule}:{category}:{zone}"}
+	assert.Equal(t, "rule7:Роутеры:A", perCategory.Key(subject))
+
+	alert := warning.NewAlert(subject)
+	assert.Equal(t, models.AlertTypeScanned, alert.Type)
+	assert.Equal(t, models.AlertOpen, alert.Status)
+	assert.Equal(t, uint(2), *alert.RuleID)
+	assert.Equal(t, "cell:TEL-4567:A:12:3", alert.DedupKey)
+	assert.False(t, alert.ScannedAt.IsZero(), "subject without data time gets the creation time")
+
+	// время оповещения - время данных, а не проверки
+	scanned := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	subject.At = scanned
+	assert.Equal(t, scanned, warning.NewAlert(subject).ScannedAt)
+}
+
+func TestCreateAlertRuleValidates(t *testing.T) {
+	repo := new(MockAlertRuleRepo)
+	s := services.NewAlertRuleService(repo, services.NewEventHub(1, services.DropEvent), new(MockLockRepo), nil)
+
+	invalid := []entities.AlertRuleInput{
+		{Name: "unknown kind", Kind: "temperature_above", Severity: models.SeverityWarning},
+		{Name: "bad severity", Kind: models.RuleCellBelowMin, Severity: "info"},
+		{Name: "percent out of range", Kind: models.RuleProductBelowOptimal, Threshold: 130, Severity: models.SeverityWarning},
+		{Name: "no hours", Kind: models.RuleZoneNotScanned, Severity: models.SeverityWarning},
+		{Name: "zone of forecast", Kind: models.RuleForecastStockout, Threshold: 3, Zone: "A", Severity: models.SeverityWarning},
+		{Name: "category of robot", Kind: models.RuleRobotBattery, Threshold: 15, Category: "Кабели", Severity: models.SeverityWarning},
+		{Name: "unknown placeholder", Kind: models.RuleCellBelowMin, DedupKey: "{sku}", Severity: models.SeverityWarning},
+		{Name: "negative cooldown", Kind: models.RuleCellBelowMin, CooldownSeconds: -1, Severity: models.SeverityWarning},
+	}
+	for _, input := range invalid {
+		_, err := s.CreateRule(input)
+		assert.True(t, errors.Is(err, entities.ErrValidation), input.Name)
+	}
+	repo.AssertNotCalled(t, "CreateRule", mock.Anything)
+
+	repo.On("CreateRule", mock.MatchedBy(func(r *models.AlertRule) bool {
+		return r.Zone == "B" && r.Enabled && r.Threshold == 4
+	})).Return(nil).Once()
+	rule, err := s.CreateRule(entities.AlertRuleInput{
+		Name: "Зона B без сканирования", Kind: models.RuleZoneNotScanned, Threshold: 4,
+		Severity: models.SeverityWarning, Zone: " b ", CooldownSeconds: 3600,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.AlertTypeZone, rule.AlertType())
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateAlertRuleKeepsKind(t *testing.T) {
+	repo := new(MockAlertRuleRepo)
+	s := services.NewAlertRuleService(repo, services.NewEventHub(1, services.DropEvent), new(MockLockRepo), nil)
+
+	rule := &models.AlertRule{ID: 3, Name: "Заряд", Kind: models.RuleRobotBattery, Threshold: 15, Severity: models.SeverityWarning, Enabled: true}
+	repo.On("GetRule", uint(3)).Return(rule, nil)
+	repo.On("UpdateRule", rule).Return(nil).Once()
+
+	disabled := false
+	threshold := 20.0
+	updated, err := s.UpdateRule(3, entities.AlertRuleUpdate{Threshold: &threshold, Enabled: &disabled})
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, updated.Threshold)
+	assert.False(t, updated.Enabled)
+
+	tooHigh := 150.0
+	_, err = s.UpdateRule(3, entities.AlertRuleUpdate{Threshold: &tooHigh})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+	repo.AssertNumberOfCalls(t, "UpdateRule", 1)
+}
+
+func TestEvaluateRulesPublishesRaisedAlerts(t *testing.T) {
+	repo := new(MockAlertRuleRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+	s := services.NewAlertRuleService(repo, hub, new(MockLockRepo), nil)
+
+	now := time.Now()
+	raised := []models.Alert{{ID: 5, Type: models.AlertTypeRobot, RobotID: "RB-002", Severity: models.SeverityCritical}}
+	repo.On("EvaluateRules", now).Return(raised, nil).Once()
+
+	n, err := s.EvaluateRules(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	ev, _ := receive(t, sub)
+	assert.Equal(t, raised[0], ev)
+}
+
+func TestIngestionAndForecastPublishRaisedAlerts(t *testing.T) {
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+	alert := models.Alert{ID: 9, Type: models.AlertTypeScanned, ProductID: "TEL-4567", Severity: models.SeverityCritical}
+
+	robots := new(MockRobotRepo)
+	robots.On("CheckId", "RB-001").Return(true)
+	robots.On("AddData", mock.AnythingOfType("[]entities.IngestItem")).
+		Return([]entities.IngestResult{{MessageID: "msg_1", Statuses: []string{"CRITICAL"}, Alerts: []models.Alert{alert}}}, nil).Once()
+	_, err := services.NewRobotService(robots, hub, nil).AddData(entities.RobotsData{
+		RobotId:     "RB-001",
+		Timestamp:   time.Now(),
+		ScanResults: []entities.ScanResults{{ProductId: "TEL-4567", Quantity: 3}},
+	})
+	assert.NoError(t, err)
+	ev, _ := receive(t, sub)
+	assert.IsType(t, entities.RobotsData{}, ev)
+	ev, _ = receive(t, sub)
+	assert.Equal(t, alert, ev)
+
+	response := &entities.AIResponse{Predictions: []entities.Predictions{{ProductID: "TEL-4567", DaysUntilStockout: 1}}, Provider: "fixed"}
+	ai := new(MockAIRepo)
+	ai.On("AIRequest", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	ai.On("AIResponse", *response).Return([]models.Alert{alert}, nil).Once()
+	_, err = services.NewAIService(ai, &fixedForecaster{response: response}, hub, nil).Regenerate(entities.AIRequest{PeriodDays: 7})
+	assert.NoError(t, err)
+	// сам прогноз в поток не публикуется, только открытые по нему оповещения
+	ev, _ = receive(t, sub)
+	assert.Equal(t, alert, ev)
+	assert.Empty(t, sub.Events())
+}
@@ -0,0 +1,92 @@
+package test_services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListAlertsValidatesFilters(t *testing.T) {
+	repo := new(MockAlertRepo)
+	s := services.NewAlertService(repo, new(MockAuthRepo))
+
+	_, err := s.ListAlerts(entities.AlertQuery{Status: "closed"})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+	_, err = s.ListAlerts(entities.AlertQuery{Severity: "info"})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+	repo.AssertNotCalled(t, "ListAlerts", mock.Anything)
+
+	query := entities.AlertQuery{Status: models.AlertOpen, Limit: 50}
+	repo.On("ListAlerts", query).Return([]models.Alert{{ID: 1}}, int64(7), nil).Once()
+	response, err := s.ListAlerts(query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), response.Total)
+	assert.Equal(t, 50, response.Pagination.Limit)
+}
+
+func TestAcknowledgeAlertAssignsAcknowledgingUser(t *testing.T) {
+	repo := new(MockAlertRepo)
+	s := services.NewAlertService(repo, new(MockAuthRepo))
+
+	repo.On("GetAlert", uint(1)).Return(&models.Alert{ID: 1, Status: models.AlertOpen}, nil).Once()
+	repo.On("UpdateAlert", mock.Anything, models.AlertOpen).Return(nil).Once()
+
+	alert, err := s.AcknowledgeAlert(1, 3, entities.AlertAcknowledge{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.AlertAcknowledged, alert.Status)
+	assert.Equal(t, uint(3), *alert.AssigneeID)
+	assert.Equal(t, uint(3), *alert.AcknowledgedBy)
+	assert.NotNil(t, alert.AcknowledgedAt)
+
+	repo.On("GetAlert", uint(2)).Return(&models.Alert{ID: 2, Status: models.AlertAcknowledged}, nil).Once()
+	_, err = s.AcknowledgeAlert(2, 3, entities.AlertAcknowledge{})
+	assert.True(t, errors.Is(err, entities.ErrConflict))
+	repo.AssertExpectations(t)
+}
+
+func TestAssignAlertChecksAssignee(t *testing.T) {
+	repo := new(MockAlertRepo)
+	users := new(MockAuthRepo)
+	s := services.NewAlertService(repo, users)
+
+	repo.On("GetAlert", uint(1)).Return(&models.Alert{ID: 1, Status: models.AlertOpen}, nil)
+	users.On("GetUserByID", uint(5)).Return(&models.Users{ID: 5, Role: models.RoleViewer}, nil).Once()
+	users.On("GetUserByID", uint(6)).Return(nil, entities.ErrNotFound).Once()
+	users.On("GetUserByID", uint(7)).Return(&models.Users{ID: 7, Role: models.RoleOperator}, nil).Once()
+	repo.On("UpdateAlert", mock.Anything, models.AlertOpen).Return(nil).Once()
+
+	_, err := s.AssignAlert(1, entities.AlertAssign{AssigneeID: 5})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+	_, err = s.AssignAlert(1, entities.AlertAssign{AssigneeID: 6})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+
+	alert, err := s.AssignAlert(1, entities.AlertAssign{AssigneeID: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), *alert.AssigneeID)
+	assert.Equal(t, models.AlertOpen, alert.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestResolveAlertRecordsNote(t *testing.T) {
+	repo := new(MockAlertRepo)
+	s := services.NewAlertService(repo, new(MockAuthRepo))
+
+	repo.On("GetAlert", uint(1)).Return(&models.Alert{ID: 1, Status: models.AlertAcknowledged}, nil).Once()
+	repo.On("UpdateAlert", mock.MatchedBy(func(a *models.Alert) bool {
+		return a.Status == models.AlertResolved && a.ResolutionNote == "пополнено с резервного склада"
+	}), models.AlertAcknowledged).Return(nil).Once()
+
+	alert, err := s.ResolveAlert(1, 3, entities.AlertResolve{Note: " пополнено с резервного склада "})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), *alert.ResolvedBy)
+
+	repo.On("GetAlert", uint(2)).Return(&models.Alert{ID: 2, Status: models.AlertResolved}, nil).Once()
+	_, err = s.ResolveAlert(2, 3, entities.AlertResolve{})
+	assert.True(t, errors.Is(err, entities.ErrConflict))
+	repo.AssertExpectations(t)
+}
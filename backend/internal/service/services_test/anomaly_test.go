@@ -0,0 +1,95 @@
+package test_services
+
+import (
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnomalyDetectorCheck(t *testing.T) {
+	d := models.DefaultAnomalyDetector()
+
+	tests := []struct {
+		name      string
+		recent    []int
+		quantity  int
+		anomalous bool
+	}{
+		{"misread drop to zero", []int{80, 79, 81, 80}, 0, true},
+		{"spike above shelf history", []int{40, 41, 40, 39, 40}, 95, true},
+		{"regular consumption", []int{80, 78, 75, 72}, 68, false},
+		{"too little history", []int{80, 80}, 0, false},
+		{"small jump on flat history", []int{5, 5, 5, 5}, 12, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, reason, anomalous := d.Check(tt.recent, tt.quantity)
+			assert.Equal(t, tt.anomalous, anomalous, "score %.2f", score)
+			if tt.anomalous {
+				assert.GreaterOrEqual(t, score, d.Threshold)
+				assert.NotEmpty(t, reason)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		d := models.DefaultAnomalyDetector()
+		d.Threshold = 0
+		_, _, anomalous := d.Check([]int{80, 79, 81, 80}, 0)
+		assert.False(t, anomalous)
+	})
+}
+
+func TestAnomalyDetectorAgrees(t *testing.T) {
+	d := models.DefaultAnomalyDetector()
+	assert.True(t, d.Agrees(20, 22))
+	assert.False(t, d.Agrees(0, 79))
+}
+
+func TestListAnomaliesDefaultsToPending(t *testing.T) {
+	repo := new(MockInventoryRepo)
+	s := services.NewInventoryService(repo, new(MockLocationRepo), services.NewEventHub(1, services.DropEvent), nil)
+
+	flagged := []models.InventoryHistory{{ID: 7, ProductID: "TEL-4567", Quantity: 0, AnomalyStatus: models.AnomalyPending}}
+	repo.On("ListAnomalies", models.AnomalyPending, 50, 0).Return(flagged, int64(1), nil).Once()
+	repo.On("ListAnomalies", "", 50, 0).Return([]models.InventoryHistory{}, int64(0), nil).Once()
+
+	response, err := s.ListAnomalies(entities.AnomalyQuery{Limit: 50})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Equal(t, uint(7), response.Items[0].ID)
+
+	_, err = s.ListAnomalies(entities.AnomalyQuery{Status: "all", Limit: 50})
+	assert.NoError(t, err)
+
+	_, err = s.ListAnomalies(entities.AnomalyQuery{Status: "ignored", Limit: 50})
+	assert.ErrorIs(t, err, entities.ErrValidation)
+	repo.AssertExpectations(t)
+}
+
+func TestResolveAnomaly(t *testing.T) {
+	repo := new(MockInventoryRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+	s := services.NewInventoryService(repo, new(MockLocationRepo), hub, nil)
+
+	alert := models.Alert{ID: 5, Type: models.AlertTypeScanned, ProductID: "TEL-4567", Severity: models.SeverityCritical}
+	repo.On("ResolveAnomaly", uint(7), models.AnomalyConfirmed, uint(3)).
+		Return(&models.InventoryHistory{ID: 7, AnomalyStatus: models.AnomalyConfirmed}, []models.Alert{alert}, nil).Once()
+	repo.On("ResolveAnomaly", uint(8), models.AnomalyRejected, uint(3)).
+		Return(nil, nil, entities.ErrConflict).Once()
+
+	history, err := s.ResolveAnomaly(7, true, 3)
+	assert.NoError(t, err)
+	assert.True(t, history.Counted())
+	// подтвержденный выброс открыл оповещение, оно уходит на дашборды
+	ev, _ := receive(t, sub)
+	assert.Equal(t, alert, ev)
+
+	_, err = s.ResolveAnomaly(8, false, 3)
+	assert.ErrorIs(t, err, entities.ErrConflict)
+	repo.AssertExpectations(t)
+}
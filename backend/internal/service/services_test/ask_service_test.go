@@ -0,0 +1,188 @@
+package test_services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Role1776/gigago"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// answeringForecaster отвечает заданными ссылками и запоминает переданные строки
+type answeringForecaster struct {
+	fixedForecaster
+	refs  []string
+	facts []entities.AskFact
+}
+
+func (f *answeringForecaster) Answer(_ context.Context, _ string, facts []entities.AskFact) (*entities.AskAnswer, error) {
+	f.facts = facts
+	return &entities.AskAnswer{Answer: "Ниже минимума: Роутер в зоне B", Refs: f.refs}, nil
+}
+
+var (
+	askRouter = models.Products{ID: "TEL-4567", Name: "Роутер", Category: "network", MinStock: 20, OptimalStock: 100}
+	askCable  = models.Products{ID: "CAB-0001", Name: "Кабель", Category: "cables", MinStock: 5, OptimalStock: 50}
+)
+
+func askRepos() (*MockInventoryRepo, *MockProductRepo, *MockLocationRepo) {
+	scanned := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	inventory := new(MockInventoryRepo)
+	products := new(MockProductRepo)
+	locations := new(MockLocationRepo)
+	// зона C есть в раскладке, но пока пустая
+	locations.On("ListLocations").Return([]models.Location{{Zone: "A"}, {Zone: "B"}, {Zone: "C"}}, nil)
+	products.On("ListProducts", entities.ProductQuery{}).Return([]models.Products{askRouter, askCable}, nil)
+	inventory.On("GetStockLevels", "", "").Return([]models.StockLevel{
+		{ProductID: "TEL-4567", Zone: "A", RowNumber: 1, ShelfNumber: 1, Quantity: 80, ScannedAt: scanned, Product: askRouter},
+		{ProductID: "TEL-4567", Zone: "B", RowNumber: 1, ShelfNumber: 1, Quantity: 10, ScannedAt: scanned, Product: askRouter},
+		{ProductID: "TEL-4567", Zone: "B", RowNumber: 2, ShelfNumber: 3, Quantity: 5, ScannedAt: scanned.Add(time.Hour), Product: askRouter},
+		{ProductID: "CAB-0001", Zone: "B", RowNumber: 4, ShelfNumber: 1, Quantity: 3, ScannedAt: scanned, Product: askCable},
+	}, nil)
+	return inventory, products, locations
+}
+
+// сканирования отдает репозиторий уже отфильтрованными по зонам и категориям из вопроса
+func expectRecentScans(inventory *MockInventoryRepo, zones, categories []string, scans []models.InventoryHistory) {
+	inventory.On("GetRecentScans", mock.MatchedBy(func(q entities.RecentScansQuery) bool {
+		return assert.ObjectsAreEqual(zones, q.Zones) && assert.ObjectsAreEqual(categories, q.Categories) &&
+			len(q.ProductIDs) == 0 && q.Limit == 50 && time.Since(q.Since) < 25*time.Hour
+	})).Return(scans, nil)
+}
+
+func TestAskGroundsAnswerInFilteredData(t *testing.T) {
+	inventory, products, locations := askRepos()
+	expectRecentScans(inventory, []string{"B"}, []string{"network"}, []models.InventoryHistory{
+		{ProductID: "TEL-4567", Zone: "B", RowNumber: 2, ShelfNumber: 3, Quantity: 5, Status: models.StockCritical, Product: askRouter},
+	})
+	provider := &answeringForecaster{refs: []string{"S1", "H1", "S1", "X9"}}
+	s := services.NewAskService(inventory, products, locations, provider)
+
+	// кириллическая "В" в названии зоны
+	got, err := s.Ask("какие товары network в зоне В ниже минимального остатка?")
+	assert.NoError(t, err)
+
+	// в модель ушли только строки зоны B категории network
+	if assert.Len(t, provider.facts, 2) {
+		stock := provider.facts[0]
+		assert.Equal(t, "S1", stock.Ref)
+		assert.Equal(t, "stock_levels", stock.Source)
+		assert.Equal(t, "B", stock.Zone)
+		assert.Equal(t, 15, stock.Quantity)
+		assert.Equal(t, 2, stock.Cells)
+		assert.Equal(t, models.StockCritical, stock.Status)
+		assert.Equal(t, "H1", provider.facts[1].Ref)
+		assert.Equal(t, "inventory_history", provider.facts[1].Source)
+	}
+
+	assert.Equal(t, "Ниже минимума: Роутер в зоне B", got.Answer)
+	assert.Equal(t, "fixed", got.Provider)
+	// повторные и несуществующие ссылки отброшены
+	assert.Len(t, got.Citations, 2)
+	assert.Equal(t, "S1", got.Citations[0].Ref)
+	assert.Equal(t, "H1", got.Citations[1].Ref)
+}
+
+func TestAskZoneWords(t *testing.T) {
+	inventory, products, locations := askRepos()
+	expectRecentScans(inventory, nil, nil, []models.InventoryHistory{
+		{ProductID: "TEL-4567", Zone: "B", RowNumber: 2, ShelfNumber: 3, Quantity: 5, Status: models.StockCritical, Product: askRouter},
+		{ProductID: "TEL-4567", Zone: "A", RowNumber: 1, ShelfNumber: 1, Quantity: 80, Status: models.StockOK, Product: askRouter},
+	})
+	provider := &answeringForecaster{refs: []string{"S1"}}
+	s := services.NewAskService(inventory, products, locations, provider)
+
+	// "зонах склада" не код зоны, фильтра нет
+	_, err := s.Ask("что в зонах склада заканчивается?")
+	assert.NoError(t, err)
+	// критичные остатки первыми
+	assert.Len(t, provider.facts, 5)
+	assert.Equal(t, "CAB-0001", provider.facts[0].ProductID)
+
+	// слова после "зона", которые не коды зон, фильтром не считаются
+	for _, question := range []string{"что в зоне и рядом заканчивается?", "what is low in the zone of returns?"} {
+		_, err = s.Ask(question)
+		assert.NoError(t, err, question)
+		assert.Len(t, provider.facts, 5, question)
+	}
+
+	// несуществующая зона не превращается в ответ по всему складу
+	provider.facts = nil
+	_, err = s.Ask("что заканчивается в зоне Z?")
+	assert.ErrorIs(t, err, entities.ErrValidation)
+	assert.ErrorContains(t, err, "unknown zone Z")
+	assert.Nil(t, provider.facts)
+}
+
+func TestAskEmptyDefinedZone(t *testing.T) {
+	inventory, products, locations := askRepos()
+	expectRecentScans(inventory, []string{"C"}, nil, []models.InventoryHistory{})
+	provider := &answeringForecaster{}
+	s := services.NewAskService(inventory, products, locations, provider)
+
+	// зона есть в раскладке, но остатков в ней нет: ответ без данных, а не ошибка
+	_, err := s.Ask("что лежит в зоне c?")
+	assert.NoError(t, err)
+	assert.Empty(t, provider.facts)
+}
+
+func TestAskProviderWithoutAnswers(t *testing.T) {
+	s := services.NewAskService(new(MockInventoryRepo), new(MockProductRepo), new(MockLocationRepo), services.NewStatisticalForecaster())
+
+	_, err := s.Ask("сколько роутеров?")
+	assert.ErrorIs(t, err, entities.ErrNotSupported)
+
+	_, err = s.Ask("   ")
+	assert.ErrorIs(t, err, entities.ErrValidation)
+}
+
+func TestParseAnswerResponse(t *testing.T) {
+	facts := []entities.AskFact{{Ref: "S1"}, {Ref: "H1"}}
+
+	answer, err := services.ParseAnswerResponse("```json\n{\"answer\": \"Роутер ниже минимума\", \"refs\": [\"s1\", \"H1\",]}\n```", facts)
+	assert.NoError(t, err)
+	assert.Equal(t, &entities.AskAnswer{Answer: "Роутер ниже минимума", Refs: []string{"S1", "H1"}}, answer)
+
+	for _, content := range []string{
+		"Роутер ниже минимума",
+		`{"answer": "", "refs": ["S1"]}`,
+		`{"answer": "Роутер ниже минимума", "refs": []}`,
+		`{"answer": "Роутер ниже минимума", "refs": ["S7"]}`,
+	} {
+		_, err := services.ParseAnswerResponse(content, facts)
+		assert.Error(t, err, content)
+	}
+
+	answer, err = services.ParseAnswerResponse(`{"answer": "Данных нет"}`, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, answer.Refs)
+}
+
+func TestAskThroughGigaChat(t *testing.T) {
+	server, requests := fakeGigaChat(t,
+		`{"answer": "Роутер в зоне B ниже минимума"}`,
+		`{"answer": "Роутер в зоне B ниже минимума", "refs": ["S1"]}`,
+	)
+	provider := services.NewGigaChatForecaster("key", nil,
+		gigago.WithCustomURLOauth(server.URL+"/oauth"),
+		gigago.WithCustomURLAI(server.URL+"/chat/completions"),
+	)
+	inventory, products, locations := askRepos()
+	expectRecentScans(inventory, []string{"B"}, nil, []models.InventoryHistory{})
+	s := services.NewAskService(inventory, products, locations, provider)
+
+	got, err := s.Ask("что в зоне B ниже минимума?")
+	assert.NoError(t, err)
+	assert.Equal(t, services.ProviderGigaChat, got.Provider)
+	assert.Equal(t, "Роутер в зоне B ниже минимума", got.Answer)
+	if assert.Len(t, got.Citations, 1) {
+		assert.Equal(t, "CAB-0001", got.Citations[0].ProductID)
+	}
+	// ответ без ссылок отклонен и исправлен
+	assert.Equal(t, []int{2, 4}, *requests)
+}
@@ -0,0 +1,105 @@
+package test_services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// receive читает событие из подписки с таймаутом
+func receive(t *testing.T, sub *services.Subscription) (interface{}, bool) {
+	t.Helper()
+	select {
+	case ev, ok := <-sub.Events():
+		return ev, ok
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+		return nil, false
+	}
+}
+
+func TestEventHubFanOut(t *testing.T) {
+	hub := services.NewEventHub(4, services.DropEvent)
+	first := hub.Subscribe()
+	second := hub.Subscribe()
+
+	hub.Publish("robot_update")
+
+	ev, ok := receive(t, first)
+	assert.True(t, ok)
+	assert.Equal(t, "robot_update", ev)
+
+	ev, ok = receive(t, second)
+	assert.True(t, ok)
+	assert.Equal(t, "robot_update", ev)
+}
+
+func TestEventHubPublishWithoutSubscribers(t *testing.T) {
+	hub := services.NewEventHub(1, services.DropEvent)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			hub.Publish(i)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked without subscribers")
+	}
+}
+
+func TestEventHubDropEventPolicy(t *testing.T) {
+	hub := services.NewEventHub(2, services.DropEvent)
+	sub := hub.Subscribe()
+
+	for i := 0; i < 5; i++ {
+		hub.Publish(i)
+	}
+
+	assert.Equal(t, 1, hub.Len())
+	ev, _ := receive(t, sub)
+	assert.Equal(t, 0, ev)
+	ev, _ = receive(t, sub)
+	assert.Equal(t, 1, ev)
+	assert.Len(t, sub.Events(), 0)
+}
+
+func TestEventHubDropSubscriberPolicy(t *testing.T) {
+	hub := services.NewEventHub(1, services.DropSubscriber)
+	slow := hub.Subscribe()
+	fast := hub.Subscribe()
+
+	hub.Publish(1)
+	<-fast.Events()
+	hub.Publish(2)
+
+	assert.Equal(t, 1, hub.Len())
+
+	ev, ok := receive(t, slow)
+	assert.True(t, ok)
+	assert.Equal(t, 1, ev)
+	_, ok = receive(t, slow)
+	assert.False(t, ok)
+
+	ev, ok = receive(t, fast)
+	assert.True(t, ok)
+	assert.Equal(t, 2, ev)
+}
+
+func TestEventHubUnsubscribeTwice(t *testing.T) {
+	hub := services.NewEventHub(1, services.DropEvent)
+	sub := hub.Subscribe()
+
+	hub.Unsubscribe(sub)
+	hub.Unsubscribe(sub)
+
+	assert.Equal(t, 0, hub.Len())
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+}
@@ -0,0 +1,106 @@
+package test_services
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/fakegigachat"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// GigaChat из конфигурации, направленный на локальную замену
+func fakeGigaChatForecaster(t *testing.T, cfg fakegigachat.Config) (services.Forecaster, *fakegigachat.Server) {
+	t.Helper()
+	fake, err := fakegigachat.NewServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	t.Setenv("GIGACHAT_BASE_URL", server.URL+"/")
+	t.Setenv("API_KEY", "test-key")
+	t.Setenv("AI_SERVICE", services.ProviderGigaChat)
+	t.Setenv("AI_FALLBACK", "none")
+	forecaster, err := services.NewForecasterFromConfig(catalogRepo(models.Products{ID: "TEL-4567", Name: "Роутер"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return forecaster, fake
+}
+
+func TestAIServiceAgainstFakeGigaChat(t *testing.T) {
+	forecaster, fake := fakeGigaChatForecaster(t, fakegigachat.Config{Key: "test-key"})
+	repo := new(MockAIRepo)
+	s := services.NewAIService(repo, forecaster, services.NewEventHub(1, services.DropEvent), nil)
+
+	product := models.Products{ID: "TEL-4567", Name: "Роутер", OptimalStock: 100}
+	now := time.Now()
+	history := []models.InventoryHistory{
+		{ProductID: "TEL-4567", Quantity: 80, ScannedAt: now, Product: product},
+		{ProductID: "TEL-4567", Quantity: 100, ScannedAt: now.AddDate(0, 0, -2), Product: product},
+	}
+	repo.On("AIRequest", mock.Anything, mock.Anything).Return(history, nil).Once()
+	repo.On("AIResponse", mock.AnythingOfType("entities.AIResponse")).Return(nil, nil).Once()
+
+	response, err := s.Predict(entities.AIRequest{PeriodDays: 7})
+	if !assert.NoError(t, err) || !assert.Len(t, response.Predictions, 1) {
+		return
+	}
+	assert.Equal(t, services.ProviderGigaChat, response.Provider)
+	assert.Equal(t, 8, response.Predictions[0].DaysUntilStockout) // 10 шт. в день
+	assert.Equal(t, 20, response.Predictions[0].RecommendedOrder)
+
+	requests := fake.Requests()
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "system", requests[0].Messages[0].Role)
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestFakeGigaChatScriptedCorrection(t *testing.T) {
+	forecaster, fake := fakeGigaChatForecaster(t, fakegigachat.Config{
+		Script: []fakegigachat.Reply{{Content: "Извините, не могу помочь"}},
+	})
+	history := []models.InventoryHistory{{ProductID: "TEL-4567", Quantity: 40, Product: models.Products{ID: "TEL-4567"}}}
+
+	response, err := forecaster.Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, history)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "TEL-4567", response.Predictions[0].ProductID)
+	// после сценария ответ строится по данным, исправление тоже принято
+	assert.Len(t, fake.Requests(), 2)
+}
+
+func TestFakeGigaChatAnswerRules(t *testing.T) {
+	forecaster, _ := fakeGigaChatForecaster(t, fakegigachat.Config{
+		Rules: []fakegigachat.Rule{{Match: "недоступ", Reply: fakegigachat.Reply{Status: 503, Content: "service unavailable"}}},
+	})
+	answerer := forecaster.(services.Answerer)
+	facts := []entities.AskFact{{Ref: "S1", ProductName: "Роутер", Zone: "A", Quantity: 5, Status: models.StockCritical}}
+
+	answer, err := answerer.Answer(context.Background(), "Сколько роутеров в зоне A?", facts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"S1"}, answer.Refs)
+	assert.Contains(t, answer.Answer, "Роутер")
+
+	_, err = answerer.Answer(context.Background(), "Проверка: сервис недоступен", facts)
+	assert.ErrorContains(t, err, "503")
+}
+
+func TestFakeGigaChatRejectsWrongKey(t *testing.T) {
+	forecaster, fake := fakeGigaChatForecaster(t, fakegigachat.Config{Key: "other-key"})
+
+	_, err := forecaster.Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, nil)
+	assert.ErrorContains(t, err, "401")
+	assert.Empty(t, fake.Requests())
+}
@@ -0,0 +1,199 @@
+package test_services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Role1776/gigago"
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseForecastResponseRepairsCommonDefects(t *testing.T) {
+	content := "Вот прогноз:\n```json\n{\n" +
+		`"predictions": [{"product_id": "TEL-4567", "product_name": "Роутер, 4 порта", "prediction_date": "01.02.2025",` +
+		`"days_until_stockout": "5", "recommended_order": 40.0, "confidence_score": "0.8",},],` +
+		`"confidence": "0.75",` + "\n}\n```"
+
+	resp, err := services.ParseForecastResponse(content)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, resp.Confidence)
+	assert.Equal(t, []entities.Predictions{{
+		ProductID:         "TEL-4567",
+		ProductName:       "Роутер, 4 порта",
+		PredictionDate:    "01.02.2025",
+		DaysUntilStockout: 5,
+		RecommendedOrder:  40,
+		ConfidenceScore:   0.8,
+	}}, resp.Predictions)
+}
+
+func TestParseForecastResponseNumbers(t *testing.T) {
+	for text, want := range map[string]int{
+		`"1,200"`:      1200,
+		`"12,345,678"`: 12345678,
+		`"1,200.6"`:    1201,
+		`"12,5"`:       13, // десятичная запятая
+		`"40"`:         40,
+	} {
+		resp, err := services.ParseForecastResponse(`{"predictions": [{"recommended_order": ` + text + `}]}`)
+		if assert.NoError(t, err, text) {
+			assert.Equal(t, want, resp.Predictions[0].RecommendedOrder, text)
+		}
+	}
+
+	resp, err := services.ParseForecastResponse(`{"predictions": [{"confidence_score": "0,85"}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.85, resp.Predictions[0].ConfidenceScore)
+
+	_, err = services.ParseForecastResponse(`{"predictions": [{"recommended_order": "1,2,3"}]}`)
+	assert.Error(t, err)
+}
+
+func TestParseForecastResponseErrors(t *testing.T) {
+	for _, content := range []string{
+		"",
+		"Не могу построить прогноз",
+		`{"result": []}`,
+		`{"predictions": [{"days_until_stockout": "скоро"}]}`,
+	} {
+		_, err := services.ParseForecastResponse(content)
+		assert.Error(t, err, content)
+	}
+}
+
+// catalogRepo отдает каталог товаров, включая архивные
+func catalogRepo(products ...models.Products) *MockProductRepo {
+	repo := new(MockProductRepo)
+	repo.On("ListProducts", entities.ProductQuery{IncludeArchived: true}).Return(products, nil)
+	return repo
+}
+
+func TestValidateForecast(t *testing.T) {
+	archived := time.Now()
+	catalog := map[string]models.Products{
+		"TEL-4567": {ID: "TEL-4567", Name: "Роутер"},
+		"TEL-8901": {ID: "TEL-8901", Name: "Модем", ArchivedAt: &archived},
+	}
+	resp := &entities.AIResponse{
+		Confidence: 80,
+		Predictions: []entities.Predictions{
+			{ProductID: "TEL-4567", PredictionDate: "01.02.2025", DaysUntilStockout: 5, RecommendedOrder: 40, ConfidenceScore: 85},
+			{ProductID: "TEL-4567", DaysUntilStockout: 6},
+			{ProductID: "TEL-0000", DaysUntilStockout: 3},
+			{ProductID: "TEL-8901", DaysUntilStockout: 3},
+		},
+	}
+
+	services.ValidateForecast(resp, catalog)
+
+	assert.Equal(t, 0.8, resp.Confidence)
+	assert.Equal(t, []entities.Predictions{{
+		ProductID:         "TEL-4567",
+		ProductName:       "Роутер",
+		PredictionDate:    "2025-02-01",
+		DaysUntilStockout: 5,
+		RecommendedOrder:  40,
+		ConfidenceScore:   0.85,
+	}}, resp.Predictions)
+	assert.Equal(t, []entities.DroppedPrediction{
+		{ProductID: "TEL-4567", Reason: "duplicate prediction"},
+		{ProductID: "TEL-0000", Reason: "unknown product"},
+		{ProductID: "TEL-8901", Reason: "product is archived"},
+	}, resp.Dropped)
+
+	negative := &entities.AIResponse{Predictions: []entities.Predictions{{ProductID: "TEL-4567", RecommendedOrder: -5}}}
+	services.ValidateForecast(negative, catalog)
+	assert.Empty(t, negative.Predictions)
+	assert.Contains(t, negative.Dropped[0].Reason, "recommended_order -5")
+}
+
+// fakeGigaChat отвечает по очереди заданными сообщениями модели
+func fakeGigaChat(t *testing.T, replies ...string) (*httptest.Server, *[]int) {
+	t.Helper()
+	var requests []int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "test-token",
+			"expires_at":   time.Now().Add(time.Hour).UnixMilli(),
+		})
+	})
+	mux.HandleFunc("/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []gigago.Message `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		requests = append(requests, len(payload.Messages))
+
+		reply := replies[0]
+		if len(replies) > 1 {
+			replies = replies[1:]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestGigaChatForecasterRetriesWithCorrection(t *testing.T) {
+	server, requests := fakeGigaChat(t,
+		"Извините, данных недостаточно",
+		`{"predictions": [{"product_id": "TEL-4567", "days_until_stockout": 4, "recommended_order": 30, "confidence_score": 0.7}], "confidence": 0.7}`,
+	)
+	f := services.NewGigaChatForecaster("key", catalogRepo(models.Products{ID: "TEL-4567", Name: "Роутер"}),
+		gigago.WithCustomURLOauth(server.URL+"/oauth"),
+		gigago.WithCustomURLAI(server.URL+"/chat/completions"),
+	)
+	history := []models.InventoryHistory{{ProductID: "TEL-4567", Product: models.Products{ID: "TEL-4567", Name: "Роутер"}}}
+
+	resp, err := f.Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, history)
+	assert.NoError(t, err)
+	assert.Equal(t, services.ProviderGigaChat, resp.Provider)
+	assert.Equal(t, 4, resp.Predictions[0].DaysUntilStockout)
+	// системная инструкция и промпт, затем еще ответ модели и исправление
+	assert.Equal(t, []int{2, 4}, *requests)
+}
+
+func TestGigaChatForecasterGivesUp(t *testing.T) {
+	server, requests := fakeGigaChat(t, `{"predictions": [{"product_id": "TEL-0000", "days_until_stockout": 4}]}`)
+	f := services.NewGigaChatForecaster("key", catalogRepo(models.Products{ID: "TEL-4567"}),
+		gigago.WithCustomURLOauth(server.URL+"/oauth"),
+		gigago.WithCustomURLAI(server.URL+"/chat/completions"),
+	)
+	history := []models.InventoryHistory{{ProductID: "TEL-4567", Product: models.Products{ID: "TEL-4567"}}}
+
+	_, err := f.Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, history)
+	assert.ErrorContains(t, err, "TEL-0000 - unknown product")
+	assert.Len(t, *requests, 2)
+}
+
+func TestGigaChatForecasterChecksCatalog(t *testing.T) {
+	// товар есть в каталоге, но не попал в анализируемую историю
+	server, _ := fakeGigaChat(t, `{"predictions": [
+		{"product_id": "TEL-4567", "days_until_stockout": 4, "recommended_order": 30, "confidence_score": 0.7},
+		{"product_id": "CAB-0001", "days_until_stockout": 9, "recommended_order": 10, "confidence_score": 0.6}]}`)
+	f := services.NewGigaChatForecaster("key", catalogRepo(
+		models.Products{ID: "TEL-4567", Name: "Роутер"},
+		models.Products{ID: "CAB-0001", Name: "Кабель"},
+	),
+		gigago.WithCustomURLOauth(server.URL+"/oauth"),
+		gigago.WithCustomURLAI(server.URL+"/chat/completions"),
+	)
+	history := []models.InventoryHistory{{ProductID: "TEL-4567", Product: models.Products{ID: "TEL-4567"}}}
+
+	resp, err := f.Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, history)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Predictions, 2) {
+		assert.Equal(t, "Кабель", resp.Predictions[1].ProductName)
+	}
+}
@@ -0,0 +1,117 @@
+package test_services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingRunner запоминает запросы планировщика
+type recordingRunner struct {
+	requests []entities.AIRequest
+	err      error
+}
+
+func (r *recordingRunner) Regenerate(rq entities.AIRequest) (*entities.AIResponse, error) {
+	r.requests = append(r.requests, rq)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &entities.AIResponse{Provider: "fixed"}, nil
+}
+
+func TestParseForecastSchedule(t *testing.T) {
+	jobs, err := services.ParseForecastSchedule(" *:7:6h ; Сетевое оборудование, Кабели :14:24h;")
+	assert.NoError(t, err)
+	assert.Equal(t, []services.ForecastJob{
+		{PeriodDays: 7, Every: 6 * time.Hour},
+		{Categories: []string{"Сетевое оборудование", "Кабели"}, PeriodDays: 14, Every: 24 * time.Hour},
+	}, jobs)
+	assert.Equal(t, "*:7", jobs[0].Name())
+	assert.Equal(t, "Сетевое оборудование,Кабели:14", jobs[1].Name())
+
+	jobs, err = services.ParseForecastSchedule("")
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	for _, schedule := range []string{"*:7", "*:week:6h", "*:0:6h", "*:7:soon", "*:7:10s"} {
+		_, err := services.ParseForecastSchedule(schedule)
+		assert.Error(t, err, schedule)
+	}
+}
+
+func TestForecastSchedulerFromConfig(t *testing.T) {
+	t.Setenv("AI_SCHEDULE", "*:7:6h")
+	_, err := services.NewForecastSchedulerFromConfig(&recordingRunner{}, new(MockLockRepo), nil)
+	assert.NoError(t, err)
+
+	// опечатка в расписании не отключает прогнозы молча
+	t.Setenv("AI_SCHEDULE", "*:7:soon")
+	_, err = services.NewForecastSchedulerFromConfig(&recordingRunner{}, new(MockLockRepo), nil)
+	assert.ErrorContains(t, err, "AI_SCHEDULE")
+}
+
+func TestForecastSchedulerRunJob(t *testing.T) {
+	job := services.ForecastJob{Categories: []string{"network"}, PeriodDays: 7, Every: time.Hour}
+
+	t.Run("lease holder regenerates forecast", func(t *testing.T) {
+		locks := new(MockLockRepo)
+		runner := &recordingRunner{}
+		locks.On("TryLock", "lock:ai:schedule:network:7", mock.Anything, 54*time.Minute).Return(true, nil).Once()
+
+		scheduler := services.NewForecastScheduler(runner, locks, nil, []services.ForecastJob{job})
+		assert.True(t, scheduler.RunJob(job))
+		assert.Equal(t, []entities.AIRequest{{PeriodDays: 7, Categories: []string{"network"}}}, runner.requests)
+		locks.AssertExpectations(t)
+	})
+
+	t.Run("another replica holds the lease", func(t *testing.T) {
+		locks := new(MockLockRepo)
+		runner := &recordingRunner{}
+		locks.On("TryLock", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Once()
+
+		scheduler := services.NewForecastScheduler(runner, locks, nil, []services.ForecastJob{job})
+		assert.False(t, scheduler.RunJob(job))
+		assert.Empty(t, runner.requests)
+	})
+
+	t.Run("lock error skips the run", func(t *testing.T) {
+		locks := new(MockLockRepo)
+		runner := &recordingRunner{}
+		locks.On("TryLock", mock.Anything, mock.Anything, mock.Anything).Return(false, errors.New("db down")).Once()
+
+		scheduler := services.NewForecastScheduler(runner, locks, nil, []services.ForecastJob{job})
+		assert.False(t, scheduler.RunJob(job))
+		assert.Empty(t, runner.requests)
+	})
+}
+
+func TestRegenerateBypassesCacheAndPublishesAlerts(t *testing.T) {
+	repo := new(MockAIRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+
+	response := &entities.AIResponse{
+		Predictions: []entities.Predictions{{ProductID: "TEL-4567", DaysUntilStockout: 2}},
+		Provider:    "fixed",
+	}
+	s := services.NewAIService(repo, &fixedForecaster{response: response}, hub, nil)
+
+	rq := entities.AIRequest{PeriodDays: 7}
+	repo.On("AIRequest", rq, mock.Anything).Return(nil, nil).Once()
+	alert := models.Alert{ID: 3, Type: models.AlertTypePredicted, ProductID: "TEL-4567"}
+	repo.On("AIResponse", *response).Return([]models.Alert{alert}, nil).Once()
+
+	got, err := s.Regenerate(rq)
+	assert.NoError(t, err)
+	assert.Equal(t, response, got)
+	ev, _ := receive(t, sub)
+	assert.Equal(t, alert, ev)
+	repo.AssertExpectations(t)
+}
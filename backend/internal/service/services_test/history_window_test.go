@@ -0,0 +1,147 @@
+package test_services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHistoryWindow(t *testing.T) {
+	cfg := services.DefaultHistoryConfig()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rq       entities.AIRequest
+		fromDays int
+		detail   time.Duration
+	}{
+		{"lookback follows forecast period", entities.AIRequest{PeriodDays: 30}, 90, 72 * time.Hour},
+		{"short forecast keeps minimum", entities.AIRequest{PeriodDays: 1}, 3, 72 * time.Hour},
+		{"long forecast is capped", entities.AIRequest{PeriodDays: 60}, 90, 72 * time.Hour},
+		{"explicit lookback", entities.AIRequest{PeriodDays: 30, LookbackDays: 14}, 14, 72 * time.Hour},
+		{"explicit lookback is capped too", entities.AIRequest{PeriodDays: 7, LookbackDays: 365}, 90, 72 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := cfg.Window(tt.rq, now)
+			assert.Equal(t, now.AddDate(0, 0, -tt.fromDays), window.From)
+			assert.Equal(t, now.Add(-tt.detail), window.DetailFrom)
+			assert.Equal(t, 10*time.Minute, window.Slot)
+		})
+	}
+
+	// детальное окно не выходит за начало истории
+	cfg.DetailWindow = 10 * 24 * time.Hour
+	window := cfg.Window(entities.AIRequest{PeriodDays: 1}, now)
+	assert.Equal(t, window.From, window.DetailFrom)
+}
+
+func TestHistoryConfigFromEnv(t *testing.T) {
+	t.Setenv("AI_LOOKBACK_FACTOR", "2")
+	t.Setenv("AI_MAX_LOOKBACK_DAYS", "180")
+	t.Setenv("AI_DETAIL_HOURS", "24")
+	t.Setenv("AI_SLOT_MINUTES", "30")
+	t.Setenv("AI_MAX_HISTORY_ROWS", "bad")
+
+	cfg := services.HistoryConfigFromEnv()
+	assert.Equal(t, 2, cfg.LookbackFactor)
+	assert.Equal(t, 180, cfg.MaxLookbackDays)
+	assert.Equal(t, 24*time.Hour, cfg.DetailWindow)
+	assert.Equal(t, 30*time.Minute, cfg.Slot)
+	assert.Equal(t, services.DefaultHistoryConfig().MaxRows, cfg.MaxRows)
+}
+
+func productSeries(productID string, n int) []models.InventoryHistory {
+	history := make([]models.InventoryHistory, n)
+	for i := range history {
+		history[i] = models.InventoryHistory{ID: uint(i + 1), ProductID: productID, Quantity: 100 - i}
+	}
+	return history
+}
+
+func TestDownsampleHistory(t *testing.T) {
+	short := productSeries("TEL-8901", 3)
+	long := productSeries("TEL-4567", 100)
+	history := append(append([]models.InventoryHistory{}, long...), short...)
+
+	assert.Equal(t, history, services.DownsampleHistory(history, 200))
+
+	reduced := services.DownsampleHistory(history, 20)
+	var kept []int
+	shortKept := 0
+	for _, h := range reduced {
+		if h.ProductID == "TEL-4567" {
+			kept = append(kept, h.Quantity)
+		} else {
+			shortKept++
+		}
+	}
+	// короткий ряд целиком, длинный - 10 равномерных точек с первой и последней
+	assert.Equal(t, 3, shortKept)
+	assert.Len(t, kept, 10)
+	assert.Equal(t, 100, kept[0])
+	assert.Equal(t, 1, kept[len(kept)-1])
+
+	// ряды разных ячеек товара сокращаются отдельно, последняя точка каждой ячейки сохраняется
+	shelves := productSeries("TEL-4567", 30)
+	for i := range shelves {
+		shelves[i].Zone = []string{"A", "B", "C"}[i%3]
+	}
+	reduced = services.DownsampleHistory(shelves, 9)
+	assert.Len(t, reduced, 9)
+	last := make(map[string]int)
+	for _, h := range reduced {
+		last[h.Zone] = h.Quantity
+	}
+	assert.Equal(t, map[string]int{"A": 73, "B": 72, "C": 71}, last)
+}
+
+func TestDownsampleHistoryManyProducts(t *testing.T) {
+	var many []models.InventoryHistory
+	for i := 0; i < 5; i++ {
+		series := productSeries(fmt.Sprintf("P-%d", i), 10)
+		for j := range series {
+			series[j].Quantity += i * 10 // P-0 ближе всего к нехватке
+			series[j].ScannedAt = time.Unix(int64(j), 0)
+			series[j].Product.MinStock = 20
+		}
+		many = append(many, series...)
+	}
+
+	// предел не превышается: по две точки получают только самые срочные товары
+	reduced := services.DownsampleHistory(many, 5)
+	assert.Len(t, reduced, 4)
+	products := make(map[string]int)
+	for _, h := range reduced {
+		products[h.ProductID]++
+	}
+	assert.Equal(t, map[string]int{"P-0": 2, "P-1": 2}, products)
+
+	assert.Empty(t, services.DownsampleHistory(many, 1))
+}
+
+func TestPredictSendsReducedHistory(t *testing.T) {
+	t.Setenv("AI_MAX_HISTORY_ROWS", "4")
+	repo := new(MockAIRepo)
+	forecaster := &fixedForecaster{response: &entities.AIResponse{Provider: "fixed"}}
+	s := services.NewAIService(repo, forecaster, services.NewEventHub(1, services.DropEvent), nil)
+
+	rq := entities.AIRequest{PeriodDays: 30}
+	repo.On("AIRequest", rq, mock.MatchedBy(func(w entities.HistoryWindow) bool {
+		days := w.DetailFrom.Sub(w.From).Hours() / 24
+		return days > 86 && days < 88 // 90 дней истории, из них последние 3 детально
+	})).Return(productSeries("TEL-4567", 40), nil).Once()
+	repo.On("AIResponse", mock.Anything).Return(nil, nil).Once()
+
+	_, err := s.Predict(rq)
+	assert.NoError(t, err)
+	assert.Len(t, forecaster.history, 4)
+	repo.AssertExpectations(t)
+}
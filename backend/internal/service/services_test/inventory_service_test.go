@@ -0,0 +1,66 @@
+package test_services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetStockAggregatesLocations(t *testing.T) {
+	repo := new(MockInventoryRepo)
+	s := services.NewInventoryService(repo, new(MockLocationRepo), services.NewEventHub(1, services.DropEvent), nil)
+
+	router := models.Products{ID: "TEL-4567", Name: "Роутер", MinStock: 10, OptimalStock: 100}
+	now := time.Now()
+	repo.On("GetStockLevels", "", "").Return([]models.StockLevel{
+		{ProductID: "TEL-4567", Zone: "A", RowNumber: 1, ShelfNumber: 1, Quantity: 30, Status: models.StockLow, ScannedAt: now, Product: router},
+		{ProductID: "TEL-4567", Zone: "B", RowNumber: 2, ShelfNumber: 3, Quantity: 40, Status: models.StockLow, ScannedAt: now, Product: router},
+	}, nil).Once()
+
+	stock, err := s.GetStock("", "")
+	assert.NoError(t, err)
+	assert.Len(t, stock.Products, 1)
+	assert.Equal(t, 70, stock.Products[0].Total)
+	assert.Equal(t, models.StockOK, stock.Products[0].Status)
+	assert.Len(t, stock.Products[0].Locations, 2)
+	assert.Equal(t, "B", stock.Products[0].Locations[1].Zone)
+}
+
+func TestImportCSVComputesStatus(t *testing.T) {
+	repo := new(MockInventoryRepo)
+	locations := new(MockLocationRepo)
+	s := services.NewInventoryService(repo, locations, services.NewEventHub(1, services.DropEvent), nil)
+
+	csv := "product_id;name;quantity;zone;date;row;shelf\n" +
+		"TEL-4567;Роутер;5;A;2025-01-01;1;1\n" +
+		"UNKNOWN;Нечто;50;A;2025-01-01;1;2\n" +
+		"TEL-4567;Роутер;5;Z;2025-01-01;1;1\n" +
+		"TEL-4567;Роутер;5;A;2025-01-01;21;1\n" +
+		"TEL-4567;Роутер;900;A;2025-01-01;2;1\n" +
+		"OLD-0001;Снятый;5;A;2025-01-01;3;1\n"
+
+	archived := time.Now()
+	repo.On("GetProductsByIDs", []string{"TEL-4567", "UNKNOWN", "OLD-0001"}).
+		Return([]models.Products{{ID: "TEL-4567", MinStock: 10, OptimalStock: 100}, {ID: "OLD-0001", ArchivedAt: &archived}}, nil).Once()
+	locations.On("ListLocations").
+		Return([]models.Location{{Zone: "A", Rows: 20, ShelvesPerRow: 10, ShelfCapacity: 500}}, nil).Once()
+	repo.On("ImportInventoryHistories", mock.MatchedBy(func(h []models.InventoryHistory) bool {
+		return len(h) == 1 && h[0].ProductID == "TEL-4567" && h[0].Status == models.StockCritical
+	})).Return(nil).Once()
+
+	result, err := s.ImportCSV(strings.NewReader(csv))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.SuccessCount)
+	assert.Equal(t, 5, result.FailedCount)
+	assert.Contains(t, result.Errors[1], "unknown zone")
+	assert.Contains(t, result.Errors[2], "outside of the warehouse layout")
+	assert.Contains(t, result.Errors[3], "shelf capacity")
+	// архивный товар отклоняется так же, как в данных роботов
+	assert.Equal(t, "Product OLD-0001 is archived", result.Errors[4])
+	repo.AssertExpectations(t)
+}
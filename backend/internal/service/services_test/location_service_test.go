@@ -0,0 +1,50 @@
+package test_services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateLocationNormalizesZone(t *testing.T) {
+	repo := new(MockLocationRepo)
+	s := services.NewLocationService(repo)
+
+	repo.On("CreateLocation", mock.MatchedBy(func(l *models.Location) bool {
+		return l.Zone == "F" && l.Rows == 12 && l.ShelvesPerRow == 8
+	})).Return(nil).Once()
+
+	location, err := s.CreateLocation(entities.LocationInput{Zone: " f ", Rows: 12, ShelvesPerRow: 8})
+	assert.NoError(t, err)
+	assert.Equal(t, "F", location.Zone)
+
+	_, err = s.CreateLocation(entities.LocationInput{Zone: "F-1", Rows: 1, ShelvesPerRow: 1})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateLocationValidatesBounds(t *testing.T) {
+	repo := new(MockLocationRepo)
+	s := services.NewLocationService(repo)
+
+	repo.On("GetLocation", "A").Return(&models.Location{Zone: "A", Rows: 20, ShelvesPerRow: 10}, nil).Once()
+
+	zero := 0
+	_, err := s.UpdateLocation("a", entities.LocationUpdate{Rows: &zero})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+	repo.AssertNotCalled(t, "UpdateLocation", mock.Anything)
+
+	capacity := 300
+	repo.On("GetLocation", "A").Return(&models.Location{Zone: "A", Rows: 20, ShelvesPerRow: 10}, nil).Once()
+	repo.On("UpdateLocation", mock.MatchedBy(func(l *models.Location) bool {
+		return l.ShelfCapacity == 300 && l.Rows == 20
+	})).Return(nil).Once()
+	location, err := s.UpdateLocation("A", entities.LocationUpdate{ShelfCapacity: &capacity})
+	assert.NoError(t, err)
+	assert.Equal(t, 300, location.ShelfCapacity)
+}
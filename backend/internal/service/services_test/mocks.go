@@ -0,0 +1,499 @@
+package test_services
+
+import (
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRobotRepo мок репозитория роботов
+type MockRobotRepo struct {
+	mock.Mock
+}
+
+func (m *MockRobotRepo) AddData(items []entities.IngestItem) ([]entities.IngestResult, error) {
+	args := m.Called(items)
+	if fn, ok := args.Get(0).(func([]entities.IngestItem) []entities.IngestResult); ok {
+		return fn(items), args.Error(1)
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.IngestResult), args.Error(1)
+}
+
+func (m *MockRobotRepo) CheckId(id string) bool {
+	args := m.Called(id)
+	return args.Bool(0)
+}
+
+func (m *MockRobotRepo) ListRobots() ([]models.Robots, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Robots), args.Error(1)
+}
+
+func (m *MockRobotRepo) GetRobot(id string) (*models.Robots, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Robots), args.Error(1)
+}
+
+func (m *MockRobotRepo) CreateRobot(robot *models.Robots) error {
+	args := m.Called(robot)
+	return args.Error(0)
+}
+
+func (m *MockRobotRepo) UpdateRobot(robot *models.Robots) error {
+	args := m.Called(robot)
+	return args.Error(0)
+}
+
+func (m *MockRobotRepo) DeleteRobot(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// MockRobotAuthRepo мок репозитория ключей роботов
+type MockRobotAuthRepo struct {
+	mock.Mock
+}
+
+func (m *MockRobotAuthRepo) CreateCredential(cred *models.RobotCredential) error {
+	args := m.Called(cred)
+	return args.Error(0)
+}
+
+func (m *MockRobotAuthRepo) ReplaceCredentials(cred *models.RobotCredential) error {
+	args := m.Called(cred)
+	return args.Error(0)
+}
+
+func (m *MockRobotAuthRepo) ListCredentials(robotID string) ([]models.RobotCredential, error) {
+	args := m.Called(robotID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RobotCredential), args.Error(1)
+}
+
+func (m *MockRobotAuthRepo) GetActiveCredential(keyID string) (*models.RobotCredential, error) {
+	args := m.Called(keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RobotCredential), args.Error(1)
+}
+
+func (m *MockRobotAuthRepo) RevokeCredential(robotID, keyID string) error {
+	args := m.Called(robotID, keyID)
+	return args.Error(0)
+}
+
+// MockInventoryRepo мок репозитория инвентаря
+type MockInventoryRepo struct {
+	mock.Mock
+}
+
+func (m *MockInventoryRepo) ImportInventoryHistories(histories []models.InventoryHistory) error {
+	args := m.Called(histories)
+	return args.Error(0)
+}
+
+func (m *MockInventoryRepo) GetInventoryHistoryByProductIDs(productIDs []string) ([]models.InventoryHistory, error) {
+	args := m.Called(productIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InventoryHistory), args.Error(1)
+}
+
+func (m *MockInventoryRepo) GetInventoryHistoryByScanIDs(scanIDs []string) ([]models.InventoryHistory, error) {
+	args := m.Called(scanIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InventoryHistory), args.Error(1)
+}
+
+func (m *MockInventoryRepo) GetProductsByIDs(productIDs []string) ([]models.Products, error) {
+	args := m.Called(productIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Products), args.Error(1)
+}
+
+func (m *MockInventoryRepo) GetHistory(from, to, zone, status string, limit, offset int) ([]models.InventoryHistory, int64, error) {
+	args := m.Called(from, to, zone, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.InventoryHistory), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockInventoryRepo) GetRecentScans(query entities.RecentScansQuery) ([]models.InventoryHistory, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InventoryHistory), args.Error(1)
+}
+
+func (m *MockInventoryRepo) GetStockLevels(productID, zone string) ([]models.StockLevel, error) {
+	args := m.Called(productID, zone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StockLevel), args.Error(1)
+}
+
+func (m *MockInventoryRepo) ListAnomalies(status string, limit, offset int) ([]models.InventoryHistory, int64, error) {
+	args := m.Called(status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.InventoryHistory), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockInventoryRepo) ResolveAnomaly(id uint, status string, userID uint) (*models.InventoryHistory, []models.Alert, error) {
+	args := m.Called(id, status, userID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	alerts, _ := args.Get(1).([]models.Alert)
+	return args.Get(0).(*models.InventoryHistory), alerts, args.Error(2)
+}
+
+// MockProductRepo мок репозитория товаров
+type MockProductRepo struct {
+	mock.Mock
+}
+
+func (m *MockProductRepo) ListProducts(query entities.ProductQuery) ([]models.Products, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Products), args.Error(1)
+}
+
+func (m *MockProductRepo) GetProduct(id string) (*models.Products, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Products), args.Error(1)
+}
+
+func (m *MockProductRepo) CreateProduct(product *models.Products) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepo) UpdateProduct(product *models.Products) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepo) UpsertProducts(products []models.Products) error {
+	args := m.Called(products)
+	return args.Error(0)
+}
+
+// MockLocationRepo мок репозитория раскладки склада
+type MockLocationRepo struct {
+	mock.Mock
+}
+
+func (m *MockLocationRepo) ListLocations() ([]models.Location, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Location), args.Error(1)
+}
+
+func (m *MockLocationRepo) GetLocation(zone string) (*models.Location, error) {
+	args := m.Called(zone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Location), args.Error(1)
+}
+
+func (m *MockLocationRepo) CreateLocation(location *models.Location) error {
+	args := m.Called(location)
+	return args.Error(0)
+}
+
+func (m *MockLocationRepo) UpdateLocation(location *models.Location) error {
+	args := m.Called(location)
+	return args.Error(0)
+}
+
+func (m *MockLocationRepo) DeleteLocation(zone string) error {
+	args := m.Called(zone)
+	return args.Error(0)
+}
+
+// MockAIRepo мок репозитория прогнозов
+type MockAIRepo struct {
+	mock.Mock
+}
+
+func (m *MockAIRepo) AIRequest(rq entities.AIRequest, window entities.HistoryWindow) ([]models.InventoryHistory, error) {
+	args := m.Called(rq, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InventoryHistory), args.Error(1)
+}
+
+func (m *MockAIRepo) AIResponse(rp entities.AIResponse) ([]models.Alert, error) {
+	args := m.Called(rp)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Alert), args.Error(1)
+}
+
+func (m *MockAIRepo) ListPredictions(query entities.PredictionQuery) ([]models.AiPrediction, int64, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.AiPrediction), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAIRepo) LatestPredictions(query entities.PredictionQuery) ([]models.AiPrediction, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AiPrediction), args.Error(1)
+}
+
+// MockAccuracyRepo мок репозитория оценки прогнозов
+type MockAccuracyRepo struct {
+	mock.Mock
+}
+
+func (m *MockAccuracyRepo) PendingPredictions() ([]models.AiPrediction, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AiPrediction), args.Error(1)
+}
+
+func (m *MockAccuracyRepo) FirstStockout(productID string, after time.Time) (*time.Time, error) {
+	args := m.Called(productID, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockAccuracyRepo) SaveOutcome(prediction *models.AiPrediction) error {
+	args := m.Called(prediction)
+	return args.Error(0)
+}
+
+func (m *MockAccuracyRepo) EvaluatedPredictions(from, to time.Time) ([]models.AiPrediction, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AiPrediction), args.Error(1)
+}
+
+// MockLockRepo мок аренды фоновых задач
+type MockLockRepo struct {
+	mock.Mock
+}
+
+func (m *MockLockRepo) TryLock(name, owner string, ttl time.Duration) (bool, error) {
+	args := m.Called(name, owner, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockAlertRepo мок репозитория оповещений
+type MockAlertRepo struct {
+	mock.Mock
+}
+
+func (m *MockAlertRepo) ListAlerts(query entities.AlertQuery) ([]models.Alert, int64, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Alert), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAlertRepo) GetAlert(id uint) (*models.Alert, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepo) UpdateAlert(alert *models.Alert, fromStatus string) error {
+	args := m.Called(alert, fromStatus)
+	return args.Error(0)
+}
+
+func (m *MockAlertRepo) EscalateAlerts(now time.Time, after time.Duration) ([]models.Alert, error) {
+	args := m.Called(now, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Alert), args.Error(1)
+}
+
+// MockAuthRepo мок репозитория пользователей
+type MockAuthRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuthRepo) CreateUser(user models.Users) (uint, error) {
+	args := m.Called(user)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *MockAuthRepo) GetUser(email, password string) (*models.Users, error) {
+	args := m.Called(email, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Users), args.Error(1)
+}
+
+func (m *MockAuthRepo) GetUserByID(id uint) (*models.Users, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Users), args.Error(1)
+}
+
+func (m *MockAuthRepo) ListUsers() ([]models.Users, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Users), args.Error(1)
+}
+
+func (m *MockAuthRepo) UpdateUserRole(id uint, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+// MockAlertRuleRepo мок репозитория правил оповещений
+type MockAlertRuleRepo struct {
+	mock.Mock
+}
+
+func (m *MockAlertRuleRepo) ListRules() ([]models.AlertRule, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRuleRepo) GetRule(id uint) (*models.AlertRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRuleRepo) CreateRule(rule *models.AlertRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockAlertRuleRepo) UpdateRule(rule *models.AlertRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockAlertRuleRepo) DeleteRule(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAlertRuleRepo) EvaluateRules(now time.Time) ([]models.Alert, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Alert), args.Error(1)
+}
+
+// MockNotificationRepo мок репозитория каналов уведомлений и журнала доставки
+type MockNotificationRepo struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepo) ListChannels() ([]models.NotificationChannel, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationRepo) GetChannel(id uint) (*models.NotificationChannel, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationRepo) CreateChannel(channel *models.NotificationChannel) error {
+	args := m.Called(channel)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepo) UpdateChannel(channel *models.NotificationChannel) error {
+	args := m.Called(channel)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepo) DeleteChannel(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepo) ClaimDeliveries(now time.Time, limit int, lease time.Duration) ([]models.NotificationDelivery, error) {
+	args := m.Called(now, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepo) SaveDelivery(delivery *models.NotificationDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepo) ListDeliveries(query entities.DeliveryQuery) ([]models.NotificationDelivery, int64, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.NotificationDelivery), args.Get(1).(int64), args.Error(2)
+}
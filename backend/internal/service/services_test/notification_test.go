@@ -0,0 +1,397 @@
+package test_services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var lowStockAlert = models.Alert{
+	ID: 12, Type: models.AlertTypeScanned, Severity: models.SeverityCritical, Status: models.AlertOpen,
+	ProductID: "TEL-4567", ProductName: "Роутер RT-AC68U", Zone: "A", RowNumber: 12, ShelfNumber: 3, Quantity: 4,
+	Message: "CRITICAL остаток! Требуется пополнение.", ScannedAt: time.Now(),
+}
+
+func TestWebhookNotifierSignsPayload(t *testing.T) {
+	const secret = "whsec_0123456789abcdef"
+	status := http.StatusOK
+	var received services.WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(services.WebhookTimestampHeader)
+		assert.Equal(t, "sha256="+services.SignWebhookPayload(secret, timestamp, body), r.Header.Get(services.WebhookSignatureHeader))
+		assert.Equal(t, "42", r.Header.Get(services.WebhookDeliveryHeader))
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(status)
+		fmt.Fprint(w, "busy")
+	}))
+	defer server.Close()
+
+	notifier := services.NewWebhookNotifier(time.Second)
+	notification := services.Notification{
+		DeliveryID: 42,
+		Channel:    models.NotificationChannel{Kind: models.ChannelWebhook, Target: server.URL, Secret: secret},
+		Alert:      lowStockAlert,
+	}
+	assert.NoError(t, notifier.Send(context.Background(), notification))
+	assert.Equal(t, "alert", received.Event)
+	assert.Equal(t, uint(12), received.Alert.ID)
+
+	status = http.StatusServiceUnavailable
+	err := notifier.Send(context.Background(), notification)
+	assert.ErrorContains(t, err, "webhook responded 503: busy")
+}
+
+func TestTelegramNotifierSendsMessage(t *testing.T) {
+	var chats []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bot123:secret-token/sendMessage", r.URL.Path)
+		var message struct {
+			ChatID string `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		if message.ChatID == "-100" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"ok":false,"description":"Bad Request: chat not found"}`)
+			return
+		}
+		chats = append(chats, message.ChatID)
+		assert.Contains(t, message.Text, "[CRITICAL] Остаток TEL-4567 в зоне A")
+		assert.Contains(t, message.Text, "Ячейка: A-12-3")
+		fmt.Fprint(w, `{"ok":true,"result":{}}`)
+	}))
+	defer server.Close()
+
+	notifier := services.NewTelegramNotifier(server.URL+"/", "123:secret-token", time.Second)
+	channel := models.NotificationChannel{Kind: models.ChannelTelegram, Target: "1001,1002"}
+	assert.NoError(t, notifier.Send(context.Background(), services.Notification{Channel: channel, Alert: lowStockAlert}))
+	assert.Equal(t, []string{"1001", "1002"}, chats)
+
+	channel.Target = "-100"
+	err := notifier.Send(context.Background(), services.Notification{Channel: channel, Alert: lowStockAlert})
+	assert.ErrorContains(t, err, "chat not found")
+
+	// токен бота не попадает в текст ошибки и журнал доставки
+	server.Close()
+	err = notifier.Send(context.Background(), services.Notification{Channel: channel, Alert: lowStockAlert})
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
+
+// SMTP заглушка: принимает одно письмо и возвращает конверт и текст
+func smtpStub(t *testing.T) (string, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	mails := make(chan []string, 1)
+
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		var envelope []string
+		reply := func(line string) { _ = text.PrintfLine("%s", line) }
+
+		reply("220 stub ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+			case "EHLO", "HELO":
+				reply("250 stub")
+			case "MAIL", "RCPT":
+				envelope = append(envelope, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 go ahead")
+				body, _ := text.ReadDotLines()
+				envelope = append(envelope, strings.Join(body, "\n"))
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				mails <- envelope
+				return
+			default:
+				reply("502 unknown command")
+			}
+		}
+	}()
+	return listener.Addr().String(), mails
+}
+
+func TestEmailNotifierSendsThroughSMTP(t *testing.T) {
+	addr, mails := smtpStub(t)
+
+	notifier := services.NewEmailNotifier(addr, "", "", "warehouse@example.com", time.Second)
+	channel := models.NotificationChannel{Kind: models.ChannelEmail, Target: "ops@example.com,lead@example.com"}
+	assert.NoError(t, notifier.Send(context.Background(), services.Notification{Channel: channel, Alert: lowStockAlert}))
+
+	envelope := <-mails
+	assert.Equal(t, "MAIL FROM:<warehouse@example.com>", strings.SplitN(envelope[0], " BODY", 2)[0])
+	assert.Equal(t, "RCPT TO:<ops@example.com>", envelope[1])
+	assert.Equal(t, "RCPT TO:<lead@example.com>", envelope[2])
+	assert.Contains(t, envelope[3], "To: ops@example.com, lead@example.com")
+	assert.Contains(t, envelope[3], "Subject: =?utf-8?q?")
+	assert.Contains(t, envelope[3], "Товар: Роутер RT-AC68U (TEL-4567)")
+}
+
+func TestEmailNotifierReportsUnreachableServer(t *testing.T) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := listener.Addr().String()
+	listener.Close()
+
+	notifier := services.NewEmailNotifier(addr, "", "", "", 200*time.Millisecond)
+	channel := models.NotificationChannel{Kind: models.ChannelEmail, Target: "ops@example.com"}
+	assert.Error(t, notifier.Send(context.Background(), services.Notification{Channel: channel, Alert: lowStockAlert}))
+}
+
+func TestChannelRoutesBySeverityAndZone(t *testing.T) {
+	critical := models.NotificationChannel{Enabled: true, Severities: "critical"}
+	zoneB := models.NotificationChannel{Enabled: true, Zones: "B,C"}
+	all := models.NotificationChannel{Enabled: true}
+
+	warningInB := models.Alert{Severity: models.SeverityWarning, Zone: "B"}
+	criticalInA := models.Alert{Severity: models.SeverityCritical, Zone: "A"}
+	forecast := models.Alert{Severity: models.SeverityCritical, Type: models.AlertTypePredicted}
+
+	assert.False(t, critical.Routes(warningInB))
+	assert.True(t, critical.Routes(criticalInA))
+	assert.True(t, zoneB.Routes(warningInB))
+	assert.False(t, zoneB.Routes(criticalInA))
+	assert.False(t, zoneB.Routes(forecast), "alerts without zone go only to channels without zone filter")
+	assert.True(t, all.Routes(forecast))
+	assert.False(t, models.NotificationChannel{}.Routes(forecast), "disabled channel")
+
+	escalation := models.NotificationChannel{Enabled: true, Tier: 2}
+	escalated := criticalInA
+	escalated.Tier = 2
+	assert.False(t, escalation.Routes(criticalInA), "new alerts go to tier 1")
+	assert.True(t, escalation.Routes(escalated))
+	assert.False(t, all.Routes(escalated), "tier 1 was already notified")
+}
+
+func TestNextTierSkipsChannelsThatDoNotRouteAlert(t *testing.T) {
+	channels := []models.NotificationChannel{
+		{ID: 1, Enabled: true, Tier: 1},
+		{ID: 2, Enabled: true, Tier: 2, Zones: "B"},
+		{ID: 3, Enabled: true, Tier: 3, Severities: "warning"},
+		{ID: 4, Enabled: true, Tier: 4, Zones: "A"},
+		{ID: 5, Enabled: false, Tier: 2},
+	}
+	criticalInA := models.Alert{Severity: models.SeverityCritical, Zone: "A", Tier: 1}
+
+	tier, ok := models.NextTier(channels, criticalInA)
+	assert.True(t, ok)
+	assert.Equal(t, 4, tier, "tier 2 routes only zone B, tier 3 only warnings")
+
+	criticalInA.Tier = 4
+	_, ok = models.NextTier(channels, criticalInA)
+	assert.False(t, ok, "no higher tier left")
+
+	forecast := models.Alert{Severity: models.SeverityCritical, Type: models.AlertTypePredicted}
+	_, ok = models.NextTier(channels, forecast)
+	assert.False(t, ok, "alerts without zone skip zone-filtered tiers")
+}
+
+func TestPendingDeliveriesRouteAlertToMatchingChannels(t *testing.T) {
+	channels := []models.NotificationChannel{
+		{ID: 1, Kind: models.ChannelWebhook, Enabled: true},
+		{ID: 2, Kind: models.ChannelTelegram, Enabled: true, Severities: "critical", Zones: "A"},
+		{ID: 3, Kind: models.ChannelEmail, Enabled: true, Zones: "B"},
+	}
+	now := time.Now()
+
+	d := models.PendingDeliveries(channels, lowStockAlert, now)
+	if assert.Len(t, d, 2) {
+		assert.Equal(t, uint(1), *d[0].ChannelID)
+		assert.Equal(t, uint(2), *d[1].ChannelID)
+		assert.Equal(t, models.ChannelTelegram, d[1].ChannelKind)
+		assert.Equal(t, uint(12), d[1].AlertID)
+		assert.Equal(t, models.SeverityCritical, d[1].Severity)
+		assert.Equal(t, models.DeliveryPending, d[1].Status)
+		assert.Equal(t, now, d[1].NextAttemptAt)
+	}
+}
+
+// доставка, которая падает заданное число раз
+type flakyNotifier struct {
+	failures int
+	sent     []services.Notification
+}
+
+func (n *flakyNotifier) Kind() string {
+	return models.ChannelWebhook
+}
+
+func (n *flakyNotifier) Send(ctx context.Context, notification services.Notification) error {
+	if n.failures > 0 {
+		n.failures--
+		return errors.New("connection refused")
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestDispatchRetriesWithExponentialBackoff(t *testing.T) {
+	repo := new(MockNotificationRepo)
+	notifier := &flakyNotifier{failures: 2}
+	policy := services.RetryPolicy{MaxAttempts: 3, Base: time.Minute, Max: time.Hour}
+	s := services.NewNotificationService(repo, services.NewEventHub(1, services.DropEvent), policy, notifier)
+
+	channelID := uint(1)
+	channel := &models.NotificationChannel{ID: 1, Kind: models.ChannelWebhook, Enabled: true}
+	alert := lowStockAlert
+	delivery := models.NotificationDelivery{ID: 7, AlertID: 12, ChannelID: &channelID, Status: models.DeliveryPending, Alert: &alert, Channel: channel}
+
+	now := time.Now()
+	claim := func(at time.Time) {
+		repo.On("ClaimDeliveries", at, mock.Anything, mock.Anything).Return([]models.NotificationDelivery{delivery}, nil).Once()
+	}
+	var saved []models.NotificationDelivery
+	repo.On("SaveDelivery", mock.Anything).Run(func(args mock.Arguments) {
+		d := *args.Get(0).(*models.NotificationDelivery)
+		saved = append(saved, d)
+		delivery.Attempts, delivery.NextAttemptAt, delivery.Status = d.Attempts, d.NextAttemptAt, d.Status
+	}).Return(nil)
+
+	claim(now)
+	_, err := s.Dispatch(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, saved[0].Status)
+	assert.Equal(t, now.Add(time.Minute), saved[0].NextAttemptAt)
+	assert.Equal(t, "connection refused", saved[0].LastError)
+
+	later := saved[0].NextAttemptAt
+	claim(later)
+	_, err = s.Dispatch(context.Background(), later)
+	assert.NoError(t, err)
+	assert.Equal(t, later.Add(2*time.Minute), saved[1].NextAttemptAt)
+
+	last := saved[1].NextAttemptAt
+	claim(last)
+	n, err := s.Dispatch(context.Background(), last)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, models.DeliverySent, saved[2].Status)
+	assert.Equal(t, 3, saved[2].Attempts)
+	assert.Empty(t, saved[2].LastError)
+	assert.Equal(t, uint64(7), notifier.sent[0].DeliveryID)
+}
+
+func TestDispatchGivesUpAfterMaxAttempts(t *testing.T) {
+	repo := new(MockNotificationRepo)
+	policy := services.RetryPolicy{MaxAttempts: 3, Base: time.Minute, Max: time.Hour}
+	s := services.NewNotificationService(repo, services.NewEventHub(1, services.DropEvent), policy, &flakyNotifier{failures: 10})
+
+	now := time.Now()
+	alert := lowStockAlert
+	deliveries := []models.NotificationDelivery{
+		{ID: 1, Attempts: 2, Alert: &alert, Channel: &models.NotificationChannel{Kind: models.ChannelWebhook, Enabled: true}},
+		{ID: 2, Status: models.DeliveryPending, Alert: &alert, Channel: &models.NotificationChannel{Kind: models.ChannelEmail, Enabled: true}},
+		{ID: 3, Alert: &alert},
+	}
+	repo.On("ClaimDeliveries", now, mock.Anything, mock.Anything).Return(deliveries, nil).Once()
+	failed := func(id uint64, reason string) interface{} {
+		return mock.MatchedBy(func(d *models.NotificationDelivery) bool {
+			return d.ID == id && d.Status == models.DeliveryFailed && strings.Contains(d.LastError, reason)
+		})
+	}
+	repo.On("SaveDelivery", failed(1, "connection refused")).Return(nil).Once()
+	repo.On("SaveDelivery", mock.MatchedBy(func(d *models.NotificationDelivery) bool {
+		return d.ID == 2 && d.Status == models.DeliveryPending && d.NextAttemptAt.Equal(now.Add(time.Minute)) &&
+			strings.Contains(d.LastError, "email channels are not configured")
+	})).Return(nil).Once()
+	repo.On("SaveDelivery", failed(3, "channel was deleted")).Return(nil).Once()
+
+	_, err := s.Dispatch(context.Background(), now)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := services.RetryPolicy{MaxAttempts: 10, Base: 30 * time.Second, Max: 5 * time.Minute}
+	assert.Equal(t, 30*time.Second, policy.Delay(1))
+	assert.Equal(t, time.Minute, policy.Delay(2))
+	assert.Equal(t, 4*time.Minute, policy.Delay(4))
+	assert.Equal(t, 5*time.Minute, policy.Delay(5))
+	assert.Equal(t, 5*time.Minute, policy.Delay(40))
+}
+
+func TestCreateChannelValidates(t *testing.T) {
+	repo := new(MockNotificationRepo)
+	s := services.NewNotificationService(repo, services.NewEventHub(1, services.DropEvent), services.DefaultRetryPolicy(),
+		services.NewWebhookNotifier(time.Second), services.NewTelegramNotifier("", "token", time.Second))
+
+	invalid := []entities.NotificationChannelInput{
+		{Name: "unknown kind", Kind: "sms", Target: "+70000000000"},
+		{Name: "email not configured", Kind: models.ChannelEmail, Target: "ops@example.com"},
+		{Name: "not a url", Kind: models.ChannelWebhook, Target: "ftp://hooks", Secret: "0123456789abcdef"},
+		{Name: "unsigned webhook", Kind: models.ChannelWebhook, Target: "https://hooks.example.com/alerts"},
+		{Name: "bad severity", Kind: models.ChannelTelegram, Target: "1001", Severities: "critical,info"},
+		{Name: "secret for bot", Kind: models.ChannelTelegram, Target: "1001", Secret: "0123456789abcdef"},
+		{Name: "no chat", Kind: models.ChannelTelegram, Target: " , "},
+	}
+	for _, input := range invalid {
+		_, err := s.CreateChannel(input)
+		assert.True(t, errors.Is(err, entities.ErrValidation), input.Name)
+	}
+	repo.AssertNotCalled(t, "CreateChannel", mock.Anything)
+
+	repo.On("CreateChannel", mock.MatchedBy(func(c *models.NotificationChannel) bool {
+		return c.Target == "1001,1002" && c.Zones == "A,B" && c.Severities == "critical" && c.Enabled
+	})).Return(nil).Once()
+	_, err := s.CreateChannel(entities.NotificationChannelInput{
+		Name: "Дежурные зоны A и B", Kind: models.ChannelTelegram, Target: "1001, 1002", Severities: " critical ", Zones: "a, b",
+	})
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestNotificationServiceWakesOnPublishedAlerts(t *testing.T) {
+	repo := new(MockNotificationRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	s := services.NewNotificationService(repo, hub, services.DefaultRetryPolicy())
+
+	claims := make(chan struct{}, 4)
+	repo.On("ClaimDeliveries", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		claims <- struct{}{}
+	}).Return(nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	for hub.Len() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	<-claims
+
+	// журнал уже записан репозиторием, оповещение только будит отправку раньше опроса
+	hub.Publish(entities.RobotsData{RobotId: "RB-001"})
+	hub.Publish(lowStockAlert)
+	select {
+	case <-claims:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch was not woken")
+	}
+}
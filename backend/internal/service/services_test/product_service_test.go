@@ -0,0 +1,105 @@
+package test_services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateProductDefaultsAndValidation(t *testing.T) {
+	repo := new(MockProductRepo)
+	s := services.NewProductService(repo)
+
+	repo.On("CreateProduct", mock.MatchedBy(func(p *models.Products) bool {
+		return p.ID == "TEL-9999" && p.MinStock == 10 && p.OptimalStock == 100
+	})).Return(nil).Once()
+
+	product, err := s.CreateProduct(entities.ProductInput{ID: " TEL-9999 ", Name: "Точка доступа"})
+	assert.NoError(t, err)
+	assert.Equal(t, "TEL-9999", product.ID)
+
+	minStock, optimal := 50, 20
+	_, err = s.CreateProduct(entities.ProductInput{ID: "TEL-1", Name: "x", MinStock: &minStock, OptimalStock: &optimal})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateProductKeepsThresholdsConsistent(t *testing.T) {
+	repo := new(MockProductRepo)
+	s := services.NewProductService(repo)
+
+	repo.On("GetProduct", "TEL-4567").
+		Return(&models.Products{ID: "TEL-4567", Name: "Роутер", MinStock: 10, OptimalStock: 100}, nil)
+
+	minStock := 150
+	_, err := s.UpdateProduct("TEL-4567", entities.ProductUpdate{MinStock: &minStock})
+	assert.True(t, errors.Is(err, entities.ErrValidation))
+	repo.AssertNotCalled(t, "UpdateProduct", mock.Anything)
+}
+
+func TestArchiveProduct(t *testing.T) {
+	repo := new(MockProductRepo)
+	s := services.NewProductService(repo)
+
+	repo.On("GetProduct", "TEL-4567").Return(&models.Products{ID: "TEL-4567", Name: "Роутер"}, nil).Once()
+	repo.On("UpdateProduct", mock.MatchedBy(func(p *models.Products) bool {
+		return p.ArchivedAt != nil
+	})).Return(nil).Once()
+
+	product, err := s.ArchiveProduct("TEL-4567")
+	assert.NoError(t, err)
+	assert.NotNil(t, product.ArchivedAt)
+	repo.AssertExpectations(t)
+}
+
+func TestUnarchiveProduct(t *testing.T) {
+	repo := new(MockProductRepo)
+	s := services.NewProductService(repo)
+
+	archivedAt := time.Now()
+	repo.On("GetProduct", "TEL-4567").Return(&models.Products{ID: "TEL-4567", ArchivedAt: &archivedAt}, nil).Once()
+	repo.On("UpdateProduct", mock.MatchedBy(func(p *models.Products) bool {
+		return p.ArchivedAt == nil
+	})).Return(nil).Once()
+
+	product, err := s.UnarchiveProduct("TEL-4567")
+	assert.NoError(t, err)
+	assert.Nil(t, product.ArchivedAt)
+
+	// товар не в архиве - ничего не сохраняется
+	repo.On("GetProduct", "TEL-1111").Return(&models.Products{ID: "TEL-1111"}, nil).Once()
+	_, err = s.UnarchiveProduct("TEL-1111")
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestImportProductsCSV(t *testing.T) {
+	repo := new(MockProductRepo)
+	s := services.NewProductService(repo)
+
+	csv := "id;name;category;min_stock;optimal_stock\n" +
+		"TEL-9999;Точка доступа;network;5;50\n" +
+		"TEL-8888;Без порогов;network;;\n" +
+		"TEL-7777;Плохие пороги;network;60;50\n" +
+		"TEL-9999;Точка доступа v2;network;5;60\n"
+
+	repo.On("UpsertProducts", mock.MatchedBy(func(p []models.Products) bool {
+		return len(p) == 2 &&
+			p[0].Name == "Точка доступа v2" && p[0].OptimalStock == 60 &&
+			p[1].MinStock == 10 && p[1].OptimalStock == 100
+	})).Return(nil).Once()
+
+	result, err := s.ImportProductsCSV(strings.NewReader(csv))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.SuccessCount)
+	assert.Equal(t, 1, result.FailedCount)
+	assert.Contains(t, result.Errors[0], "line 4")
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,147 @@
+package test_services
+
+import (
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// signedRequest подписывает запрос так же, как это делает робот
+func signedRequest(keyID, secret string, at time.Time, body string) entities.RobotSignedRequest {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	sig := services.SignRobotRequest([]byte(secret), ts, "POST", "/api/robots/data", []byte(body))
+	return entities.RobotSignedRequest{
+		KeyID:     keyID,
+		Timestamp: ts,
+		Signature: hex.EncodeToString(sig),
+		Method:    "POST",
+		Path:      "/api/robots/data",
+		Body:      []byte(body),
+	}
+}
+
+func TestRobotAuthIssueAndVerify(t *testing.T) {
+	t.Setenv("ROBOT_SECRET_KEY", "test-server-key")
+	robots := new(MockRobotRepo)
+	repo := new(MockRobotAuthRepo)
+	s := services.NewRobotAuthService(repo, robots, nil)
+
+	robots.On("GetRobot", "RB-001").Return(&models.Robots{ID: "RB-001", Status: models.RobotStatusActive}, nil)
+
+	var stored *models.RobotCredential
+	repo.On("CreateCredential", mock.AnythingOfType("*models.RobotCredential")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.RobotCredential)
+	}).Return(nil)
+
+	key, err := s.IssueKey("RB-001")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, key.Secret)
+	assert.NotContains(t, stored.SecretEncrypted, key.Secret)
+	assert.NotContains(t, stored.SecretEncrypted, hex.EncodeToString([]byte(key.Secret)))
+
+	repo.On("GetActiveCredential", key.KeyID).Return(stored, nil)
+
+	req := signedRequest(key.KeyID, key.Secret, time.Now(), `{"robot_id":"RB-001"}`)
+	robotID, err := s.VerifyRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "RB-001", robotID)
+
+	// повтор того же запроса отклоняется
+	_, err = s.VerifyRequest(req)
+	assert.ErrorIs(t, err, entities.ErrUnauthorized)
+
+	// тело подменено
+	tampered := signedRequest(key.KeyID, key.Secret, time.Now().Add(time.Second), `{"robot_id":"RB-001"}`)
+	tampered.Body = []byte(`{"robot_id":"RB-002"}`)
+	_, err = s.VerifyRequest(tampered)
+	assert.ErrorIs(t, err, entities.ErrUnauthorized)
+
+	// устаревшая подпись
+	_, err = s.VerifyRequest(signedRequest(key.KeyID, key.Secret, time.Now().Add(-time.Hour), `{}`))
+	assert.ErrorIs(t, err, entities.ErrUnauthorized)
+
+	// неверный секрет
+	_, err = s.VerifyRequest(signedRequest(key.KeyID, "wrong", time.Now(), `{}`))
+	assert.ErrorIs(t, err, entities.ErrUnauthorized)
+}
+
+func TestRobotAuthStoredSecret(t *testing.T) {
+	t.Setenv("ROBOT_SECRET_KEY", "test-server-key")
+	robots := new(MockRobotRepo)
+	repo := new(MockRobotAuthRepo)
+	s := services.NewRobotAuthService(repo, robots, nil)
+
+	robots.On("GetRobot", "RB-001").Return(&models.Robots{ID: "RB-001", Status: models.RobotStatusActive}, nil)
+	var stored []*models.RobotCredential
+	repo.On("CreateCredential", mock.AnythingOfType("*models.RobotCredential")).Run(func(args mock.Arguments) {
+		stored = append(stored, args.Get(0).(*models.RobotCredential))
+	}).Return(nil)
+
+	first, err := s.IssueKey("RB-001")
+	assert.NoError(t, err)
+	second, err := s.IssueKey("RB-001")
+	assert.NoError(t, err)
+
+	// строка из бд не подписывает запросы
+	leaked := signedRequest(first.KeyID, stored[0].SecretEncrypted, time.Now(), `{}`)
+	repo.On("GetActiveCredential", first.KeyID).Return(stored[0], nil).Once()
+	_, err = s.VerifyRequest(leaked)
+	assert.ErrorIs(t, err, entities.ErrUnauthorized)
+
+	// зашифрованный секрет другого ключа не подходит
+	swapped := *stored[1]
+	swapped.KeyID = first.KeyID
+	repo.On("GetActiveCredential", first.KeyID).Return(&swapped, nil).Once()
+	_, err = s.VerifyRequest(signedRequest(first.KeyID, second.Secret, time.Now(), `{}`))
+	assert.Error(t, err)
+
+	// другой ключ сервера не расшифрует секрет
+	t.Setenv("ROBOT_SECRET_KEY", "other-server-key")
+	other := services.NewRobotAuthService(repo, robots, nil)
+	repo.On("GetActiveCredential", first.KeyID).Return(stored[0], nil).Once()
+	_, err = other.VerifyRequest(signedRequest(first.KeyID, first.Secret, time.Now(), `{}`))
+	assert.Error(t, err)
+}
+
+func TestRobotAuthWithoutServerKey(t *testing.T) {
+	t.Setenv("ROBOT_SECRET_KEY", "")
+	robots := new(MockRobotRepo)
+	repo := new(MockRobotAuthRepo)
+	s := services.NewRobotAuthService(repo, robots, nil)
+
+	robots.On("GetRobot", "RB-001").Return(&models.Robots{ID: "RB-001", Status: models.RobotStatusActive}, nil)
+
+	_, err := s.IssueKey("RB-001")
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "CreateCredential", mock.Anything)
+}
+
+func TestRobotAuthRevokedKey(t *testing.T) {
+	robots := new(MockRobotRepo)
+	repo := new(MockRobotAuthRepo)
+	s := services.NewRobotAuthService(repo, robots, nil)
+
+	repo.On("GetActiveCredential", "rk_revoked").Return(nil, entities.ErrNotFound)
+
+	_, err := s.VerifyRequest(signedRequest("rk_revoked", "secret", time.Now(), `{}`))
+	assert.ErrorIs(t, err, entities.ErrUnauthorized)
+}
+
+func TestRobotAuthDecommissionedRobot(t *testing.T) {
+	robots := new(MockRobotRepo)
+	repo := new(MockRobotAuthRepo)
+	s := services.NewRobotAuthService(repo, robots, nil)
+
+	robots.On("GetRobot", "RB-009").Return(&models.Robots{ID: "RB-009", Status: models.RobotStatusDecommissioned}, nil)
+
+	_, err := s.RotateKeys("RB-009")
+	assert.ErrorIs(t, err, entities.ErrValidation)
+	repo.AssertNotCalled(t, "ReplaceCredentials", mock.Anything)
+}
@@ -0,0 +1,172 @@
+package test_services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// savedAll отвечает на AddData так, будто каждое сообщение записано впервые
+func savedAll(items []entities.IngestItem) []entities.IngestResult {
+	results := make([]entities.IngestResult, len(items))
+	for i, item := range items {
+		results[i] = entities.IngestResult{MessageID: item.MessageID}
+	}
+	return results
+}
+func TestRobotAddDataDerivesMessageID(t *testing.T) {
+	repo := new(MockRobotRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+	s := services.NewRobotService(repo, hub, nil)
+
+	ts := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	data := entities.RobotsData{RobotId: "RB-001", Timestamp: ts, NextCheckpoint: "A-1-1"}
+
+	repo.On("CheckId", "RB-001").Return(true)
+	repo.On("AddData", mock.MatchedBy(func(items []entities.IngestItem) bool {
+		return len(items) == 1 &&
+			items[0].Data.MessageID == "RB-001@2025-01-01T10:00:00Z" &&
+			strings.HasPrefix(items[0].MessageID, "msg_")
+	})).Return([]entities.IngestResult{{MessageID: "msg_1"}}, nil).Once()
+
+	result, err := s.AddData(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "msg_1", result.MessageID)
+	assert.Len(t, sub.Events(), 1)
+	repo.AssertExpectations(t)
+}
+
+func TestRobotAddDataDuplicateIsNotPublished(t *testing.T) {
+	repo := new(MockRobotRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+	s := services.NewRobotService(repo, hub, nil)
+
+	data := entities.RobotsData{MessageID: "client-7", RobotId: "RB-001", Timestamp: time.Now()}
+
+	repo.On("CheckId", "RB-001").Return(true)
+	repo.On("AddData", mock.AnythingOfType("[]entities.IngestItem")).
+		Return([]entities.IngestResult{{MessageID: "msg_first", Duplicate: true}}, nil).Once()
+
+	result, err := s.AddData(data)
+	assert.NoError(t, err)
+	assert.True(t, result.Duplicate)
+	assert.Equal(t, "msg_first", result.MessageID)
+	assert.Len(t, sub.Events(), 0)
+}
+
+func TestRobotAddBatchChunks(t *testing.T) {
+	repo := new(MockRobotRepo)
+	hub := services.NewEventHub(512, services.DropEvent)
+	sub := hub.Subscribe()
+	s := services.NewRobotService(repo, hub, nil)
+
+	batch := make([]entities.RobotsData, 250)
+	for i := range batch {
+		batch[i] = entities.RobotsData{RobotId: "RB-001", Timestamp: time.Unix(int64(1700000000+i), 0)}
+	}
+	batch[10].RobotId = "RB-999"
+
+	repo.On("CheckId", "RB-001").Return(true).Once()
+	repo.On("CheckId", "RB-999").Return(false).Once()
+
+	var chunkSizes []int
+	repo.On("AddData", mock.AnythingOfType("[]entities.IngestItem")).Run(func(args mock.Arguments) {
+		chunkSizes = append(chunkSizes, len(args.Get(0).([]entities.IngestItem)))
+	}).Return(savedAll, nil).Times(2)
+	repo.On("AddData", mock.AnythingOfType("[]entities.IngestItem")).Return(nil, errors.New("connection reset")).Once()
+
+	results := s.AddBatch(batch)
+
+	assert.Len(t, results, 250)
+	assert.Equal(t, []int{99, 100}, chunkSizes)
+	assert.Contains(t, results[10].Error, "invalid robot id")
+	assert.Empty(t, results[11].Error)
+	assert.NotEmpty(t, results[11].MessageID)
+	assert.Equal(t, "connection reset", results[249].Error)
+	assert.Len(t, sub.Events(), 199)
+	repo.AssertExpectations(t)
+}
+
+func TestRobotAddDataPublishesComputedStatus(t *testing.T) {
+	repo := new(MockRobotRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+	s := services.NewRobotService(repo, hub, nil)
+
+	data := entities.RobotsData{
+		MessageID: "client-1",
+		RobotId:   "RB-001",
+		Timestamp: time.Now(),
+		ScanResults: []entities.ScanResults{
+			{ProductId: "TEL-4567", Quantity: 3, Status: "OK"},
+		},
+	}
+
+	repo.On("CheckId", "RB-001").Return(true)
+	repo.On("AddData", mock.AnythingOfType("[]entities.IngestItem")).
+		Return([]entities.IngestResult{{MessageID: "msg_1", Statuses: []string{"CRITICAL"}}}, nil).Once()
+
+	_, err := s.AddData(data)
+	assert.NoError(t, err)
+
+	ev, _ := receive(t, sub)
+	published := ev.(entities.RobotsData)
+	assert.Equal(t, "CRITICAL", published.ScanResults[0].Status)
+	assert.Equal(t, "OK", data.ScanResults[0].Status)
+}
+
+func TestRobotAddDataKeepsValidationError(t *testing.T) {
+	repo := new(MockRobotRepo)
+	s := services.NewRobotService(repo, services.NewEventHub(1, services.DropEvent), nil)
+
+	cause := fmt.Errorf("%w: product OLD-0001 is archived", entities.ErrValidation)
+	repo.On("CheckId", "RB-001").Return(true)
+	repo.On("AddData", mock.AnythingOfType("[]entities.IngestItem")).
+		Return([]entities.IngestResult{{Error: cause.Error(), Err: cause}}, nil).Once()
+
+	_, err := s.AddData(entities.RobotsData{RobotId: "RB-001", Timestamp: time.Now()})
+	assert.ErrorIs(t, err, entities.ErrValidation)
+	assert.ErrorContains(t, err, "OLD-0001 is archived")
+}
+
+func TestRobotAddDataDoesNotPublishAnomalies(t *testing.T) {
+	repo := new(MockRobotRepo)
+	hub := services.NewEventHub(4, services.DropEvent)
+	sub := hub.Subscribe()
+	s := services.NewRobotService(repo, hub, nil)
+
+	data := entities.RobotsData{
+		MessageID: "client-2",
+		RobotId:   "RB-001",
+		Timestamp: time.Now(),
+		ScanResults: []entities.ScanResults{
+			{ProductId: "TEL-4567", Quantity: 0},
+			{ProductId: "TEL-8901", Quantity: 40},
+		},
+	}
+
+	repo.On("CheckId", "RB-001").Return(true)
+	repo.On("AddData", mock.AnythingOfType("[]entities.IngestItem")).
+		Return([]entities.IngestResult{{
+			MessageID: "msg_2",
+			Statuses:  []string{"CRITICAL", "OK"},
+			Anomalies: []bool{true, false},
+		}}, nil).Once()
+
+	_, err := s.AddData(data)
+	assert.NoError(t, err)
+
+	ev, _ := receive(t, sub)
+	published := ev.(entities.RobotsData)
+	assert.Len(t, published.ScanResults, 1)
+	assert.Equal(t, "TEL-8901", published.ScanResults[0].ProductId)
+}
@@ -0,0 +1,109 @@
+package test_services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// series строит ряд остатков товара с шагом в сутки
+func series(product models.Products, quantities ...int) []models.InventoryHistory {
+	start := time.Now().Add(-time.Duration(len(quantities)) * 24 * time.Hour)
+	history := make([]models.InventoryHistory, len(quantities))
+	for i, q := range quantities {
+		history[i] = models.InventoryHistory{
+			ProductID: product.ID,
+			Quantity:  q,
+			ScannedAt: start.Add(time.Duration(i) * 24 * time.Hour),
+			Product:   product,
+		}
+	}
+	return history
+}
+
+func TestStatisticalForecastSteadyConsumption(t *testing.T) {
+	router := models.Products{ID: "TEL-4567", Name: "Роутер", MinStock: 10, OptimalStock: 100}
+	history := series(router, 100, 90, 80, 70, 60, 50, 40, 30, 20, 10) // расход 10 в день
+
+	resp, err := services.NewStatisticalForecaster().Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, history)
+	assert.NoError(t, err)
+	assert.Equal(t, services.ProviderStatistical, resp.Provider)
+	assert.Len(t, resp.Predictions, 1)
+
+	p := resp.Predictions[0]
+	assert.Equal(t, "Роутер", p.ProductName)
+	assert.Equal(t, 0, p.DaysUntilStockout)        // остаток уже на min_stock
+	assert.Equal(t, 100+70-10, p.RecommendedOrder) // до оптимума плюс расход за неделю
+	assert.Equal(t, 0.95, p.ConfidenceScore)       // ровный расход, полная выборка
+	assert.Equal(t, time.Now().Format("2006-01-02"), p.PredictionDate)
+}
+
+func TestStatisticalForecastIgnoresReplenishment(t *testing.T) {
+	modem := models.Products{ID: "TEL-8901", Name: "Модем", MinStock: 5, OptimalStock: 50}
+	history := series(modem, 50, 45, 40, 90, 85, 80) // пополнение в середине ряда
+
+	resp, err := services.NewStatisticalForecaster().Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, history)
+	assert.NoError(t, err)
+
+	p := resp.Predictions[0]
+	assert.Greater(t, p.DaysUntilStockout, 0)
+	assert.Less(t, p.DaysUntilStockout, 30)
+	assert.Less(t, p.ConfidenceScore, 0.95)
+}
+
+func TestStatisticalForecastWithoutConsumption(t *testing.T) {
+	cable := models.Products{ID: "TEL-3456", Name: "Кабель", MinStock: 20, OptimalStock: 200}
+
+	resp, err := services.NewStatisticalForecaster().Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, series(cable, 150))
+	assert.NoError(t, err)
+	assert.Equal(t, 365, resp.Predictions[0].DaysUntilStockout)
+	assert.Equal(t, 50, resp.Predictions[0].RecommendedOrder)
+	assert.Equal(t, 0.1, resp.Predictions[0].ConfidenceScore)
+}
+
+func TestStatisticalForecastAcrossShelves(t *testing.T) {
+	router := models.Products{ID: "TEL-4567", Name: "Роутер", MinStock: 10, OptimalStock: 200}
+	// две ячейки сканируются по очереди, в каждой расход 5 в день
+	first := series(router, 100, 95, 90, 85, 80, 75)
+	second := series(router, 40, 35, 30, 25, 20, 15)
+	var history []models.InventoryHistory
+	for i := range first {
+		first[i].Zone, first[i].RowNumber, first[i].ShelfNumber = "A", 1, 1
+		second[i].Zone, second[i].RowNumber, second[i].ShelfNumber = "B", 2, 3
+		second[i].ScannedAt = second[i].ScannedAt.Add(time.Hour)
+		history = append(history, first[i], second[i])
+	}
+
+	resp, err := services.NewStatisticalForecaster().Forecast(context.Background(), entities.AIRequest{PeriodDays: 7}, history)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Predictions, 1)
+
+	p := resp.Predictions[0]
+	// остаток 75+15, расход 10 в день по обеим ячейкам
+	assert.Equal(t, (75+15-10)/10, p.DaysUntilStockout)
+	assert.Equal(t, 200+70-90, p.RecommendedOrder)
+	assert.Equal(t, 0.95, p.ConfidenceScore)
+}
+
+func TestFallbackForecaster(t *testing.T) {
+	primary := &fixedForecaster{err: errors.New("gigachat unreachable")}
+	fallback := &fixedForecaster{response: &entities.AIResponse{Confidence: 0.5}}
+	f := services.NewFallbackForecaster(primary, fallback)
+
+	resp, err := f.Forecast(context.Background(), entities.AIRequest{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, resp.Confidence)
+	assert.Equal(t, "fixed", resp.Provider)
+
+	fallback.err = errors.New("no data")
+	fallback.response = nil
+	_, err = f.Forecast(context.Background(), entities.AIRequest{}, nil)
+	assert.ErrorContains(t, err, "gigachat unreachable")
+	assert.ErrorContains(t, err, "no data")
+}
@@ -0,0 +1,203 @@
+package test_services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Senpa1k/Smart_Warehouse/internal/entities"
+	"github.com/Senpa1k/Smart_Warehouse/internal/models"
+	"github.com/Senpa1k/Smart_Warehouse/internal/service/services"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apply выполняет команду и возвращает ответ в виде, который увидит клиент
+func apply(t *testing.T, subs *services.StreamSubscriptions, command string) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(subs.Apply([]byte(command)))
+	require.NoError(t, err)
+	var reply map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &reply))
+	return reply
+}
+
+func TestStreamSubscriptions(t *testing.T) {
+	zoneA := services.StreamEvent{Type: services.StreamRobotUpdate, Zone: "A", RobotID: "RB-001"}
+	zoneC := services.StreamEvent{Type: services.StreamRobotUpdate, Zone: "C", RobotID: "RB-002"}
+	critical := services.StreamEvent{Type: services.StreamInventoryAlert, Zone: "A", Category: "Сетевое", Severity: models.SeverityCritical}
+	warning := services.StreamEvent{Type: services.StreamInventoryAlert, Zone: "A", Category: "Сетевое", Severity: models.SeverityWarning}
+
+	t.Run("all events without subscriptions", func(t *testing.T) {
+		subs := services.NewStreamSubscriptions()
+		assert.True(t, subs.Allows(zoneA))
+		assert.True(t, subs.Allows(critical))
+	})
+
+	t.Run("zone filter", func(t *testing.T) {
+		subs := services.NewStreamSubscriptions()
+		reply := apply(t, subs, `{"action":"subscribe","filter":{"zones":["c"]}}`)
+		assert.Equal(t, "subscribed", reply["type"])
+		assert.Equal(t, "sub-1", reply["data"].(map[string]interface{})["id"])
+
+		assert.False(t, subs.Allows(zoneA))
+		assert.True(t, subs.Allows(zoneC))
+	})
+
+	t.Run("severity filter skips events without severity", func(t *testing.T) {
+		subs := services.NewStreamSubscriptions()
+		apply(t, subs, `{"action":"subscribe","filter":{"severities":["critical"],"categories":["Сетевое"]}}`)
+
+		assert.True(t, subs.Allows(critical))
+		assert.False(t, subs.Allows(warning))
+		assert.False(t, subs.Allows(zoneA))
+	})
+
+	t.Run("subscriptions are combined", func(t *testing.T) {
+		subs := services.NewStreamSubscriptions()
+		apply(t, subs, `{"action":"subscribe","filter":{"zones":["C"]}}`)
+		apply(t, subs, `{"action":"subscribe","filter":{"types":["inventory_alert"],"severities":["warning"]}}`)
+
+		assert.True(t, subs.Allows(zoneC))
+		assert.True(t, subs.Allows(warning))
+		assert.False(t, subs.Allows(zoneA))
+		assert.False(t, subs.Allows(critical))
+	})
+
+	t.Run("subscription with the same id is replaced", func(t *testing.T) {
+		subs := services.NewStreamSubscriptions()
+		apply(t, subs, `{"action":"subscribe","id":"robots","filter":{"robots":["rb-001"]}}`)
+		assert.True(t, subs.Allows(zoneA))
+
+		apply(t, subs, `{"action":"subscribe","id":"robots","filter":{"robots":["RB-002"]}}`)
+		assert.False(t, subs.Allows(zoneA))
+		assert.True(t, subs.Allows(zoneC))
+
+		reply := apply(t, subs, `{"action":"list"}`)
+		assert.Equal(t, "subscriptions", reply["type"])
+		assert.Len(t, reply["data"], 1)
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		subs := services.NewStreamSubscriptions()
+		apply(t, subs, `{"action":"subscribe","id":"c","filter":{"zones":["C"]}}`)
+		apply(t, subs, `{"action":"subscribe","id":"alerts","filter":{"types":["inventory_alert"]}}`)
+
+		reply := apply(t, subs, `{"action":"unsubscribe","id":"alerts"}`)
+		assert.Equal(t, "unsubscribed", reply["type"])
+		assert.EqualValues(t, 1, reply["data"].(map[string]interface{})["remaining"])
+		assert.False(t, subs.Allows(critical))
+		assert.True(t, subs.Allows(zoneC))
+
+		reply = apply(t, subs, `{"action":"unsubscribe","id":"alerts"}`)
+		assert.Equal(t, "error", reply["type"])
+
+		// отписка от всего прекращает поток событий
+		apply(t, subs, `{"action":"unsubscribe"}`)
+		assert.False(t, subs.Allows(zoneC))
+		assert.False(t, subs.Allows(critical))
+	})
+
+	t.Run("invalid commands", func(t *testing.T) {
+		subs := services.NewStreamSubscriptions()
+		for _, command := range []string{
+			`not json`,
+			`{"action":"listen"}`,
+			`{"action":"subscribe","filter":{"types":["robot_moved"]}}`,
+			`{"action":"subscribe","filter":{"severities":["info"]}}`,
+		} {
+			reply := apply(t, subs, command)
+			assert.Equal(t, "error", reply["type"], command)
+		}
+		// ошибочные команды не включают фильтрацию
+		assert.True(t, subs.Allows(zoneA))
+	})
+
+	t.Run("subscription limit", func(t *testing.T) {
+		subs := services.NewStreamSubscriptions()
+		for i := 0; i < 20; i++ {
+			reply := apply(t, subs, fmt.Sprintf(`{"action":"subscribe","id":"s%d"}`, i))
+			require.Equal(t, "subscribed", reply["type"])
+		}
+		reply := apply(t, subs, `{"action":"subscribe"}`)
+		assert.Equal(t, "error", reply["type"])
+
+		// замена существующей подписки в лимит не упирается
+		reply = apply(t, subs, `{"action":"subscribe","id":"s0","filter":{"zones":["B"]}}`)
+		assert.Equal(t, "subscribed", reply["type"])
+	})
+}
+
+func TestDescribeEvent(t *testing.T) {
+	var data entities.RobotsData
+	data.RobotId = "RB-001"
+	data.Location.Zone = "A"
+	event, ok := services.DescribeEvent(data)
+	assert.True(t, ok)
+	assert.Equal(t, services.StreamEvent{Type: services.StreamRobotUpdate, Zone: "A", RobotID: "RB-001"}, event)
+
+	event, ok = services.DescribeEvent(models.Alert{Zone: "B", RobotID: "RB-002", Category: "Кабели", Severity: models.SeverityWarning})
+	assert.True(t, ok)
+	assert.Equal(t, services.StreamEvent{Type: services.StreamInventoryAlert, Zone: "B", RobotID: "RB-002", Category: "Кабели", Severity: models.SeverityWarning}, event)
+
+	_, ok = services.DescribeEvent("ai_response")
+	assert.False(t, ok)
+
+	// статус робота из Redis фильтруется по зоне так же, как сканирование
+	event = services.DescribeRobotData(`{"type":"robot_data","robot_id":"RB-003","zone":"C","battery":80}`)
+	assert.Equal(t, services.StreamEvent{Type: services.StreamRobotData, Zone: "C", RobotID: "RB-003"}, event)
+	subs := services.NewStreamSubscriptions()
+	subs.Apply([]byte(`{"action":"subscribe","filter":{"zones":["A"]}}`))
+	assert.False(t, subs.Allows(event))
+}
+
+func TestRunStreamSubscriptions(t *testing.T) {
+	hub := services.NewEventHub(16, services.DropEvent)
+	stream := services.NewWebsocketDashBoard(hub, nil)
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		stream.RunStream(conn)
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	read := func() map[string]interface{} {
+		t.Helper()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		var message map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&message))
+		return message
+	}
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{
+		"action": "subscribe",
+		"filter": map[string]interface{}{"zones": []string{"C"}},
+	}))
+	assert.Equal(t, "subscribed", read()["type"])
+
+	for _, zone := range []string{"A", "C"} {
+		var data entities.RobotsData
+		data.RobotId = "RB-00" + zone
+		data.Location.Zone = zone
+		data.NextCheckpoint = zone + "-1-2"
+		hub.Publish(data)
+	}
+
+	message := read()
+	assert.Equal(t, services.StreamRobotUpdate, message["type"])
+	assert.Equal(t, "RB-00C", message["data"].(map[string]interface{})["id"])
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_robot_credentials_robot;
+
+DROP TABLE IF EXISTS robot_credentials;
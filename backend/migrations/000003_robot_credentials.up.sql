@@ -0,0 +1,10 @@
+CREATE TABLE robot_credentials (
+    id SERIAL PRIMARY KEY,
+    robot_id VARCHAR(50) NOT NULL REFERENCES robots(id) ON DELETE CASCADE,
+    key_id VARCHAR(64) UNIQUE NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_robot_credentials_robot ON robot_credentials(robot_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_inventory_message;
+
+ALTER TABLE IF EXISTS inventory_history DROP COLUMN IF EXISTS message_id;
+
+DROP TABLE IF EXISTS robot_messages;
@@ -0,0 +1,11 @@
+CREATE TABLE robot_messages (
+    id VARCHAR(64) PRIMARY KEY,
+    robot_id VARCHAR(50) NOT NULL REFERENCES robots(id) ON DELETE CASCADE,
+    client_message_id VARCHAR(255) NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (robot_id, client_message_id)
+);
+
+ALTER TABLE inventory_history ADD COLUMN message_id VARCHAR(64) REFERENCES robot_messages(id);
+
+CREATE INDEX idx_inventory_message ON inventory_history(message_id);
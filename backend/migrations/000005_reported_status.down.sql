@@ -0,0 +1,3 @@
+UPDATE inventory_history SET status = reported_status WHERE reported_status IS NOT NULL;
+
+ALTER TABLE IF EXISTS inventory_history DROP COLUMN IF EXISTS reported_status;
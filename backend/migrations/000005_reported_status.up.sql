@@ -0,0 +1,11 @@
+ALTER TABLE inventory_history ADD COLUMN reported_status VARCHAR(50);
+
+UPDATE inventory_history SET reported_status = status WHERE robot_id <> 'IMPORT_SERVICE';
+
+UPDATE inventory_history h SET status = CASE
+    WHEN h.quantity <= p.min_stock THEN 'CRITICAL'
+    WHEN h.quantity <= p.optimal_stock / 2 THEN 'LOW_STOCK'
+    ELSE 'OK'
+END
+FROM products p
+WHERE p.id = h.product_id;
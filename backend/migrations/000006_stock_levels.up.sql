@@ -0,0 +1,21 @@
+CREATE TABLE stock_levels (
+    product_id VARCHAR(50) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    zone VARCHAR(10) NOT NULL,
+    row_number INTEGER NOT NULL DEFAULT 0,
+    shelf_number INTEGER NOT NULL DEFAULT 0,
+    quantity INTEGER NOT NULL,
+    status VARCHAR(50),
+    scanned_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, zone, row_number, shelf_number)
+);
+
+CREATE INDEX idx_stock_levels_zone ON stock_levels(zone);
+
+-- начальное заполнение последним сканированием каждой ячейки
+INSERT INTO stock_levels (product_id, zone, row_number, shelf_number, quantity, status, scanned_at)
+SELECT DISTINCT ON (product_id, zone, COALESCE(row_number, 0), COALESCE(shelf_number, 0))
+    product_id, zone, COALESCE(row_number, 0), COALESCE(shelf_number, 0), quantity, status, scanned_at
+FROM inventory_history
+WHERE product_id IS NOT NULL
+ORDER BY product_id, zone, COALESCE(row_number, 0), COALESCE(shelf_number, 0), scanned_at DESC, id DESC;
@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN archived_at TIMESTAMP;
@@ -0,0 +1,17 @@
+CREATE TABLE locations (
+    zone VARCHAR(10) PRIMARY KEY,
+    name VARCHAR(100),
+    rows INTEGER NOT NULL CHECK (rows > 0),
+    shelves_per_row INTEGER NOT NULL CHECK (shelves_per_row > 0),
+    shelf_capacity INTEGER NOT NULL DEFAULT 0 CHECK (shelf_capacity >= 0), -- 0 - без ограничения
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- текущая раскладка склада, по которой работают эмулятор и карта дашборда
+INSERT INTO locations (zone, name, rows, shelves_per_row, shelf_capacity) VALUES
+('A', 'Зона A', 20, 10, 500),
+('B', 'Зона B', 20, 10, 500),
+('C', 'Зона C', 20, 10, 500),
+('D', 'Зона D', 20, 10, 500),
+('E', 'Зона E', 20, 10, 500);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_inventory_product_scanned;
+DROP INDEX IF EXISTS idx_ai_predictions_pending;
+
+ALTER TABLE IF EXISTS ai_predictions DROP COLUMN IF EXISTS evaluated_at;
+ALTER TABLE IF EXISTS ai_predictions DROP COLUMN IF EXISTS actual_days;
+ALTER TABLE IF EXISTS ai_predictions DROP COLUMN IF EXISTS actual_stockout_at;
+ALTER TABLE IF EXISTS ai_predictions DROP COLUMN IF EXISTS provider;
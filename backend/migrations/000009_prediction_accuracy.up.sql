@@ -0,0 +1,8 @@
+-- поставщик прогноза и фактический исход для оценки точности
+ALTER TABLE ai_predictions ADD COLUMN provider VARCHAR(50);
+ALTER TABLE ai_predictions ADD COLUMN actual_stockout_at TIMESTAMP; -- NULL - остаток не опускался до min_stock
+ALTER TABLE ai_predictions ADD COLUMN actual_days INTEGER;
+ALTER TABLE ai_predictions ADD COLUMN evaluated_at TIMESTAMP;
+
+CREATE INDEX idx_ai_predictions_pending ON ai_predictions(created_at) WHERE evaluated_at IS NULL;
+CREATE INDEX idx_inventory_product_scanned ON inventory_history(product_id, scanned_at);
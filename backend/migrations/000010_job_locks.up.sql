@@ -0,0 +1,6 @@
+-- аренда фоновых задач между репликами backend, если Redis недоступен
+CREATE TABLE job_locks (
+    name VARCHAR(100) PRIMARY KEY,
+    owner VARCHAR(255) NOT NULL,
+    locked_until TIMESTAMP NOT NULL
+);
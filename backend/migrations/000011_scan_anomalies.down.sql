@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_inventory_anomalies;
+DROP INDEX IF EXISTS idx_inventory_cell_scanned;
+
+ALTER TABLE IF EXISTS inventory_history DROP COLUMN IF EXISTS anomaly_resolved_by;
+ALTER TABLE IF EXISTS inventory_history DROP COLUMN IF EXISTS anomaly_resolved_at;
+ALTER TABLE IF EXISTS inventory_history DROP COLUMN IF EXISTS anomaly_reason;
+ALTER TABLE IF EXISTS inventory_history DROP COLUMN IF EXISTS anomaly_score;
+ALTER TABLE IF EXISTS inventory_history DROP COLUMN IF EXISTS anomaly_status;
@@ -0,0 +1,9 @@
+-- выбросы в сканированиях роботов: до подтверждения не попадают в остатки и прогнозы
+ALTER TABLE inventory_history ADD COLUMN anomaly_status VARCHAR(20) NOT NULL DEFAULT ''; -- '', pending, confirmed, rejected
+ALTER TABLE inventory_history ADD COLUMN anomaly_score DOUBLE PRECISION;
+ALTER TABLE inventory_history ADD COLUMN anomaly_reason VARCHAR(255);
+ALTER TABLE inventory_history ADD COLUMN anomaly_resolved_at TIMESTAMP;
+ALTER TABLE inventory_history ADD COLUMN anomaly_resolved_by INTEGER REFERENCES users(id);
+
+CREATE INDEX idx_inventory_cell_scanned ON inventory_history(product_id, zone, row_number, shelf_number, scanned_at);
+CREATE INDEX idx_inventory_anomalies ON inventory_history(scanned_at) WHERE anomaly_status <> '';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_alerts_status_created;
+DROP INDEX IF EXISTS idx_alerts_active;
+DROP TABLE IF EXISTS alerts;
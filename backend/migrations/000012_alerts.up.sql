@@ -0,0 +1,27 @@
+-- оповещения об остатках с жизненным циклом: open -> acknowledged -> resolved
+CREATE TABLE alerts (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('scanned', 'predicted')),
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('critical', 'warning')),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    product_id VARCHAR(50) NOT NULL REFERENCES products(id),
+    product_name VARCHAR(255),
+    zone VARCHAR(10) NOT NULL DEFAULT '', -- пусто у оповещения по прогнозу
+    row_number INTEGER NOT NULL DEFAULT 0,
+    shelf_number INTEGER NOT NULL DEFAULT 0,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    message TEXT,
+    scanned_at TIMESTAMP NOT NULL, -- сканирование или прогноз, последними обновившие оповещение
+    assignee_id INTEGER REFERENCES users(id),
+    acknowledged_at TIMESTAMP,
+    acknowledged_by INTEGER REFERENCES users(id),
+    resolved_at TIMESTAMP,
+    resolved_by INTEGER REFERENCES users(id), -- NULL у закрытых автоматически
+    resolution_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- не больше одного активного оповещения на ячейку или товар в прогнозе
+CREATE UNIQUE INDEX idx_alerts_active ON alerts(type, product_id, zone, row_number, shelf_number) WHERE status <> 'resolved';
+CREATE INDEX idx_alerts_status_created ON alerts(status, created_at);
@@ -0,0 +1,16 @@
+DELETE FROM alerts WHERE type NOT IN ('scanned', 'predicted');
+
+DROP INDEX IF EXISTS idx_alerts_rule_key;
+DROP INDEX IF EXISTS idx_alerts_active;
+CREATE UNIQUE INDEX idx_alerts_active ON alerts(type, product_id, zone, row_number, shelf_number) WHERE status <> 'resolved';
+
+ALTER TABLE alerts DROP COLUMN IF EXISTS dedup_key;
+ALTER TABLE alerts DROP COLUMN IF EXISTS rule_id;
+ALTER TABLE alerts DROP COLUMN IF EXISTS robot_id;
+ALTER TABLE alerts ALTER COLUMN product_id DROP DEFAULT;
+ALTER TABLE alerts ADD CONSTRAINT alerts_product_id_fkey FOREIGN KEY (product_id) REFERENCES products(id);
+ALTER TABLE alerts DROP CONSTRAINT IF EXISTS alerts_type_check;
+ALTER TABLE alerts ADD CONSTRAINT alerts_type_check CHECK (type IN ('scanned', 'predicted'));
+
+DROP TABLE IF EXISTS alert_rules;
+ALTER TABLE products DROP COLUMN IF EXISTS lead_time_days;
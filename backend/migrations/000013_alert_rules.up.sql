@@ -0,0 +1,55 @@
+-- срок поставки товара для правил по прогнозу
+ALTER TABLE products ADD COLUMN lead_time_days INTEGER NOT NULL DEFAULT 7 CHECK (lead_time_days >= 0);
+
+-- настраиваемые условия оповещений, проверяются при приеме данных и по таймеру
+CREATE TABLE alert_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(50) NOT NULL CHECK (kind IN ('cell_below_min', 'cell_below_optimal', 'product_below_optimal',
+        'robot_battery_below', 'zone_not_scanned', 'forecast_stockout', 'forecast_before_lead_time')),
+    threshold DOUBLE PRECISION NOT NULL DEFAULT 0, -- проценты, часы или дни в зависимости от kind
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('critical', 'warning')),
+    category VARCHAR(100) NOT NULL DEFAULT '', -- пусто - любая
+    product_id VARCHAR(50) NOT NULL DEFAULT '',
+    zone VARCHAR(10) NOT NULL DEFAULT '',
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0 CHECK (cooldown_seconds >= 0), -- пауза перед повторным открытием после закрытия
+    dedup_key VARCHAR(255) NOT NULL DEFAULT '', -- шаблон ключа, пусто - по kind
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- правила, повторяющие прежние встроенные пороги
+INSERT INTO alert_rules (name, kind, threshold, severity) VALUES
+    ('Критический остаток в ячейке', 'cell_below_min', 0, 'critical'),
+    ('Низкий остаток в ячейке', 'cell_below_optimal', 50, 'warning'),
+    ('Прогноз: нехватка через 2 дня', 'forecast_stockout', 2, 'critical'),
+    ('Прогноз: нехватка в течение недели', 'forecast_stockout', 7, 'warning');
+
+-- оповещения по роботам и зонам не относятся к товару
+ALTER TABLE alerts DROP CONSTRAINT alerts_type_check;
+ALTER TABLE alerts ADD CONSTRAINT alerts_type_check CHECK (type IN ('scanned', 'stock', 'robot', 'zone', 'predicted'));
+ALTER TABLE alerts DROP CONSTRAINT alerts_product_id_fkey;
+ALTER TABLE alerts ALTER COLUMN product_id SET DEFAULT '';
+ALTER TABLE alerts ADD COLUMN robot_id VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE alerts ADD COLUMN rule_id INTEGER REFERENCES alert_rules(id) ON DELETE SET NULL;
+ALTER TABLE alerts ADD COLUMN dedup_key VARCHAR(255);
+
+UPDATE alerts SET
+    dedup_key = CASE type
+        WHEN 'scanned' THEN 'cell:' || product_id || ':' || zone || ':' || row_number || ':' || shelf_number
+        ELSE 'forecast:' || product_id
+    END,
+    rule_id = (SELECT r.id FROM alert_rules r
+        WHERE r.severity = alerts.severity
+          AND r.kind = CASE
+              WHEN alerts.type = 'predicted' THEN 'forecast_stockout'
+              WHEN alerts.severity = 'critical' THEN 'cell_below_min'
+              ELSE 'cell_below_optimal'
+          END);
+ALTER TABLE alerts ALTER COLUMN dedup_key SET NOT NULL;
+
+-- не больше одного активного оповещения на ключ
+DROP INDEX IF EXISTS idx_alerts_active;
+CREATE UNIQUE INDEX idx_alerts_active ON alerts(dedup_key) WHERE status <> 'resolved';
+CREATE INDEX idx_alerts_rule_key ON alerts(rule_id, dedup_key, resolved_at);
@@ -0,0 +1,33 @@
+-- каналы внешних уведомлений об оповещениях и маршрутизация по важности и зоне
+CREATE TABLE notification_channels (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('webhook', 'email', 'telegram')),
+    target TEXT NOT NULL, -- url вебхука, адреса через запятую или chat_id бота
+    secret VARCHAR(255) NOT NULL DEFAULT '', -- ключ подписи вебхука
+    severities VARCHAR(50) NOT NULL DEFAULT '', -- через запятую, пусто - любая
+    zones VARCHAR(255) NOT NULL DEFAULT '', -- через запятую, пусто - любая
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- журнал доставки: очередь отправки с повторами и результат каждой доставки
+CREATE TABLE notification_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    alert_id INTEGER NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    channel_id INTEGER REFERENCES notification_channels(id) ON DELETE SET NULL,
+    channel_kind VARCHAR(20) NOT NULL,
+    severity VARCHAR(20) NOT NULL, -- важность оповещения на момент отправки
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_deliveries_due ON notification_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_deliveries_alert ON notification_deliveries (alert_id);
+CREATE INDEX idx_deliveries_channel ON notification_deliveries (channel_id, created_at DESC);
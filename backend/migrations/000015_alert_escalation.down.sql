@@ -0,0 +1,7 @@
+ALTER TABLE notification_deliveries DROP COLUMN IF EXISTS tier;
+ALTER TABLE notification_channels DROP COLUMN IF EXISTS tier;
+
+DROP INDEX IF EXISTS idx_alerts_escalation;
+ALTER TABLE alerts DROP COLUMN IF EXISTS tier_since;
+ALTER TABLE alerts DROP COLUMN IF EXISTS tier;
+ALTER TABLE alerts DROP COLUMN IF EXISTS occurrences;
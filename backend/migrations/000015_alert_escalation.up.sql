@@ -0,0 +1,15 @@
+-- повторы условия считаются в активном оповещении, а не рассылаются заново
+ALTER TABLE alerts ADD COLUMN occurrences INTEGER NOT NULL DEFAULT 1;
+
+-- уровень уведомлений: неподтвержденное критическое оповещение поднимается на следующий уровень
+ALTER TABLE alerts ADD COLUMN tier INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE alerts ADD COLUMN tier_since TIMESTAMPTZ;
+UPDATE alerts SET tier_since = created_at;
+ALTER TABLE alerts ALTER COLUMN tier_since SET DEFAULT NOW();
+ALTER TABLE alerts ALTER COLUMN tier_since SET NOT NULL;
+
+CREATE INDEX idx_alerts_escalation ON alerts (tier_since) WHERE status = 'open' AND severity = 'critical';
+
+-- каналы уровня 1 получают новые оповещения, старших уровней - эскалации
+ALTER TABLE notification_channels ADD COLUMN tier INTEGER NOT NULL DEFAULT 1 CHECK (tier >= 1);
+ALTER TABLE notification_deliveries ADD COLUMN tier INTEGER NOT NULL DEFAULT 1;
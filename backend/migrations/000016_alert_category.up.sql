@@ -0,0 +1,6 @@
+-- категория товара в оповещении для фильтров подписок дашбордов
+ALTER TABLE alerts ADD COLUMN category VARCHAR(100) NOT NULL DEFAULT '';
+
+UPDATE alerts SET category = p.category
+FROM products p
+WHERE p.id = alerts.product_id AND p.category IS NOT NULL;
@@ -0,0 +1,5 @@
+-- зашифрованные секреты не переводятся обратно в хэш, ключи отзываются
+UPDATE robot_credentials SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP), secret_encrypted = '';
+
+ALTER TABLE robot_credentials ALTER COLUMN secret_encrypted TYPE VARCHAR(64);
+ALTER TABLE robot_credentials RENAME COLUMN secret_encrypted TO secret_hash;
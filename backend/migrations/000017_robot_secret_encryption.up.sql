@@ -0,0 +1,6 @@
+-- секреты роботов хранятся зашифрованными ключом сервера (ROBOT_SECRET_KEY),
+-- из прежнего хэша секрет не восстановить, поэтому старые ключи отзываются
+UPDATE robot_credentials SET revoked_at = CURRENT_TIMESTAMP WHERE revoked_at IS NULL;
+
+ALTER TABLE robot_credentials RENAME COLUMN secret_hash TO secret_encrypted;
+ALTER TABLE robot_credentials ALTER COLUMN secret_encrypted TYPE TEXT;
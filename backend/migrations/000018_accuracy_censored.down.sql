@@ -0,0 +1,3 @@
+-- прежняя оценка: граница окна ожидания, прогноз + 7 дней
+UPDATE ai_predictions SET actual_days = days_until_stockout + 7
+WHERE evaluated_at IS NOT NULL AND actual_stockout_at IS NULL;
@@ -0,0 +1,3 @@
+-- прогнозы без наблюдавшейся нехватки цензурированы: факт неизвестен, а не равен границе окна ожидания
+UPDATE ai_predictions SET actual_days = NULL
+WHERE evaluated_at IS NOT NULL AND actual_stockout_at IS NULL;
@@ -1,7 +1,8 @@
 import React, { useState, useEffect } from 'react';
 import { Box, Paper, Typography, IconButton, Tooltip } from '@mui/material';
 import { ZoomIn, ZoomOut, CenterFocusStrong } from '@mui/icons-material';
-import { Robot } from '../types';
+import { Robot, WarehouseZone } from '../types';
+import { apiService } from '../services/api';
 
 interface WarehouseMapProps {
   robots: Robot[];
@@ -12,12 +13,27 @@ const WarehouseMap: React.FC<WarehouseMapProps> = ({ robots }) => {
   const [pan, setPan] = useState({ x: 0, y: 0 });
   const [, forceUpdate] = useState(0);
 
-  const zones = ['A', 'B', 'C', 'D', 'E'];
-  const rows = 20;
-  const shelves = 10;
+  const [layout, setLayout] = useState<WarehouseZone[]>([]);
+
+  // Load warehouse layout from the backend
+  useEffect(() => {
+    apiService
+      .getLocations()
+      .then(setLayout)
+      .catch((err) => console.error('Failed to load warehouse layout:', err));
+  }, []);
+
+  // Zones are placed left to right, each zone is as wide as its shelves per row
+  const offsets: Record<string, number> = {};
+  let shelvesTotal = 0;
+  layout.forEach((zone) => {
+    offsets[zone.zone] = shelvesTotal;
+    shelvesTotal += zone.shelves_per_row;
+  });
+  const rows = layout.reduce((max, zone) => Math.max(max, zone.rows), 0);
 
   const cellSize = 30;
-  const width = zones.length * shelves * cellSize;
+  const width = shelvesTotal * cellSize;
   const height = rows * cellSize;
 
   const handleZoomIn = () => setZoom((prev) => Math.min(prev + 0.2, 2));
@@ -39,10 +55,10 @@ const WarehouseMap: React.FC<WarehouseMapProps> = ({ robots }) => {
   };
 
   const getRobotPosition = (robot: Robot) => {
-    const zoneIndex = zones.indexOf(robot.current_zone);
-    if (zoneIndex === -1) return { x: 0, y: 0 };
+    const offset = offsets[robot.current_zone];
+    if (offset === undefined) return { x: 0, y: 0 };
 
-    const x = (zoneIndex * shelves + robot.current_shelf - 1) * cellSize + cellSize / 2;
+    const x = (offset + robot.current_shelf - 1) * cellSize + cellSize / 2;
     const y = (robot.current_row - 1) * cellSize + cellSize / 2;
 
     return { x, y };
@@ -85,12 +101,12 @@ const WarehouseMap: React.FC<WarehouseMapProps> = ({ robots }) => {
           }}
         >
           {/* Grid */}
-          {zones.map((zone, zoneIdx) => (
+          {layout.map(({ zone, rows: zoneRows, shelves_per_row: shelves }) => (
             <g key={zone}>
-              {Array.from({ length: rows }).map((_, rowIdx) => (
+              {Array.from({ length: zoneRows }).map((_, rowIdx) => (
                 <g key={`${zone}-${rowIdx}`}>
                   {Array.from({ length: shelves }).map((_, shelfIdx) => {
-                    const x = (zoneIdx * shelves + shelfIdx) * cellSize;
+                    const x = (offsets[zone] + shelfIdx) * cellSize;
                     const y = rowIdx * cellSize;
 
                     return (
@@ -111,7 +127,7 @@ const WarehouseMap: React.FC<WarehouseMapProps> = ({ robots }) => {
 
               {/* Zone labels */}
               <text
-                x={(zoneIdx * shelves + shelves / 2) * cellSize}
+                x={(offsets[zone] + shelves / 2) * cellSize}
                 y={-10}
                 textAnchor="middle"
                 fontSize="14"
@@ -10,6 +10,7 @@ import { useAppDispatch, useAppSelector } from '../store/hooks';
 import {
   fetchDashboardData,
   fetchAIPredictions,
+  fetchLatestPredictions,
   updateRobot,
   updateRobots,
   addRecentScan,
@@ -30,6 +31,7 @@ const DashboardPage: React.FC = () => {
   // Fetch initial data
   useEffect(() => {
     dispatch(fetchDashboardData());
+    dispatch(fetchLatestPredictions());
 
     // Refresh data every 30 seconds
     const interval = setInterval(() => {
@@ -7,7 +7,8 @@ import type {
   DashboardStats,
   AIPrediction,
   HistoryFilters,
-  CSVUploadResult
+  CSVUploadResult,
+  WarehouseZone
 } from '../types';
 
 class APIService {
@@ -64,6 +65,12 @@ class APIService {
     return response.data;
   }
 
+  // Warehouse layout endpoints
+  async getLocations(): Promise<WarehouseZone[]> {
+    const response = await this.api.get<{ locations: WarehouseZone[] }>('/locations');
+    return response.data.locations;
+  }
+
   // History endpoints
   async getInventoryHistory(
     filters: Partial<HistoryFilters>,
@@ -127,6 +134,18 @@ class APIService {
     return response.data;
   }
 
+  // последний сохраненный прогноз по каждому товару, без нового запроса к модели
+  async getLatestPredictions(): Promise<AIPrediction[]> {
+    const response = await this.api.get<{
+      predictions: Array<Omit<AIPrediction, 'product_id'> & { product: { id: string } }>;
+    }>('/ai/predictions/latest');
+    return response.data.predictions.map(({ product, ...prediction }) => ({
+      ...prediction,
+      product_id: product.id,
+      prediction_date: prediction.prediction_date.slice(0, 10)
+    }));
+  }
+
   // Export endpoints
   async exportToExcel(productIds: string[]): Promise<Blob> {
     const response = await this.api.get('/export/excel', {
@@ -46,6 +46,17 @@ export const fetchAIPredictions = createAsyncThunk(
   }
 );
 
+export const fetchLatestPredictions = createAsyncThunk(
+  'dashboard/fetchLatestPredictions',
+  async (_, { rejectWithValue }) => {
+    try {
+      return await apiService.getLatestPredictions();
+    } catch (error: any) {
+      return rejectWithValue(error.response?.data?.message || 'Ошибка получения прогнозов');
+    }
+  }
+);
+
 const dashboardSlice = createSlice({
   name: 'dashboard',
   initialState,
@@ -117,7 +128,14 @@ const dashboardSlice = createSlice({
           state.aiPredictions = action.payload.predictions;
           state.aiConfidence = action.payload.confidence;
         }
-      );
+      )
+      // Latest stored predictions
+      .addCase(fetchLatestPredictions.fulfilled, (state, action: PayloadAction<AIPrediction[]>) => {
+        state.aiPredictions = action.payload;
+        state.aiConfidence = action.payload.length
+          ? action.payload.reduce((sum, p) => sum + p.confidence_score, 0) / action.payload.length
+          : 0;
+      });
   }
 });
 
@@ -99,3 +99,12 @@ export interface Location {
   row: number;
   shelf: number;
 }
+
+// Warehouse layout zone (/api/locations)
+export interface WarehouseZone {
+  zone: string;
+  name: string;
+  rows: number;
+  shelves_per_row: number;
+  shelf_capacity: number;
+}
@@ -7,6 +7,9 @@
 import json
 import time
 import random
+import hashlib
+import hmac
+import uuid
 import requests
 from datetime import datetime, timedelta
 import os
@@ -19,19 +22,48 @@ logging.basicConfig(
 )
 logger = logging.getLogger(__name__)
 
+# Раскладка склада, как в таблице locations (миграция 000008_locations)
+ZONES = ['A', 'B', 'C', 'D', 'E']
+ROWS = 20
+SHELVES = 10
+
+
+def load_robot_keys():
+    """Ключи роботов из ROBOT_KEYS в формате RB-001=rk_id:secret,RB-002=..."""
+    keys = {}
+    for item in os.getenv('ROBOT_KEYS', '').split(','):
+        if '=' not in item or ':' not in item:
+            continue
+        robot_id, key = item.strip().split('=', 1)
+        key_id, secret = key.split(':', 1)
+        keys[robot_id] = (key_id, secret)
+    return keys
+
+
+def sign_request(key_id, secret, method, path, body):
+    """Подпись запроса: HMAC-SHA256 с секретом робота в качестве ключа"""
+    timestamp = str(int(time.time()))
+    body_hash = hashlib.sha256(body).hexdigest()
+    message = f"{timestamp}\n{method}\n{path}\n{body_hash}".encode()
+    return {
+        "X-Robot-Key": key_id,
+        "X-Robot-Timestamp": timestamp,
+        "X-Robot-Signature": hmac.new(secret.encode(), message, hashlib.sha256).hexdigest(),
+    }
+
 
 class RobotEmulator:
-    def __init__(self, robot_id, api_url):
+    def __init__(self, robot_id, api_url, key=None):
         self.robot_id = robot_id
         self.api_url = api_url
+        self.key = key
         self.battery = random.randint(85, 100)
 
         # Уникальная стартовая позиция для каждого робота
         robot_num = int(robot_id.split('-')[1])
-        zones = ['A', 'B', 'C', 'D', 'E']
-        self.current_zone = zones[(robot_num - 1) % len(zones)]
-        self.current_row = robot_num * 3
-        self.current_shelf = robot_num * 2
+        self.current_zone = ZONES[(robot_num - 1) % len(ZONES)]
+        self.current_row = (robot_num * 3 - 1) % ROWS + 1
+        self.current_shelf = (robot_num * 2 - 1) % SHELVES + 1
 
         # Список тестовых товаров с категориями
         self.products = [
@@ -105,21 +137,24 @@ class RobotEmulator:
 
         return scan_results
 
-    def move_to_next_location(self):
-        """Перемещение робота к следующей локации"""
-        self.current_shelf += 1
+    def next_location(self):
+        """Следующая ячейка обхода, не выходит за раскладку склада"""
+        zone, row, shelf = self.current_zone, self.current_row, self.current_shelf + 1
 
-        if self.current_shelf > 10:
-            self.current_shelf = 1
-            self.current_row += 1
+        if shelf > SHELVES:
+            shelf = 1
+            row += 1
 
-            if self.current_row > 20:
-                self.current_row = 1
+            if row > ROWS:
+                row = 1
                 # Переход к следующей зоне
-                self.current_zone = chr(ord(self.current_zone) + 1)
+                zone = ZONES[(ZONES.index(zone) + 1) % len(ZONES)]
+
+        return zone, row, shelf
 
-                if ord(self.current_zone) > ord('E'):
-                    self.current_zone = 'A'
+    def move_to_next_location(self):
+        """Перемещение робота к следующей локации"""
+        self.current_zone, self.current_row, self.current_shelf = self.next_location()
 
         # Расход батареи
         self.battery -= random.uniform(0.5, 1.5)
@@ -131,6 +166,7 @@ class RobotEmulator:
     def send_data(self):
         """Отправка данных на сервер"""
         data = {
+            "message_id": str(uuid.uuid4()),  # сервер не запишет повтор с тем же id
             "robot_id": self.robot_id,
             "timestamp": datetime.utcnow().isoformat() + "Z",
             "location": {
@@ -140,17 +176,23 @@ class RobotEmulator:
             },
             "scan_results": self.generate_scan_data(),
             "battery_level": int(self.battery),
-            "next_checkpoint": f"{self.current_zone}-{self.current_row + 1}-{self.current_shelf}"
+            "next_checkpoint": "{}-{}-{}".format(*self.next_location())
         }
 
+        path = "/api/robots/data"
+        body = json.dumps(data).encode()
+        headers = {"Content-Type": "application/json"}
+        if self.key:
+            headers.update(sign_request(self.key[0], self.key[1], "POST", path, body))
+        else:
+            # старая схема, сервер принимает ее только с ROBOT_LEGACY_AUTH=true
+            headers["Authorization"] = f"Bearer token_{self.robot_id}"
+
         try:
             response = requests.post(
-                f"{self.api_url}/api/robots/data",
-                json=data,
-                headers={
-                    "Authorization": f"Bearer token_{self.robot_id}",
-                    "Content-Type": "application/json"
-                },
+                f"{self.api_url}{path}",
+                data=body,
+                headers=headers,
                 timeout=10
             )
 
@@ -189,9 +231,12 @@ def main():
     # Запуск эмуляторов роботов
     import threading
 
+    robot_keys = load_robot_keys()
+
     robots = []
     for i in range(1, robots_count + 1):
-        robot = RobotEmulator(f"RB-{i:03d}", api_url)
+        robot_id = f"RB-{i:03d}"
+        robot = RobotEmulator(robot_id, api_url, robot_keys.get(robot_id))
         thread = threading.Thread(target=robot.run, daemon=True)
         thread.start()
         robots.append(robot)